@@ -13,15 +13,39 @@ const (
 	// Value: RoleSpec.name from RoleBasedGroup.spec.roles[]
 	SetRoleLabelKey = RBGDomainPrefix + "role"
 
+	// GroupSetLabelKey identifies the RoleBasedGroups created by a RoleBasedGroupSet
+	// Value: RoleBasedGroupSet.metadata.name
+	GroupSetLabelKey = "workload-rbgs-name"
+
+	// GroupSetNameLabelKey identifies the pods of the RoleBasedGroups created by a RoleBasedGroupSet
+	// Value: RoleBasedGroupSet.metadata.name
+	GroupSetNameLabelKey = RBGDomainPrefix + "group-set-name"
+
 	// PodGroupLabelKey identifies pods belonging to a specific pod group
 	// Value: RoleBasedName
 	PodGroupLabelKey = "pod-group.scheduling.sigs.k8s.io/name"
 
-	// RevisionAnnotationKey tracks the controller revision hash for template changes
-	// Value: SHA256 hash of RoleSpec template
+	// RevisionAnnotationKey tracks the role revision a workload has been rolled out to
+	// Value: hash of the role ControllerRevision
 	RevisionAnnotationKey = RBGDomainPrefix + "revision"
 
 	RoleSizeAnnotationKey string = RBGDomainPrefix + "role-size"
+
+	// PodIndexLabelKey identifies the index of a pod of a role running as bare pods
+	// Value: index of the pod, from 0 to the role replicas
+	PodIndexLabelKey = RBGDomainPrefix + "pod-index"
+
+	// PodTemplateHashAnnotationKey tracks the pod template a pod of a role running as bare pods was created from
+	// Value: hash of the pod template
+	PodTemplateHashAnnotationKey = RBGDomainPrefix + "pod-template-hash"
+
+	// OrderedDeletionFinalizer holds the deletion of a RoleBasedGroup until its roles have been
+	// deleted in reverse dependency order
+	OrderedDeletionFinalizer = RBGDomainPrefix + "ordered-deletion"
+
+	// QueueNameLabelKey names the Kueue LocalQueue admitting a RoleBasedGroup as a whole
+	// Value: name of the LocalQueue in the namespace of the RoleBasedGroup
+	QueueNameLabelKey = "kueue.x-k8s.io/queue-name"
 )
 
 type RolloutStrategyType string
@@ -33,6 +57,29 @@ const (
 	RollingUpdateStrategyType RolloutStrategyType = "RollingUpdate"
 )
 
+type PodUpdatePolicyType string
+
+const (
+	// RecreatePodUpdatePolicyType recreates the pods to update them.
+	RecreatePodUpdatePolicyType PodUpdatePolicyType = "ReCreate"
+
+	// InPlaceIfPossiblePodUpdatePolicyType updates the pods in place when possible, and recreates them otherwise.
+	InPlaceIfPossiblePodUpdatePolicyType PodUpdatePolicyType = "InPlaceIfPossible"
+
+	// InPlaceOnlyPodUpdatePolicyType only updates the pods in place.
+	InPlaceOnlyPodUpdatePolicyType PodUpdatePolicyType = "InPlaceOnly"
+)
+
+type GroupRolloutStrategyType string
+
+const (
+	// DependencyOrderRolloutStrategyType updates the roles one after another in dependency order.
+	DependencyOrderRolloutStrategyType GroupRolloutStrategyType = "DependencyOrder"
+
+	// RatioRolloutStrategyType updates a fixed number of replicas of every role per step.
+	RatioRolloutStrategyType GroupRolloutStrategyType = "Ratio"
+)
+
 type RestartPolicyType string
 
 const (
@@ -50,12 +97,21 @@ const (
 	// failed, we will recreate only one lws instance, not all lws instances.
 	// It equals to RecreateGroupOnPodRestart in lws.spec.LeaderWorkerTemplate.RestartPolicyType
 	RecreateRoleInstanceOnPodRestart RestartPolicyType = "RecreateRoleInstanceOnPodRestart"
+
+	// RecreateDependentsOnPodRestart will recreate all the pods of the role, and of the roles depending on it
+	// directly or transitively, if any pod of the role is recreated or has a container restarted.
+	// The other roles are not impacted.
+	RecreateDependentsOnPodRestart RestartPolicyType = "RecreateDependentsOnPodRestart"
 )
 
 const (
-	DeploymentWorkloadType      string = "apps/v1/Deployment"
-	StatefulSetWorkloadType     string = "apps/v1/StatefulSet"
-	LeaderWorkerSetWorkloadType string = "leaderworkerset.x-k8s.io/v1/LeaderWorkerSet"
+	DeploymentWorkloadType        string = "apps/v1/Deployment"
+	StatefulSetWorkloadType       string = "apps/v1/StatefulSet"
+	LeaderWorkerSetWorkloadType   string = "leaderworkerset.x-k8s.io/v1/LeaderWorkerSet"
+	KruiseStatefulSetWorkloadType string = "apps.kruise.io/v1beta1/StatefulSet"
+	CloneSetWorkloadType          string = "apps.kruise.io/v1alpha1/CloneSet"
+	JobWorkloadType               string = "batch/v1/Job"
+	PodWorkloadType               string = "v1/Pod"
 )
 
 type AdapterPhase string
@@ -3,6 +3,8 @@ package v1alpha1
 import (
 	"errors"
 	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func (rbg *RoleBasedGroup) GetCommonLabelsFromRole(role *RoleSpec) map[string]string {
@@ -14,6 +16,11 @@ func (rbg *RoleBasedGroup) GetCommonLabelsFromRole(role *RoleSpec) map[string]st
 	}
 }
 
+// GetGroupSetName returns the name of the RoleBasedGroupSet which created rbg, or an empty name.
+func (rbg *RoleBasedGroup) GetGroupSetName() string {
+	return rbg.Labels[GroupSetLabelKey]
+}
+
 func (rbg *RoleBasedGroup) GetCommonAnnotationsFromRole(role *RoleSpec) map[string]string {
 	return map[string]string{
 		RoleSizeAnnotationKey: fmt.Sprintf("%d", *role.Replicas),
@@ -32,6 +39,48 @@ func (rbg *RoleBasedGroup) GetGroupSize() int {
 	return ret
 }
 
+// GetGroupMinMember returns the number of pods which have to be scheduled together for gang-scheduling.
+func (rbg *RoleBasedGroup) GetGroupMinMember() int {
+	ret := 0
+	for i := range rbg.Spec.Roles {
+		ret += rbg.Spec.Roles[i].GetMinAvailablePods()
+	}
+	return ret
+}
+
+// GetPodsPerInstance returns the number of pods of one role instance.
+func (role *RoleSpec) GetPodsPerInstance() int {
+	if role.Workload.String() == LeaderWorkerSetWorkloadType && role.LeaderWorkerSet.Size != nil {
+		return int(*role.LeaderWorkerSet.Size)
+	}
+	return 1
+}
+
+// GetMinAvailable returns the minimum number of role instances required by gang-scheduling,
+// capped at replicas.
+func (role *RoleSpec) GetMinAvailable() int {
+	replicas := 0
+	if role.Replicas != nil {
+		replicas = int(*role.Replicas)
+	}
+	if role.MinAvailable == nil {
+		return replicas
+	}
+	minAvailable, err := intstr.GetScaledValueFromIntOrPercent(role.MinAvailable, replicas, true)
+	if err != nil || minAvailable > replicas {
+		return replicas
+	}
+	if minAvailable < 0 {
+		return 0
+	}
+	return minAvailable
+}
+
+// GetMinAvailablePods returns the minimum number of role pods required by gang-scheduling.
+func (role *RoleSpec) GetMinAvailablePods() int {
+	return role.GetMinAvailable() * role.GetPodsPerInstance()
+}
+
 func (rbg *RoleBasedGroup) GetWorkloadName(role *RoleSpec) string {
 	return fmt.Sprintf("%s-%s", rbg.Name, role.Name)
 }
@@ -64,11 +113,40 @@ func (rbg *RoleBasedGroup) GetRoleStatus(roleName string) (status RoleStatus, fo
 	return
 }
 
+// SpecRoleStatuses returns the status of the roles in spec, skipping the roles never reconciled.
+func (rbg *RoleBasedGroup) SpecRoleStatuses() []RoleStatus {
+	statuses := make([]RoleStatus, 0, len(rbg.Spec.Roles))
+	for _, role := range rbg.Spec.Roles {
+		if status, found := rbg.GetRoleStatus(role.Name); found {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
 func (rbg *RoleBasedGroup) EnableGangScheduling() bool {
-	if rbg.Spec.PodGroupPolicy != nil && rbg.Spec.PodGroupPolicy.PodGroupPolicySource.KubeScheduling != nil {
-		return true
+	if rbg.Spec.PodGroupPolicy == nil {
+		return false
 	}
-	return false
+	return rbg.Spec.PodGroupPolicy.KubeScheduling != nil || rbg.Spec.PodGroupPolicy.Volcano != nil
+}
+
+// GetQueueName returns the Kueue LocalQueue admitting rbg, or an empty string if rbg is not admitted by Kueue.
+func (rbg *RoleBasedGroup) GetQueueName() string {
+	return rbg.Labels[QueueNameLabelKey]
+}
+
+// GetPriorityClassName returns the priority class of the pods of role, the one of the role or else of rbg.
+func (rbg *RoleBasedGroup) GetPriorityClassName(role *RoleSpec) string {
+	if role.PriorityClassName != "" {
+		return role.PriorityClassName
+	}
+	return rbg.Spec.PriorityClassName
+}
+
+// EvictOnPodPreemption reports whether all the roles of rbg are evicted once any of its pods is preempted.
+func (rbg *RoleBasedGroup) EvictOnPodPreemption() bool {
+	return rbg.Spec.PreemptionPolicy == EvictRBGOnPodPreemption
 }
 
 func (rbgsa *RoleBasedGroupScalingAdapter) ContainsRBGOwner(rbg *RoleBasedGroup) bool {
@@ -79,3 +157,14 @@ func (rbgsa *RoleBasedGroupScalingAdapter) ContainsRBGOwner(rbg *RoleBasedGroup)
 	}
 	return false
 }
+
+// GetDependencyReadiness returns the readiness condition of the dependency of role, or nil if the
+// dependency is ready once all of its replicas are ready.
+func (role *RoleSpec) GetDependencyReadiness(dependency string) *DependencyReadiness {
+	for i := range role.DependencyReadiness {
+		if role.DependencyReadiness[i].Role == dependency {
+			return &role.DependencyReadiness[i]
+		}
+	}
+	return nil
+}
@@ -33,6 +33,167 @@ type RoleBasedGroupSpec struct {
 
 	// Configuration for the PodGroup to enable gang-scheduling via supported plugins.
 	PodGroupPolicy *PodGroupPolicy `json:"podGroupPolicy,omitempty"`
+
+	// RevisionHistoryLimit is the maximum number of ControllerRevisions kept per role
+	// in addition to the current and update revisions.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// RollbackTo asks the controller to restore the role templates recorded in a previous revision.
+	// The controller clears the field once the rollback is done.
+	// +optional
+	RollbackTo *RollbackConfig `json:"rollbackTo,omitempty"`
+
+	// RolloutStrategy coordinates the rolling update of the roles.
+	// If not set, each role rolls out independently following its own rolloutStrategy.
+	// +optional
+	RolloutStrategy *GroupRolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// RestartStrategy limits the restarts of the group triggered by the restart policies of the roles.
+	// If not set, restarts are backed off with the defaults and never limited.
+	// +optional
+	RestartStrategy *RestartStrategy `json:"restartStrategy,omitempty"`
+
+	// TopologyPolicy places the pods of every role relative to each other, or to the pods of the other groups,
+	// within a topology domain, like a NVLink domain, a rack or a zone.
+	// +optional
+	TopologyPolicy *TopologyPolicy `json:"topologyPolicy,omitempty"`
+
+	// PriorityClassName is the priority class of the pods of every role, unless the role sets its own.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PreemptionPolicy defines how the group reacts when the scheduler preempts some of its pods
+	// in favor of higher priority pods. Any preemption by the scheduler counts, whether the preemptor
+	// belongs to another group or not. Defaults to None.
+	// +kubebuilder:validation:Enum={None,EvictRBGOnPodPreemption}
+	// +optional
+	PreemptionPolicy PreemptionPolicyType `json:"preemptionPolicy,omitempty"`
+}
+
+// PreemptionPolicyType is how a group reacts to the preemption of its pods.
+type PreemptionPolicyType string
+
+const (
+	// NonePreemptionPolicy leaves the preempted pods to the workloads of their roles, which recreate them.
+	NonePreemptionPolicy PreemptionPolicyType = "None"
+
+	// EvictRBGOnPodPreemption evicts all the roles of the group together as soon as any of its pods is
+	// preempted, so that a preempted group never keeps holding resources as a broken half-group.
+	// The roles are recreated once all of their pods are gone.
+	EvictRBGOnPodPreemption PreemptionPolicyType = "EvictRBGOnPodPreemption"
+)
+
+// TopologyPolicy injects pod affinity or anti-affinity terms into the pod template of every role.
+type TopologyPolicy struct {
+	// TopologyKey is the node label defining the topology domains, like topology.kubernetes.io/zone.
+	// +kubebuilder:validation:MinLength=1
+	TopologyKey string `json:"topologyKey"`
+
+	// Mode is how the pods are placed across the topology domains.
+	Mode TopologyPolicyMode `json:"mode"`
+
+	// Preferred only prefers the placement instead of requiring it, the pods are scheduled anyway
+	// when the placement cannot be met.
+	// +optional
+	Preferred bool `json:"preferred,omitempty"`
+}
+
+// TopologyPolicyMode is how the pods of a group are placed across the topology domains.
+// +kubebuilder:validation:Enum={Colocate,Spread}
+type TopologyPolicyMode string
+
+const (
+	// ColocateTopologyPolicyMode places all the pods of all the roles of the group in the same topology domain.
+	ColocateTopologyPolicyMode TopologyPolicyMode = "Colocate"
+
+	// SpreadTopologyPolicyMode keeps the pods of a group created by a RoleBasedGroupSet out of the topology
+	// domains running pods of the other groups of the same RoleBasedGroupSet, spreading its replicas.
+	// It has no effect on a group not created by a RoleBasedGroupSet.
+	SpreadTopologyPolicyMode TopologyPolicyMode = "Spread"
+)
+
+// RestartStrategy protects the group from restarting forever when a pod is crash-looping.
+type RestartStrategy struct {
+	// MaxRestarts is the maximum number of restarts of the group within WindowSeconds.
+	// Once exceeded, the group is not restarted anymore and the RestartLimitExceeded condition is set
+	// until the spec is updated. 0 means no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRestarts int32 `json:"maxRestarts,omitempty"`
+
+	// WindowSeconds is the period the restarts are counted over.
+	// +kubebuilder:default=3600
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	WindowSeconds int32 `json:"windowSeconds,omitempty"`
+
+	// InitialBackoffSeconds is the delay between the first two restarts, it doubles with each restart
+	// within the window.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialBackoffSeconds int32 `json:"initialBackoffSeconds,omitempty"`
+
+	// MaxBackoffSeconds caps the delay between two restarts.
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxBackoffSeconds int32 `json:"maxBackoffSeconds,omitempty"`
+
+	// ReadyTimeoutSeconds is how long a role recreated by a restart of the group is waited for to be ready.
+	// Past it, the role is given up and the restart goes on with the next roles.
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ReadyTimeoutSeconds int32 `json:"readyTimeoutSeconds,omitempty"`
+}
+
+// GroupRolloutStrategy defines how the rbg controller moves the roles to their new revision together.
+type GroupRolloutStrategy struct {
+	// Type defines the coordinated rollout strategy.
+	// DependencyOrder updates the roles one after another following their dependencies,
+	// a role starts to update once the roles before it are updated and ready.
+	// Ratio updates all the roles in lock-step, a fixed number of replicas of each role per step,
+	// the next step starts once all the roles are ready.
+	//
+	// +kubebuilder:validation:Enum={DependencyOrder,Ratio}
+	// +kubebuilder:default=DependencyOrder
+	Type GroupRolloutStrategyType `json:"type"`
+
+	// Ratio is the number of replicas of each role updated per step when type is Ratio.
+	// Roles not listed update one replica per step.
+	// Deployment roles can not be partially updated and are fully released at the first step.
+	// +optional
+	Ratio []RoleRolloutRatio `json:"ratio,omitempty"`
+}
+
+// RoleRolloutRatio is the number of replicas of a role updated per rollout step.
+type RoleRolloutRatio struct {
+	// Name of the role
+	Name string `json:"name"`
+
+	// Replicas of the role updated per step
+	// +kubebuilder:validation:Minimum=1
+	Replicas int32 `json:"replicas"`
+}
+
+// RollbackConfig selects the role revisions to roll back to.
+// +kubebuilder:validation:XValidation:rule="self.revision == 0 || (has(self.roles) && size(self.roles) > 0)",message="roles must be set when revision is set"
+type RollbackConfig struct {
+	// Revision number to roll back to. The revisions are numbered per role, so the same number
+	// records different points in time for roles updated a different number of times, and roles
+	// must be set along with it.
+	// If 0, each role is rolled back to its previous revision.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Revision int64 `json:"revision,omitempty"`
+
+	// Roles to roll back. Defaults to all roles when revision is 0.
+	// +optional
+	Roles []string `json:"roles,omitempty"`
 }
 
 // PodGroupPolicy represents a PodGroup configuration for gang-scheduling.
@@ -45,13 +206,17 @@ type PodGroupPolicy struct {
 // Only one of its members may be specified.
 type PodGroupPolicySource struct {
 	// KubeScheduling plugin from the Kubernetes scheduler-plugins for gang-scheduling.
+	// The PodGroup requires the minAvailable instances of every role to be scheduled together.
 	KubeScheduling *KubeSchedulingPodGroupPolicySource `json:"kubeScheduling,omitempty"`
 
-	// TODO (gujingit): Add support for Volcano gang-scheduler.
+	// Volcano gang-scheduler.
+	// The PodGroup requires the minAvailable instances of every role to be scheduled together.
+	Volcano *VolcanoPodGroupPolicySource `json:"volcano,omitempty"`
 }
 
 // KubeSchedulingPodGroupPolicySource represents configuration for  Kubernetes scheduling plugin.
-// The number of min members in the PodGroupSpec is always equal to the number of rbg pods.
+// The number of min members in the PodGroupSpec is the sum of the pods of the minAvailable instances of every role,
+// and the min resources are the sum of their container requests.
 type KubeSchedulingPodGroupPolicySource struct {
 	// Time threshold to schedule PodGroup for gang-scheduling.
 	// If the scheduling timeout is equal to 0, the default value is used.
@@ -60,6 +225,20 @@ type KubeSchedulingPodGroupPolicySource struct {
 	ScheduleTimeoutSeconds *int32 `json:"scheduleTimeoutSeconds,omitempty"`
 }
 
+// VolcanoPodGroupPolicySource represents configuration for the Volcano gang-scheduler.
+// The number of min members in the PodGroupSpec is the sum of the pods of the minAvailable instances of every role,
+// and the min resources are the sum of their container requests.
+type VolcanoPodGroupPolicySource struct {
+	// Queue the PodGroup is submitted to.
+	// If empty, the volcano default queue is used.
+	// +optional
+	Queue string `json:"queue,omitempty"`
+
+	// PriorityClassName of the PodGroup, used by volcano to order and preempt PodGroups.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
 // RolloutStrategy defines the strategy that the rbg controller
 // will use to perform replica updates of role.
 type RolloutStrategy struct {
@@ -72,6 +251,11 @@ type RolloutStrategy struct {
 	// RollingUpdate defines the parameters to be used when type is RollingUpdateStrategyType.
 	// +optional
 	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
+
+	// Paused stops the rollout of the role, replicas not updated yet are kept at the old revision
+	// until the rollout is resumed. A paused Deployment role sets spec.paused of the Deployment.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
 }
 
 // RollingUpdate defines the parameters to be used for RollingUpdateStrategyType.
@@ -106,6 +290,33 @@ type RollingUpdate struct {
 	// +kubebuilder:validation:XIntOrString
 	// +kubebuilder:default=0
 	MaxSurge intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// Partition is the number of replicas, from ordinal 0, kept at the old revision during the update.
+	// Replicas from ordinal Partition to Replicas-1 are updated, which allows to canary a new revision
+	// on a few replicas. A Deployment can not be partially updated, the Deployment role is paused
+	// while Partition is greater than or equal to Replicas and fully updated otherwise.
+	// By default, a value of 0 is used.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Partition *int32 `json:"partition,omitempty"`
+
+	// PodUpdatePolicy is how the OpenKruise workloads update the pods of the role.
+	// InPlaceIfPossible updates the pods in place when only their images or metadata change, so that
+	// they keep their node and local data, and recreates them otherwise. InPlaceOnly never recreates
+	// the pods, ReCreate always does. It is ignored by the native workloads.
+	// By default, InPlaceIfPossible is used.
+	//
+	// +kubebuilder:validation:Enum={ReCreate,InPlaceIfPossible,InPlaceOnly}
+	// +optional
+	PodUpdatePolicy PodUpdatePolicyType `json:"podUpdatePolicy,omitempty"`
+
+	// InPlaceUpdateGracePeriodSeconds is the time a pod is kept not ready before it is updated in place,
+	// letting the traffic drain from it. It is only used by the OpenKruise workloads.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InPlaceUpdateGracePeriodSeconds int32 `json:"inPlaceUpdateGracePeriodSeconds,omitempty"`
 }
 
 // RoleSpec defines the specification for a role in the group
@@ -119,6 +330,15 @@ type RoleSpec struct {
 	// +kubebuilder:default=1
 	Replicas *int32 `json:"replicas"`
 
+	// MinAvailable is the minimum number of role instances that have to be scheduled together
+	// when gang-scheduling is enabled. Value can be an absolute number (ex: 5) or a percentage
+	// of replicas (ex: 10%), rounded up. It is capped at replicas, so that scale-downs never make
+	// the gang unsatisfiable. Instances above minAvailable are scheduled elastically.
+	// For LeaderWorkerSet each instance counts as leaderWorkerSet.size pods.
+	// Defaults to replicas.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
 	// RolloutStrategy defines the strategy that will be applied to update replicas
 	// when a revision is made to the leaderWorkerTemplate.
 	// +optional
@@ -126,14 +346,32 @@ type RoleSpec struct {
 
 	// RestartPolicy defines the restart policy when pod failures happen.
 	// The default value is RecreateRoleInstanceOnPodRestart for LWS and None for STS & Deploy. Therefore, no default value is set.
-	// +kubebuilder:validation:Enum={None,RecreateRBGOnPodRestart,RecreateRoleInstanceOnPodRestart}
+	// +kubebuilder:validation:Enum={None,RecreateRBGOnPodRestart,RecreateRoleInstanceOnPodRestart,RecreateDependentsOnPodRestart}
 	// +optional
 	RestartPolicy RestartPolicyType `json:"restartPolicy,omitempty"`
 
+	// PriorityClassName is the priority class of the pods of the role, it overrides the one of the group.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
 	// Dependencies of the role
 	// +optional
 	Dependencies []string `json:"dependencies,omitempty"`
 
+	// DeletionTimeoutSeconds is how long the pods of the role are waited for to terminate when the role
+	// is deleted, before its dependencies are deleted. Roles are deleted in reverse dependency order.
+	// It is counted from the deletionStartTime of the role status. Defaults to 300 seconds.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DeletionTimeoutSeconds *int32 `json:"deletionTimeoutSeconds,omitempty"`
+
+	// DependencyReadiness relaxes or extends when a dependency is considered ready for the role to start.
+	// By default, a dependency is ready once all of its replicas are ready.
+	// +listType=map
+	// +listMapKey=role
+	// +optional
+	DependencyReadiness []DependencyReadiness `json:"dependencyReadiness,omitempty"`
+
 	// Workload type specification
 	// +kubebuilder:default={apiVersion:"apps/v1", kind:"StatefulSet"}
 	// +optional
@@ -155,11 +393,107 @@ type RoleSpec struct {
 
 	// +optional
 	ScalingAdapter *ScalingAdapter `json:"scalingAdapter,omitempty"`
+
+	// DiscoveryConfig shapes the group topology file mounted into the pods of the role.
+	// The topology lists the live pods of the visible roles with their address, IP, node, readiness and revision,
+	// and is refreshed on pod events. By default, it is mounted as YAML at /etc/rbg/config.yaml.
+	// The topologies of all the roles are stored in a single ConfigMap of the group, once per distinct content.
+	// +optional
+	DiscoveryConfig *DiscoveryConfig `json:"discoveryConfig,omitempty"`
+}
+
+// DiscoveryConfig defines the format and the location of the group topology file of a role.
+type DiscoveryConfig struct {
+	// Format of the topology file. Defaults to yaml.
+	// +kubebuilder:validation:Enum={json,yaml,env}
+	// +optional
+	Format DiscoveryConfigFormat `json:"format,omitempty"`
+
+	// MountPath is the directory the topology file is mounted at, its content is replaced.
+	// Defaults to /etc/rbg.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+
+	// FileName is the name of the topology file. Defaults to config.yaml, config.json or config.env
+	// depending on the format. It is required with a template.
+	// +optional
+	FileName string `json:"fileName,omitempty"`
+
+	// Template is a Go template rendering an engine-specific config, like a Mooncake mooncake.json, instead of
+	// the topology in format. It is executed with .Group, .Roles and .RoleName, the role the file is mounted into,
+	// and can use the toJson, toYaml, join, upper, lower and replace functions.
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// VisibleRoles are the roles whose instances the topology of the role lists.
+	// Defaults to the dependencies of the role and the role itself.
+	// +listType=set
+	// +optional
+	VisibleRoles []string `json:"visibleRoles,omitempty"`
+}
+
+// DiscoveryConfigFormat is the format of the group topology file.
+type DiscoveryConfigFormat string
+
+const (
+	// JSONDiscoveryConfigFormat renders the topology as JSON.
+	JSONDiscoveryConfigFormat DiscoveryConfigFormat = "json"
+
+	// YAMLDiscoveryConfigFormat renders the topology as YAML.
+	YAMLDiscoveryConfigFormat DiscoveryConfigFormat = "yaml"
+
+	// EnvDiscoveryConfigFormat renders the topology as an env file of KEY=value lines, like
+	// GROUP_NAME, ROLE_<ROLE>_READY_INSTANCES and ROLE_<ROLE>_INSTANCE_<INDEX>_ADDRESS.
+	EnvDiscoveryConfigFormat DiscoveryConfigFormat = "env"
+)
+
+// DependencyReadiness is the condition a dependency has to meet for the role to start.
+// The pods of the dependency meeting the condition are counted against MinReady.
+type DependencyReadiness struct {
+	// Role is the name of the dependency, it must be listed in the dependencies of the role.
+	Role string `json:"role"`
+
+	// MinReady is the number or percentage of the replicas of the dependency which must be ready.
+	// Percentages are rounded up. By default, all the replicas must be ready.
+	// +optional
+	MinReady *intstr.IntOrString `json:"minReady,omitempty"`
+
+	// PodConditionType is the pod condition, like a readiness gate, which must be true on a pod
+	// of the dependency for it to be counted, instead of the Ready condition.
+	// +optional
+	PodConditionType corev1.PodConditionType `json:"podConditionType,omitempty"`
+
+	// Probe is a check run by the controller against each pod of the dependency, or its Service.
+	// A pod is only counted once its probe succeeds.
+	// +optional
+	Probe *DependencyProbe `json:"probe,omitempty"`
+}
+
+// DependencyProbe is a check the controller runs against each pod of a dependency, so that minReady counts
+// the pods passing it. For LeaderWorkerSet dependencies only the leader pods are probed and counted.
+// Exactly one of httpGet, tcpSocket and exec must be specified, exec probes must be enabled in the controller.
+// The host of httpGet and tcpSocket must be empty, the probes target the pod IP: each pod is checked on its own,
+// and the controller never connects to an address outside of the dependency.
+// HTTPS probes do not verify the certificate and do not follow redirects, like the probes of the kubelet.
+type DependencyProbe struct {
+	corev1.ProbeHandler `json:",inline"`
+
+	// Service is the name of a Service in the namespace of the group. When set, the httpGet or tcpSocket
+	// probe is run once against the cluster DNS name of the Service, after minReady pods of the dependency
+	// are ready, instead of against each pod. Named ports are looked up in the ports of the Service.
+	// +optional
+	Service string `json:"service,omitempty"`
+
+	// TimeoutSeconds is the time after which the probe fails. Defaults to 1 second.
+	// The probes of the pods run concurrently, within the timeout overall.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
 }
 
 type WorkloadSpec struct {
 	// +optional
-	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/v[0-9]+((alpha|beta)[0-9]+)?$`
+	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?v[0-9]+((alpha|beta)[0-9]+)?$`
 	// +kubebuilder:default="apps/v1"
 	APIVersion string `json:"apiVersion"`
 
@@ -226,6 +560,85 @@ type RoleBasedGroupStatus struct {
 
 	// Status of individual roles
 	RoleStatuses []RoleStatus `json:"roleStatuses"`
+
+	// Rollout reports the progress of the coordinated rollout of the roles.
+	// +optional
+	Rollout *GroupRolloutStatus `json:"rollout,omitempty"`
+
+	// Restarts records the restarts of the group triggered by the restart policies of the roles.
+	// +optional
+	Restarts *RestartStatus `json:"restarts,omitempty"`
+}
+
+// RestartStatus records the restarts of the group within the current window of spec.restartStrategy.
+type RestartStatus struct {
+	// Count is the number of restarts since WindowStartTime.
+	Count int32 `json:"count"`
+
+	// WindowStartTime is the time of the first restart of the current window.
+	// +optional
+	WindowStartTime *metav1.Time `json:"windowStartTime,omitempty"`
+
+	// LastRestartTime is the time of the last restart.
+	// +optional
+	LastRestartTime *metav1.Time `json:"lastRestartTime,omitempty"`
+
+	// LastRestartReason is why the group was last restarted.
+	// +optional
+	LastRestartReason string `json:"lastRestartReason,omitempty"`
+
+	// Roles tracks the roles of the restart in progress, in the order they are recreated.
+	// Empty once the restart completed, or was given up after a recreated role was not ready within 5 minutes.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Roles []RoleRestartStatus `json:"roles,omitempty"`
+}
+
+// RoleRestartPhase is the step a role is at in the restart of its group.
+type RoleRestartPhase string
+
+const (
+	// RoleRestartPending means the role waits for the roles recreated before it.
+	RoleRestartPending RoleRestartPhase = "Pending"
+
+	// RoleRestartDeleting means the workload of the role is being deleted.
+	RoleRestartDeleting RoleRestartPhase = "Deleting"
+
+	// RoleRestartWaitingForRecreate means the workload of the role is deleted and waits to be created again.
+	RoleRestartWaitingForRecreate RoleRestartPhase = "WaitingForRecreate"
+
+	// RoleRestartWaitingForReady means the workload of the role was created again and waits to be ready.
+	RoleRestartWaitingForReady RoleRestartPhase = "WaitingForReady"
+
+	// RoleRestartCompleted means the role is restarted.
+	RoleRestartCompleted RoleRestartPhase = "Completed"
+
+	// RoleRestartTimedOut means the workload of the role was created again but was not ready in time.
+	RoleRestartTimedOut RoleRestartPhase = "TimedOut"
+)
+
+// RoleRestartStatus is the progress of a role in the restart of its group.
+type RoleRestartStatus struct {
+	// Name of the role.
+	Name string `json:"name"`
+
+	// Phase is the step the role is at.
+	// +kubebuilder:validation:Enum={Pending,Deleting,WaitingForRecreate,WaitingForReady,Completed,TimedOut}
+	Phase RoleRestartPhase `json:"phase"`
+
+	// LastTransitionTime is the time the role entered Phase.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// GroupRolloutStatus shows the progress of a coordinated rollout.
+type GroupRolloutStatus struct {
+	// Step is the current step of the rollout, starting at 1.
+	Step int32 `json:"step"`
+
+	// TotalSteps is the number of steps needed to update all the roles.
+	TotalSteps int32 `json:"totalSteps"`
 }
 
 // RoleStatus shows the current state of a specific role
@@ -238,6 +651,44 @@ type RoleStatus struct {
 
 	// Total number of desired replicas
 	Replicas int32 `json:"replicas"`
+
+	// Number of replicas running the latest template of the workload
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// Number of ready replicas running the latest template of the workload
+	// +optional
+	UpdatedReadyReplicas int32 `json:"updatedReadyReplicas,omitempty"`
+
+	// CurrentRevision is the name of the role revision the workload was last fully rolled out to.
+	// +optional
+	CurrentRevision string `json:"currentRevision,omitempty"`
+
+	// UpdateRevision is the name of the role revision matching the current role spec.
+	// +optional
+	UpdateRevision string `json:"updateRevision,omitempty"`
+
+	// ObservedGeneration is the generation of the rbg the workload of the role was last reconciled at.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Dependencies of the role when it was last reconciled. Once the role is removed from the spec,
+	// they order its deletion after the roles removed along with it which depend on it.
+	// +optional
+	Dependencies []string `json:"dependencies,omitempty"`
+
+	// DeletionStartTime is when the workload of the role started to be deleted, along with the rbg or after
+	// the role was removed from the spec. The next role is deleted once the pods of the role are gone,
+	// or deletionTimeoutSeconds after it.
+	// +optional
+	DeletionStartTime *metav1.Time `json:"deletionStartTime,omitempty"`
+
+	// Conditions track the condition of the role. The Ready condition is true once the workload
+	// has observed the role spec of the current generation and all its replicas are ready.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -259,8 +710,8 @@ type RoleBasedGroupConditionType string
 
 // These are built-in conditions of a RBG.
 const (
-	// RoleBasedGroupAvailable means the rbg is available, ie, at least the
-	// minimum available groups are up and running.
+	// RoleBasedGroupReady means all the roles of the rbg are ready at the current generation.
+	// It is also reported per role in RoleStatus.Conditions.
 	RoleBasedGroupReady RoleBasedGroupConditionType = "Ready"
 
 	// RoleBasedGroupProgressing means rbg is progressing. Progress for a
@@ -278,8 +729,31 @@ const (
 	// RoleBasedGroupRestartInProgress means rbg is restarting. RestartInProgress
 	// is true when the rbg is in restart process after the pod is deleted or the container is restarted.
 	RoleBasedGroupRestartInProgress RoleBasedGroupConditionType = "RestartInProgress"
+
+	// RoleBasedGroupRestartLimitExceeded means the group restarted more than spec.restartStrategy allows,
+	// it is not restarted anymore until the spec is updated.
+	RoleBasedGroupRestartLimitExceeded RoleBasedGroupConditionType = "RestartLimitExceeded"
+
+	// RoleBasedGroupRolledBack reports the result of the last rollback requested by spec.rollbackTo.
+	RoleBasedGroupRolledBack RoleBasedGroupConditionType = "RolledBack"
+
+	// RoleBasedGroupCoordinatedRolloutInProgress is true while the roles are rolled out step by step
+	// following spec.rolloutStrategy. The message reports the current step.
+	RoleBasedGroupCoordinatedRolloutInProgress RoleBasedGroupConditionType = "CoordinatedRolloutInProgress"
+
+	// RoleBasedGroupSuspended is true while a rbg labeled with a Kueue queue name waits for Kueue to admit it,
+	// or was evicted by Kueue. The workloads of its roles are not created meanwhile.
+	RoleBasedGroupSuspended RoleBasedGroupConditionType = "Suspended"
+
+	// RoleBasedGroupPreempted is true once some pods of a rbg were preempted by the scheduler and its roles were
+	// evicted together, until all of its roles are ready again.
+	RoleBasedGroupPreempted RoleBasedGroupConditionType = "Preempted"
 )
 
+// RoleWorkloadFailedReason is the reason of the Ready condition of a role whose workload failed for good,
+// such as a Job out of retries. The group is then not ready with the RoleFailed reason.
+const RoleWorkloadFailedReason = "WorkloadFailed"
+
 // +kubebuilder:object:root=true
 
 // RoleBasedGroupList contains a list of RoleBasedGroup.
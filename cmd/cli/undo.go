@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	toRevision int64
+	undoRoles  []string
+)
+
+func newUndoCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undo NAME",
+		Short: "Roll back a RoleBasedGroup to a previous revision",
+		Example: `  # Roll back every role to its previous revision
+  kubectl rbg undo my-rbg
+
+  # Roll back the decode role to revision 3
+  kubectl rbg undo my-rbg --to-revision=3 --roles=decode`,
+		Args: cobra.ExactArgs(1),
+		RunE: runUndo,
+	}
+
+	cmd.Flags().Int64Var(&toRevision, "to-revision", 0, "The revision of the roles to roll back to, numbered per role. Default to 0 (previous revision)")
+	cmd.Flags().StringSliceVar(&undoRoles, "roles", nil, "Roles to roll back. Default to all roles")
+	return cmd
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	name = args[0]
+	if toRevision < 0 {
+		return fmt.Errorf("--to-revision must be greater than or equal to 0")
+	}
+	if toRevision > 0 && len(undoRoles) == 0 {
+		// the revisions are numbered per role
+		return fmt.Errorf("--roles must be set along with --to-revision")
+	}
+
+	config, err := getConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	rollbackTo := map[string]interface{}{
+		"revision": toRevision,
+	}
+	if len(undoRoles) > 0 {
+		rollbackTo["roles"] = undoRoles
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"rollbackTo": rollbackTo,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = dynamicClient.Resource(rbgGVR).Namespace(namespace).Patch(
+		context.TODO(),
+		name,
+		types.MergePatchType,
+		patch,
+		metav1.PatchOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to roll back RoleBasedGroup: %w", err)
+	}
+
+	// the controller restores the role templates asynchronously, see the RolledBack condition
+	fmt.Printf("rolebasedgroup/%s rollback requested\n", name)
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
@@ -32,7 +33,10 @@ import (
 	lwsv1 "sigs.k8s.io/lws/api/leaderworkerset/v1"
 	schev1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"time"
+	volcanov1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
 	rawzap "go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -50,6 +54,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 	workloadscontroller "sigs.k8s.io/rbgs/internal/controller/workloads"
+	webhookworkloadsv1alpha1 "sigs.k8s.io/rbgs/internal/webhook/workloads/v1alpha1"
 	"sigs.k8s.io/rbgs/version"
 	// +kubebuilder:scaffold:imports
 )
@@ -63,7 +68,10 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(apiextv1.AddToScheme(scheme))
 	utilruntime.Must(lwsv1.AddToScheme(scheme))
+	utilruntime.Must(kruiseappsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(kruiseappsv1beta1.AddToScheme(scheme))
 	utilruntime.Must(schev1alpha1.AddToScheme(scheme))
+	utilruntime.Must(volcanov1beta1.AddToScheme(scheme))
 
 	utilruntime.Must(workloadsv1alpha1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
@@ -88,9 +96,12 @@ func main() {
 		enableHTTP2                                      bool
 		tlsOpts                                          []func(*tls.Config)
 		development                                      bool
+		enableWebhooks                                   bool
+		enableExecDependencyProbes                       bool
 		// Controller runtime options
-		maxConcurrentReconciles int
-		cacheSyncTimeout        time.Duration
+		maxConcurrentReconciles     int
+		maxConcurrentRoleReconciles int
+		cacheSyncTimeout            time.Duration
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -110,8 +121,16 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.BoolVar(&development, "development", false, "Enable development mode for controller manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the defaulting and validating webhooks for RoleBasedGroup will be served. "+
+			"Requires the webhook certificates to be provided.")
+	flag.BoolVar(&enableExecDependencyProbes, "enable-exec-dependency-probes", false,
+		"If set, the exec probes of the dependency readiness of RoleBasedGroups are run in the pods of the dependency. "+
+			"Requires the controller to be allowed to create pods/exec.")
 	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 10,
 		"The number of worker threads used by the the RBGS controller.")
+	flag.IntVar(&maxConcurrentRoleReconciles, "max-concurrent-role-reconciles", 4,
+		"The number of roles of a RoleBasedGroup, whose dependencies are ready, reconciled concurrently.")
 	flag.DurationVar(&cacheSyncTimeout, "cache-sync-timeout", 120*time.Second, "Informer cache sync timeout.")
 
 	flag.Parse()
@@ -247,6 +266,10 @@ func main() {
 	}
 
 	rbgReconciler := workloadscontroller.NewRoleBasedGroupReconciler(mgr)
+	rbgReconciler.SetMaxConcurrentRoleReconciles(maxConcurrentRoleReconciles)
+	if enableExecDependencyProbes {
+		rbgReconciler.EnableExecDependencyProbes(mgr.GetConfig())
+	}
 	if err = rbgReconciler.CheckCrdExists(); err != nil {
 		setupLog.Error(err, "unable to create rbg controller", "controller", "RoleBasedGroup")
 		os.Exit(1)
@@ -263,6 +286,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	discoveryReconciler := workloadscontroller.NewDiscoveryReconciler(mgr)
+	if err = discoveryReconciler.SetupWithManager(mgr, options); err != nil {
+		setupLog.Error(err, "unable to create discovery controller", "controller", "Discovery")
+		os.Exit(1)
+	}
+
 	rbgScalingAdapterReconciler := workloadscontroller.NewRoleBasedGroupScalingAdapterReconciler(mgr)
 	if err = rbgScalingAdapterReconciler.CheckCrdExists(); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RoleBasedGroupScalingAdapter")
@@ -283,6 +312,13 @@ func main() {
 		setupLog.Error(err, "unable to create rbgs controller", "controller", "RoleBasedGroupSet")
 		os.Exit(1)
 	}
+
+	if enableWebhooks {
+		if err = webhookworkloadsv1alpha1.SetupRoleBasedGroupWebhookWithManager(mgr, enableExecDependencyProbes); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "RoleBasedGroup")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -336,6 +372,15 @@ func cacheOptions() cache.Options {
 			&corev1.Service{}: {
 				Label: keyExistsSelector,
 			},
+			&appsv1.ControllerRevision{}: {
+				Label: keyExistsSelector,
+			},
+			&appsv1.ReplicaSet{}: {
+				Label: keyExistsSelector,
+			},
+			&batchv1.Job{}: {
+				Label: keyExistsSelector,
+			},
 		},
 	}
 }
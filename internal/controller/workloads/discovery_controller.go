@@ -0,0 +1,100 @@
+package workloads
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/discovery"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// DiscoveryReconciler refreshes the discovery ConfigMaps of a RoleBasedGroup on the events of its pods,
+// so that the topology mounted into the pods lists the live pods and whether they are ready.
+// The per-role ConfigMaps of earlier releases are deleted once no pod mounts them anymore.
+type DiscoveryReconciler struct {
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+func NewDiscoveryReconciler(mgr ctrl.Manager) *DiscoveryReconciler {
+	return &DiscoveryReconciler{
+		client: mgr.GetClient(),
+		scheme: mgr.GetScheme(),
+	}
+}
+
+func (r *DiscoveryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	rbg := &workloadsv1alpha1.RoleBasedGroup{}
+	if err := r.client.Get(ctx, req.NamespacedName, rbg); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if rbg.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+	logger := log.FromContext(ctx).WithValues("rbg", klog.KObj(rbg))
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	injector := discovery.NewDefaultInjector(r.scheme, r.client)
+	if err := injector.RefreshConfig(ctx, rbg); err != nil {
+		logger.Error(err, "Failed to refresh discovery config")
+		return ctrl.Result{}, err
+	}
+	if err := injector.DeleteLegacyConfigs(ctx, rbg); err != nil {
+		logger.Error(err, "Failed to delete legacy discovery configs")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// discoveryChanged reports whether the pod changed in a way the discovery config shows.
+func discoveryChanged(oldPod, newPod *corev1.Pod) bool {
+	return oldPod.Status.PodIP != newPod.Status.PodIP ||
+		oldPod.Spec.NodeName != newPod.Spec.NodeName ||
+		utils.PodRunningAndReady(*oldPod) != utils.PodRunningAndReady(*newPod) ||
+		(oldPod.DeletionTimestamp == nil) != (newPod.DeletionTimestamp == nil)
+}
+
+func (r *DiscoveryReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	podPredicate := predicate.TypedFuncs[*corev1.Pod]{
+		CreateFunc: func(e event.TypedCreateEvent[*corev1.Pod]) bool {
+			_, exist := e.Object.Labels[workloadsv1alpha1.SetNameLabelKey]
+			return exist
+		},
+		UpdateFunc: func(e event.TypedUpdateEvent[*corev1.Pod]) bool {
+			_, exist := e.ObjectNew.Labels[workloadsv1alpha1.SetNameLabelKey]
+			return exist && discoveryChanged(e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(e event.TypedDeleteEvent[*corev1.Pod]) bool {
+			_, exist := e.Object.Labels[workloadsv1alpha1.SetNameLabelKey]
+			return exist
+		},
+		GenericFunc: func(e event.TypedGenericEvent[*corev1.Pod]) bool {
+			return false
+		},
+	}
+	podToRBG := func(ctx context.Context, pod *corev1.Pod) []ctrl.Request {
+		return []ctrl.Request{{NamespacedName: types.NamespacedName{
+			Namespace: pod.Namespace,
+			Name:      pod.Labels[workloadsv1alpha1.SetNameLabelKey],
+		}}}
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
+		Named("discovery-controller").
+		WatchesRawSource(source.TypedKind(mgr.GetCache(), &corev1.Pod{},
+			handler.TypedEnqueueRequestsFromMapFunc(podToRBG), podPredicate)).
+		Complete(r)
+}
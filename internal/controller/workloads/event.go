@@ -10,6 +10,17 @@ const (
 	Succeed                    = "Succeed"
 	FailedUpdateStatus         = "FailedUpdateStatus"
 	FailedCreatePodGroup       = "FailedCreatePodGroup"
+	FailedSyncRevision         = "FailedSyncRevision"
+	RolledBack                 = "RolledBack"
+	FailedRollback             = "FailedRollback"
+	FailedCoordinateRollout    = "FailedCoordinateRollout"
+	WorkloadFailed             = "WorkloadFailed"
+	FailedDeleteRole           = "FailedDeleteRole"
+	RestartLimitExceeded       = "RestartLimitExceeded"
+	FailedRestart              = "FailedRestart"
+	FailedAdmission            = "FailedAdmission"
+	Preempted                  = "Preempted"
+	FailedEvictRoles           = "FailedEvictRoles"
 )
 
 // rbg-scaling-adapter events
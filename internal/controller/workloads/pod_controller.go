@@ -2,11 +2,16 @@ package workloads
 
 import (
 	"fmt"
+	"time"
+
 	"golang.org/x/net/context"
 	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -16,27 +21,46 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 	"sigs.k8s.io/rbgs/pkg/dependency"
-	"sigs.k8s.io/rbgs/pkg/reconciler"
 	"sigs.k8s.io/rbgs/pkg/utils"
 )
 
+const (
+	// defaultRestartWindow is the period the restarts of a rbg are counted over by default.
+	defaultRestartWindow = time.Hour
+	// defaultRestartInitialBackoff is the delay between the first two restarts of a rbg by default.
+	defaultRestartInitialBackoff = 10 * time.Second
+	// defaultRestartMaxBackoff caps the delay between two restarts of a rbg by default.
+	defaultRestartMaxBackoff = 5 * time.Minute
+)
+
 // PodReconciler reconciles a Pod object owned by RBG
 type PodReconciler struct {
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
 func NewPodReconciler(mgr ctrl.Manager) *PodReconciler {
 	return &PodReconciler{
-		client: mgr.GetClient(),
-		scheme: mgr.GetScheme(),
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor("RoleBasedGroup"),
 	}
 }
 
-func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+// restartRequest asks to restart a rbg after a pod of Role restarted,
+// following the RestartPolicy of the role, or to evict all its roles after PreemptedPod was preempted.
+type restartRequest struct {
+	types.NamespacedName
+	Role          string
+	RestartPolicy workloadsv1alpha1.RestartPolicyType
+	PreemptedPod  string
+}
+
+func (r *PodReconciler) Reconcile(ctx context.Context, req restartRequest) (ctrl.Result, error) {
 	var rbg workloadsv1alpha1.RoleBasedGroup
 	if err := r.client.Get(ctx, types.NamespacedName{
 		Name:      req.Name,
@@ -46,7 +70,29 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	}
 	logger := log.FromContext(ctx).WithValues("rbg", klog.KObj(&rbg))
 
-	if err := r.restartRBG(ctx, &rbg); err != nil {
+	if req.PreemptedPod != "" {
+		return ctrl.Result{}, r.evictRBG(ctx, &rbg, req)
+	}
+	if restartConditionTrue(rbg.Status) {
+		logger.Info("Restart in progress, skip restarting", "role", req.Role)
+		return ctrl.Result{}, nil
+	}
+	if restartLimitExceeded(&rbg) {
+		logger.Info("Restart limit exceeded, skip restarting", "role", req.Role)
+		return ctrl.Result{}, nil
+	}
+	wait, exceeded := nextRestart(&rbg, time.Now())
+	if exceeded {
+		r.recorder.Eventf(&rbg, corev1.EventTypeWarning, RestartLimitExceeded,
+			"Skip restarting for role %s, RBG already restarted %d times within %s", req.Role, rbg.Status.Restarts.Count, restartWindow(&rbg))
+		return ctrl.Result{}, r.setRestartLimitExceededCondition(ctx, &rbg)
+	}
+	if wait > 0 {
+		logger.Info("Backing off the restart", "role", req.Role, "wait", wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	if err := r.restartRBG(ctx, &rbg, req); err != nil {
 		logger.Error(err, fmt.Sprintf("restartRBG error, err: %+v", err))
 		return ctrl.Result{}, err
 	}
@@ -54,68 +100,165 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	return ctrl.Result{}, nil
 }
 
-func (r *PodReconciler) restartRBG(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+// restartRBG starts the restart of the restarted role and the roles depending on it for
+// RecreateDependentsOnPodRestart, or of every role of rbg. The roles are queued in dependency order
+// in the status of rbg, the rbg controller recreates them one after another.
+func (r *PodReconciler) restartRBG(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, req restartRequest) error {
 	logger := log.FromContext(ctx)
-	logger.Info("Recreating RoleBasedGroup")
-
-	// 1. update rbg status
-	if err := r.setRestartCondition(ctx, rbg, false); err != nil {
-		return err
-	}
+	logger.Info("Recreating RoleBasedGroup", "restartedRole", req.Role, "restartPolicy", req.RestartPolicy)
 
-	// 2. sort role
 	dependencyManager := dependency.NewDefaultDependencyManager(r.scheme, r.client)
 	sortedRoles, err := dependencyManager.SortRoles(ctx, rbg)
 	if err != nil {
 		return err
 	}
+	var restartRoles sets.Set[string]
+	if req.RestartPolicy == workloadsv1alpha1.RecreateDependentsOnPodRestart {
+		restartRoles = dependency.DependentRoles(rbg, req.Role)
+	}
+	now := metav1.Now()
+	var roles []workloadsv1alpha1.RoleRestartStatus
 	for _, role := range sortedRoles {
-		recon, err := reconciler.NewWorkloadReconciler(role.Workload, r.scheme, r.client)
-		if err != nil {
-			return err
-		}
-		// 3. recreate role
-		if err := recon.RecreateWorkload(ctx, rbg, role); err != nil {
-			return err
+		if restartRoles != nil && !restartRoles.Has(role.Name) {
+			continue
 		}
+		roles = append(roles, workloadsv1alpha1.RoleRestartStatus{
+			Name:               role.Name,
+			Phase:              workloadsv1alpha1.RoleRestartPending,
+			LastTransitionTime: now,
+		})
 	}
 
-	// 4. remove restart status
-	if err := r.setRestartCondition(ctx, rbg, true); err != nil {
-		return err
+	recordRestart(rbg, now.Time, fmt.Sprintf("A pod of role %s restarted, restart policy %s", req.Role, req.RestartPolicy))
+	rbg.Status.Restarts.Roles = roles
+	return r.setRestartCondition(ctx, rbg, false)
+}
+
+// evictRBG asks the rbg controller to evict all the roles of rbg together, after the scheduler preempted
+// a pod of rbg, instead of leaving the roles running as a broken half-group.
+func (r *PodReconciler) evictRBG(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, req restartRequest) error {
+	if preemptionEvicting(rbg) {
+		return nil
 	}
+	logger := log.FromContext(ctx)
+	logger.Info("Evicting RoleBasedGroup after preemption", "role", req.Role, "pod", req.PreemptedPod)
+
+	message := fmt.Sprintf("Pod %s of role %s was preempted by the scheduler, evicting all the roles", req.PreemptedPod, req.Role)
+	r.recorder.Event(rbg, corev1.EventTypeWarning, Preempted, message)
+	setCondition(rbg, metav1.Condition{
+		Type:    string(workloadsv1alpha1.RoleBasedGroupPreempted),
+		Status:  metav1.ConditionTrue,
+		Reason:  preemptionEvictingReason,
+		Message: message,
+	})
+
+	rbgApplyConfig := utils.RoleBasedGroup(rbg.Name, rbg.Namespace, rbg.Kind, rbg.APIVersion).
+		WithStatus(utils.RbgStatus().WithRoleStatuses(rbg.Status.RoleStatuses).WithConditions(rbg.Status.Conditions).
+			WithRollout(rbg.Status.Rollout).WithRestarts(rbg.Status.Restarts).WithObservedGeneration(rbg.Status.ObservedGeneration))
 
-	return nil
+	return utils.PatchObjectApplyConfiguration(ctx, r.client, rbgApplyConfig, utils.PatchStatus)
 }
 
 func (r *PodReconciler) setRestartCondition(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, restartCompleted bool) error {
-	var restartCondition metav1.Condition
-	if restartCompleted {
-		restartCondition = metav1.Condition{
-			Type:               string(workloadsv1alpha1.RoleBasedGroupRestartInProgress),
-			Status:             metav1.ConditionStatus(corev1.ConditionFalse),
-			LastTransitionTime: metav1.Now(),
-			Reason:             "RBGRestartCompleted",
-			Message:            "RBG Restart Completed",
-		}
-	} else {
-		restartCondition = metav1.Condition{
-			Type:               string(workloadsv1alpha1.RoleBasedGroupRestartInProgress),
-			Status:             metav1.ConditionStatus(corev1.ConditionTrue),
-			LastTransitionTime: metav1.Now(),
-			Reason:             "RBGRestart",
-			Message:            "RBG Restart in progress",
-		}
-	}
+	setCondition(rbg, restartCondition(restartCompleted))
+
+	rbgApplyConfig := utils.RoleBasedGroup(rbg.Name, rbg.Namespace, rbg.Kind, rbg.APIVersion).
+		WithStatus(utils.RbgStatus().WithRoleStatuses(rbg.Status.RoleStatuses).WithConditions(rbg.Status.Conditions).
+			WithRollout(rbg.Status.Rollout).WithRestarts(rbg.Status.Restarts).WithObservedGeneration(rbg.Status.ObservedGeneration))
 
-	setCondition(rbg, restartCondition)
+	return utils.PatchObjectApplyConfiguration(ctx, r.client, rbgApplyConfig, utils.PatchStatus)
+}
+
+// setRestartLimitExceededCondition marks rbg as not restarted anymore until its spec is updated.
+func (r *PodReconciler) setRestartLimitExceededCondition(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	condition := metav1.Condition{
+		Type:   string(workloadsv1alpha1.RoleBasedGroupRestartLimitExceeded),
+		Status: metav1.ConditionTrue,
+		Reason: "TooManyRestarts",
+		Message: fmt.Sprintf("RBG restarted %d times since %s, it is not restarted anymore until the spec is updated",
+			rbg.Status.Restarts.Count, rbg.Status.Restarts.WindowStartTime.Format(time.RFC3339)),
+	}
+	setCondition(rbg, condition)
 
 	rbgApplyConfig := utils.RoleBasedGroup(rbg.Name, rbg.Namespace, rbg.Kind, rbg.APIVersion).
-		WithStatus(utils.RbgStatus().WithRoleStatuses(rbg.Status.RoleStatuses).WithConditions(rbg.Status.Conditions))
+		WithStatus(utils.RbgStatus().WithRoleStatuses(rbg.Status.RoleStatuses).WithConditions(rbg.Status.Conditions).
+			WithRollout(rbg.Status.Rollout).WithRestarts(rbg.Status.Restarts).WithObservedGeneration(rbg.Status.ObservedGeneration))
 
 	return utils.PatchObjectApplyConfiguration(ctx, r.client, rbgApplyConfig, utils.PatchStatus)
 }
 
+// restartLimitExceeded reports whether rbg exceeded its restart limit at its current generation.
+func restartLimitExceeded(rbg *workloadsv1alpha1.RoleBasedGroup) bool {
+	cond := apimeta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupRestartLimitExceeded))
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.ObservedGeneration == rbg.Generation
+}
+
+// restartWindow returns the period the restarts of rbg are counted over.
+func restartWindow(rbg *workloadsv1alpha1.RoleBasedGroup) time.Duration {
+	if rbg.Spec.RestartStrategy == nil || rbg.Spec.RestartStrategy.WindowSeconds <= 0 {
+		return defaultRestartWindow
+	}
+	return time.Duration(rbg.Spec.RestartStrategy.WindowSeconds) * time.Second
+}
+
+// restartWindowExpired reports whether the next restart of rbg at now starts a new window.
+// The window also starts over once the spec is updated after the restart limit was exceeded.
+func restartWindowExpired(rbg *workloadsv1alpha1.RoleBasedGroup, now time.Time) bool {
+	restarts := rbg.Status.Restarts
+	if restarts == nil || restarts.WindowStartTime == nil || !now.Before(restarts.WindowStartTime.Add(restartWindow(rbg))) {
+		return true
+	}
+	return apimeta.IsStatusConditionTrue(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupRestartLimitExceeded))
+}
+
+// nextRestart returns how long the restart of rbg at now has to be backed off,
+// or whether the restart would exceed the restart limit of rbg.
+func nextRestart(rbg *workloadsv1alpha1.RoleBasedGroup, now time.Time) (time.Duration, bool) {
+	if restartWindowExpired(rbg, now) {
+		return 0, false
+	}
+	restarts := rbg.Status.Restarts
+	strategy := rbg.Spec.RestartStrategy
+	if strategy != nil && strategy.MaxRestarts > 0 && restarts.Count >= strategy.MaxRestarts {
+		return 0, true
+	}
+	if restarts.Count < 1 || restarts.LastRestartTime == nil {
+		return 0, false
+	}
+
+	initialBackoff, maxBackoff := defaultRestartInitialBackoff, defaultRestartMaxBackoff
+	if strategy != nil {
+		initialBackoff = time.Duration(strategy.InitialBackoffSeconds) * time.Second
+		maxBackoff = time.Duration(strategy.MaxBackoffSeconds) * time.Second
+	}
+	backoff := initialBackoff
+	for i := int32(1); i < restarts.Count && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, maxBackoff)
+	return max(restarts.LastRestartTime.Add(backoff).Sub(now), 0), false
+}
+
+// recordRestart records in the status of rbg that it restarted at now for reason.
+func recordRestart(rbg *workloadsv1alpha1.RoleBasedGroup, now time.Time, reason string) {
+	if restartWindowExpired(rbg, now) {
+		if apimeta.IsStatusConditionTrue(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupRestartLimitExceeded)) {
+			setCondition(rbg, metav1.Condition{
+				Type:    string(workloadsv1alpha1.RoleBasedGroupRestartLimitExceeded),
+				Status:  metav1.ConditionFalse,
+				Reason:  "RestartLimitReset",
+				Message: "The spec was updated since the restart limit was exceeded",
+			})
+		}
+		rbg.Status.Restarts = &workloadsv1alpha1.RestartStatus{WindowStartTime: &metav1.Time{Time: now}}
+	} else {
+		rbg.Status.Restarts = rbg.Status.Restarts.DeepCopy()
+	}
+	rbg.Status.Restarts.Count++
+	rbg.Status.Restarts.LastRestartTime = &metav1.Time{Time: now}
+	rbg.Status.Restarts.LastRestartReason = reason
+}
+
 func restartConditionTrue(status workloadsv1alpha1.RoleBasedGroupStatus) bool {
 	for _, cond := range status.Conditions {
 		if cond.Type == string(workloadsv1alpha1.RoleBasedGroupRestartInProgress) {
@@ -125,34 +268,22 @@ func restartConditionTrue(status workloadsv1alpha1.RoleBasedGroupStatus) bool {
 	return false
 }
 
+// setCondition sets newCondition in the status of rbg at its current generation.
+// The LastTransitionTime of the condition only changes when its status changes.
 func setCondition(rbg *workloadsv1alpha1.RoleBasedGroup, newCondition metav1.Condition) {
-	found := false
-	for i, curCondition := range rbg.Status.Conditions {
-		if newCondition.Type == curCondition.Type {
-			found = true
-			if newCondition.Status != curCondition.Status {
-				rbg.Status.Conditions[i] = newCondition
-			}
-		}
-	}
-	if !found {
-		rbg.Status.Conditions = append(rbg.Status.Conditions, newCondition)
-	}
+	newCondition.ObservedGeneration = rbg.Generation
+	apimeta.SetStatusCondition(&rbg.Status.Conditions, newCondition)
 }
 
-func (r *PodReconciler) podToRBG(ctx context.Context, obj client.Object) []reconcile.Request {
-	pod, ok := obj.(*corev1.Pod)
-	if !ok {
-		return []reconcile.Request{}
-	}
-
+func (r *PodReconciler) podToRBG(ctx context.Context, pod *corev1.Pod) []restartRequest {
 	rbgName := pod.Labels[workloadsv1alpha1.SetNameLabelKey]
 	if rbgName == "" {
-		return []reconcile.Request{}
+		return []restartRequest{}
 	}
 
-	if !utils.ContainerRestarted(pod) && !utils.PodDeleted(pod) {
-		return []reconcile.Request{}
+	preempted := utils.PodPreempted(pod)
+	if !preempted && !utils.ContainerRestarted(pod) && !utils.PodDeleted(pod) {
+		return []restartRequest{}
 	}
 
 	logger := log.FromContext(ctx).WithValues("Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name)
@@ -161,72 +292,87 @@ func (r *PodReconciler) podToRBG(ctx context.Context, obj client.Object) []recon
 	var rbg workloadsv1alpha1.RoleBasedGroup
 	err := r.client.Get(ctx, types.NamespacedName{Name: rbgName, Namespace: pod.Namespace}, &rbg)
 	if err != nil || rbg.DeletionTimestamp != nil {
-		return []reconcile.Request{}
+		return []restartRequest{}
+	}
+
+	// the pods of the roles stopped by the controller are deleted on purpose
+	if preemptionEvicting(&rbg) ||
+		apimeta.IsStatusConditionTrue(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupSuspended)) {
+		logger.V(1).Info("rbg roles are stopped, skip handle pod event")
+		return []restartRequest{}
+	}
+
+	roleName := pod.Labels[workloadsv1alpha1.SetRoleLabelKey]
+	if preempted && rbg.EvictOnPodPreemption() {
+		return []restartRequest{{
+			NamespacedName: types.NamespacedName{Name: rbgName, Namespace: rbg.Namespace},
+			Role:           roleName,
+			PreemptedPod:   pod.Name,
+		}}
+	}
+
+	if restartLimitExceeded(&rbg) {
+		logger.V(1).Info("rbg exceeded its restart limit, skip handle pod restart event")
+		return []restartRequest{}
 	}
 
 	// if rbg is in restart status, it means that a pod has already been restarted and the rbg is in restarting process now.
 	// So, skip to handle this pod restart event to avoid restarting rbg repeatedly.
 	if restartConditionTrue(rbg.Status) {
 		logger.V(1).Info("rbg is already in restart status, skip handle pod restart event")
-		return []reconcile.Request{}
+		return []restartRequest{}
 	}
 
-	roleName := pod.Labels[workloadsv1alpha1.SetRoleLabelKey]
 	if roleName == "" {
-		return []reconcile.Request{}
+		return []restartRequest{}
 	}
 
 	curRole, err := rbg.GetRole(roleName)
 	if err != nil {
-		return []reconcile.Request{}
+		return []restartRequest{}
 	}
 
 	// 1. if RestartPolicy is None, do nothing
 	// 2. if RestartPolicy is RecreateRoleInstanceOnPodRestart, the lws controller will recreate lws. RBG controller does nothing.
-	if curRole.RestartPolicy != workloadsv1alpha1.RecreateRBGOnPodRestart {
-		return []reconcile.Request{}
+	// 3. if RestartPolicy is RecreateDependentsOnPodRestart, restart the role and the roles depending on it.
+	if curRole.RestartPolicy != workloadsv1alpha1.RecreateRBGOnPodRestart &&
+		curRole.RestartPolicy != workloadsv1alpha1.RecreateDependentsOnPodRestart {
+		return []restartRequest{}
 	}
-
-	// restart rbg
-	return []reconcile.Request{{
-		NamespacedName: types.NamespacedName{
-			Name:      rbgName,
-			Namespace: rbg.Namespace,
-		},
+	return []restartRequest{{
+		NamespacedName: types.NamespacedName{Name: rbgName, Namespace: rbg.Namespace},
+		Role:           roleName,
+		RestartPolicy:  curRole.RestartPolicy,
 	}}
 }
 
 func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
-	podPredicate := predicate.Funcs{
-		CreateFunc: func(e event.CreateEvent) bool {
+	podPredicate := predicate.TypedFuncs[*corev1.Pod]{
+		CreateFunc: func(e event.TypedCreateEvent[*corev1.Pod]) bool {
 			return false
 		},
-		UpdateFunc: func(e event.UpdateEvent) bool {
-			oldPod, ok1 := e.ObjectOld.(*corev1.Pod)
-			newPod, ok2 := e.ObjectNew.(*corev1.Pod)
-			if ok1 && ok2 {
-				_, oldExist := oldPod.Labels[workloadsv1alpha1.SetNameLabelKey]
-				_, newExist := newPod.Labels[workloadsv1alpha1.SetNameLabelKey]
-				return oldExist && newExist
-
-			}
-			return false
+		UpdateFunc: func(e event.TypedUpdateEvent[*corev1.Pod]) bool {
+			_, oldExist := e.ObjectOld.Labels[workloadsv1alpha1.SetNameLabelKey]
+			_, newExist := e.ObjectNew.Labels[workloadsv1alpha1.SetNameLabelKey]
+			return oldExist && newExist
 		},
-		DeleteFunc: func(e event.DeleteEvent) bool {
-			if pod, ok := e.Object.(*corev1.Pod); ok {
-				_, exist := pod.Labels[workloadsv1alpha1.SetNameLabelKey]
-				return exist
-			}
-			return false
+		DeleteFunc: func(e event.TypedDeleteEvent[*corev1.Pod]) bool {
+			_, exist := e.Object.Labels[workloadsv1alpha1.SetNameLabelKey]
+			return exist
 		},
-		GenericFunc: func(e event.GenericEvent) bool {
+		GenericFunc: func(e event.TypedGenericEvent[*corev1.Pod]) bool {
 			return false
 		},
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
-		WithOptions(options).
+	// the requests carry the restarted role, which the restart policy may recreate alone with its dependents
+	return builder.TypedControllerManagedBy[restartRequest](mgr).
+		WithOptions(controller.TypedOptions[restartRequest]{
+			MaxConcurrentReconciles: options.MaxConcurrentReconciles,
+			CacheSyncTimeout:        options.CacheSyncTimeout,
+		}).
 		Named("pod-controller").
-		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.podToRBG), builder.WithPredicates(podPredicate)).
+		WatchesRawSource(source.TypedKind(mgr.GetCache(), &corev1.Pod{},
+			handler.TypedEnqueueRequestsFromMapFunc(r.podToRBG), podPredicate)).
 		Complete(r)
 }
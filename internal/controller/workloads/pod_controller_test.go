@@ -10,10 +10,10 @@ import (
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 	"sigs.k8s.io/rbgs/test/wrappers"
 	"testing"
+	"time"
 )
 
 func TestPodReconciler_setRestartCondition(t *testing.T) {
@@ -97,16 +97,23 @@ func TestPodReconciler_podToRBG(t *testing.T) {
 		workloadsv1alpha1.SetRoleLabelKey: "test-role",
 		workloadsv1alpha1.SetNameLabelKey: "restart-policy",
 	}).Obj()
+	preemptedPod := *pod.DeepCopy()
+	preemptedPod.Status.Conditions = []corev1.PodCondition{{
+		Type:   corev1.DisruptionTarget,
+		Status: corev1.ConditionTrue,
+		Reason: corev1.PodReasonPreemptionByScheduler,
+	}}
 
 	type args struct {
-		ctx  context.Context
-		obj  corev1.Pod
-		role workloadsv1alpha1.RoleSpec
+		ctx        context.Context
+		obj        corev1.Pod
+		role       workloadsv1alpha1.RoleSpec
+		preemption workloadsv1alpha1.PreemptionPolicyType
 	}
 	tests := []struct {
 		name string
 		args args
-		want []reconcile.Request
+		want []restartRequest
 	}{
 		{
 			name: "RecreateRBGOnPodRestart",
@@ -117,12 +124,14 @@ func TestPodReconciler_podToRBG(t *testing.T) {
 					WithRestartPolicy(workloadsv1alpha1.RecreateRBGOnPodRestart).
 					Obj(),
 			},
-			want: []reconcile.Request{
+			want: []restartRequest{
 				{
 					NamespacedName: types.NamespacedName{
 						Name:      "restart-policy",
 						Namespace: "default",
 					},
+					Role:          "test-role",
+					RestartPolicy: workloadsv1alpha1.RecreateRBGOnPodRestart,
 				},
 			},
 		},
@@ -135,7 +144,7 @@ func TestPodReconciler_podToRBG(t *testing.T) {
 					WithRestartPolicy(workloadsv1alpha1.NoneRestartPolicy).
 					Obj(),
 			},
-			want: []reconcile.Request{},
+			want: []restartRequest{},
 		},
 		{
 			name: "RecreateRoleInstanceOnPodRestart",
@@ -146,7 +155,59 @@ func TestPodReconciler_podToRBG(t *testing.T) {
 					WithRestartPolicy(workloadsv1alpha1.RecreateRoleInstanceOnPodRestart).
 					Obj(),
 			},
-			want: []reconcile.Request{},
+			want: []restartRequest{},
+		},
+		{
+			name: "RecreateDependentsOnPodRestart",
+			args: args{
+				ctx: context.TODO(),
+				obj: pod,
+				role: wrappers.BuildBasicRole("test-role").
+					WithRestartPolicy(workloadsv1alpha1.RecreateDependentsOnPodRestart).
+					Obj(),
+			},
+			want: []restartRequest{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "restart-policy",
+						Namespace: "default",
+					},
+					Role:          "test-role",
+					RestartPolicy: workloadsv1alpha1.RecreateDependentsOnPodRestart,
+				},
+			},
+		},
+		{
+			name: "pod-preempted",
+			args: args{
+				ctx: context.TODO(),
+				obj: preemptedPod,
+				role: wrappers.BuildBasicRole("test-role").
+					WithRestartPolicy(workloadsv1alpha1.NoneRestartPolicy).
+					Obj(),
+				preemption: workloadsv1alpha1.EvictRBGOnPodPreemption,
+			},
+			want: []restartRequest{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "restart-policy",
+						Namespace: "default",
+					},
+					Role:         "test-role",
+					PreemptedPod: "test-pod",
+				},
+			},
+		},
+		{
+			name: "pod-preempted-without-preemption-policy",
+			args: args{
+				ctx: context.TODO(),
+				obj: preemptedPod,
+				role: wrappers.BuildBasicRole("test-role").
+					WithRestartPolicy(workloadsv1alpha1.NoneRestartPolicy).
+					Obj(),
+			},
+			want: []restartRequest{},
 		},
 		{
 			name: "pod-running",
@@ -160,13 +221,14 @@ func TestPodReconciler_podToRBG(t *testing.T) {
 					WithRestartPolicy(workloadsv1alpha1.RecreateRBGOnPodRestart).
 					Obj(),
 			},
-			want: []reconcile.Request{},
+			want: []restartRequest{},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			rbg := wrappers.BuildBasicRoleBasedGroup("restart-policy", "default").
-				WithRoles([]workloadsv1alpha1.RoleSpec{tt.args.role}).Obj()
+				WithRoles([]workloadsv1alpha1.RoleSpec{tt.args.role}).
+				WithPreemptionPolicy(tt.args.preemption).Obj()
 			fclient := fake.NewClientBuilder().WithScheme(schema).WithObjects(&tt.args.obj, rbg).Build()
 
 			r := &PodReconciler{
@@ -179,3 +241,117 @@ func TestPodReconciler_podToRBG(t *testing.T) {
 		})
 	}
 }
+
+func Test_nextRestart(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) *metav1.Time {
+		return &metav1.Time{Time: now.Add(-d)}
+	}
+	strategy := &workloadsv1alpha1.RestartStrategy{
+		MaxRestarts:           3,
+		WindowSeconds:         600,
+		InitialBackoffSeconds: 10,
+		MaxBackoffSeconds:     30,
+	}
+	limitExceeded := metav1.Condition{
+		Type:               string(workloadsv1alpha1.RoleBasedGroupRestartLimitExceeded),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 1,
+	}
+
+	tests := []struct {
+		name         string
+		strategy     *workloadsv1alpha1.RestartStrategy
+		restarts     *workloadsv1alpha1.RestartStatus
+		conditions   []metav1.Condition
+		wantWait     time.Duration
+		wantExceeded bool
+	}{
+		{
+			name:     "first restart",
+			strategy: strategy,
+		},
+		{
+			name:     "initial backoff",
+			strategy: strategy,
+			restarts: &workloadsv1alpha1.RestartStatus{Count: 1, WindowStartTime: ago(time.Minute), LastRestartTime: ago(4 * time.Second)},
+			wantWait: 6 * time.Second,
+		},
+		{
+			name:     "exponential backoff",
+			strategy: strategy,
+			restarts: &workloadsv1alpha1.RestartStatus{Count: 2, WindowStartTime: ago(time.Minute), LastRestartTime: ago(5 * time.Second)},
+			wantWait: 15 * time.Second,
+		},
+		{
+			name:     "backoff elapsed",
+			strategy: strategy,
+			restarts: &workloadsv1alpha1.RestartStatus{Count: 2, WindowStartTime: ago(time.Minute), LastRestartTime: ago(30 * time.Second)},
+		},
+		{
+			name:     "default backoff capped",
+			restarts: &workloadsv1alpha1.RestartStatus{Count: 10, WindowStartTime: ago(time.Minute), LastRestartTime: ago(time.Minute)},
+			wantWait: 4 * time.Minute,
+		},
+		{
+			name:         "limit exceeded",
+			strategy:     strategy,
+			restarts:     &workloadsv1alpha1.RestartStatus{Count: 3, WindowStartTime: ago(time.Minute), LastRestartTime: ago(time.Minute)},
+			wantExceeded: true,
+		},
+		{
+			name:     "window expired",
+			strategy: strategy,
+			restarts: &workloadsv1alpha1.RestartStatus{Count: 3, WindowStartTime: ago(20 * time.Minute), LastRestartTime: ago(time.Second)},
+		},
+		{
+			name:       "limit reset by spec update",
+			strategy:   strategy,
+			restarts:   &workloadsv1alpha1.RestartStatus{Count: 3, WindowStartTime: ago(time.Minute), LastRestartTime: ago(time.Minute)},
+			conditions: []metav1.Condition{limitExceeded},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").Obj()
+			rbg.Generation = 2
+			rbg.Spec.RestartStrategy = tt.strategy
+			rbg.Status.Restarts = tt.restarts
+			rbg.Status.Conditions = tt.conditions
+
+			wait, exceeded := nextRestart(rbg, now)
+			if wait != tt.wantWait || exceeded != tt.wantExceeded {
+				t.Errorf("nextRestart() = (%v, %v), want (%v, %v)", wait, exceeded, tt.wantWait, tt.wantExceeded)
+			}
+		})
+	}
+}
+
+func Test_recordRestart(t *testing.T) {
+	now := time.Now()
+	windowStart := metav1.NewTime(now.Add(-time.Minute))
+
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").Obj()
+	recordRestart(rbg, now, "first")
+	want := &workloadsv1alpha1.RestartStatus{
+		Count:             1,
+		WindowStartTime:   &metav1.Time{Time: now},
+		LastRestartTime:   &metav1.Time{Time: now},
+		LastRestartReason: "first",
+	}
+	if !reflect.DeepEqual(rbg.Status.Restarts, want) {
+		t.Errorf("recordRestart() = %+v, want %+v", rbg.Status.Restarts, want)
+	}
+
+	rbg.Status.Restarts.WindowStartTime = &windowStart
+	recordRestart(rbg, now, "second")
+	want = &workloadsv1alpha1.RestartStatus{
+		Count:             2,
+		WindowStartTime:   &windowStart,
+		LastRestartTime:   &metav1.Time{Time: now},
+		LastRestartReason: "second",
+	}
+	if !reflect.DeepEqual(rbg.Status.Restarts, want) {
+		t.Errorf("recordRestart() = %+v, want %+v", rbg.Status.Restarts, want)
+	}
+}
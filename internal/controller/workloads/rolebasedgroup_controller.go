@@ -20,17 +20,24 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"sync"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -40,14 +47,23 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-	lwsv1 "sigs.k8s.io/lws/api/leaderworkerset/v1"
 	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 	"sigs.k8s.io/rbgs/pkg/dependency"
+	"sigs.k8s.io/rbgs/pkg/history"
+	"sigs.k8s.io/rbgs/pkg/kueue"
 	"sigs.k8s.io/rbgs/pkg/reconciler"
 	"sigs.k8s.io/rbgs/pkg/scale"
 	"sigs.k8s.io/rbgs/pkg/scheduler"
 	"sigs.k8s.io/rbgs/pkg/utils"
-	schev1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+const (
+	// rolloutRequeueInterval is the interval the progress of a coordinated rollout is checked at.
+	rolloutRequeueInterval = 5 * time.Second
+	// dependencyRequeueInterval is the interval the dependencies of waiting roles are checked at.
+	dependencyRequeueInterval = 5 * time.Second
+	// defaultMaxConcurrentRoleReconciles is the default number of roles of a group reconciled concurrently.
+	defaultMaxConcurrentRoleReconciles = 4
 )
 
 var (
@@ -65,6 +81,9 @@ type RoleBasedGroupReconciler struct {
 	apiReader client.Reader
 	scheme    *runtime.Scheme
 	recorder  record.EventRecorder
+	prober    dependency.Prober
+
+	maxConcurrentRoleReconciles int
 }
 
 func NewRoleBasedGroupReconciler(mgr ctrl.Manager) *RoleBasedGroupReconciler {
@@ -73,6 +92,23 @@ func NewRoleBasedGroupReconciler(mgr ctrl.Manager) *RoleBasedGroupReconciler {
 		apiReader: mgr.GetAPIReader(),
 		scheme:    mgr.GetScheme(),
 		recorder:  mgr.GetEventRecorderFor("RoleBasedGroup"),
+		prober:    dependency.NewDefaultProber(nil),
+
+		maxConcurrentRoleReconciles: defaultMaxConcurrentRoleReconciles,
+	}
+}
+
+// EnableExecDependencyProbes runs the exec dependency probes through the exec subresource of the pods
+// with config. The controller needs to be allowed to create pods/exec.
+func (r *RoleBasedGroupReconciler) EnableExecDependencyProbes(config *rest.Config) {
+	r.prober = dependency.NewDefaultProber(config)
+}
+
+// SetMaxConcurrentRoleReconciles sets the number of roles of a group, whose dependencies are ready,
+// reconciled concurrently.
+func (r *RoleBasedGroupReconciler) SetMaxConcurrentRoleReconciles(n int) {
+	if n > 0 {
+		r.maxConcurrentRoleReconciles = n
 	}
 }
 
@@ -87,95 +123,153 @@ func (r *RoleBasedGroupReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			"Failed to get rbg, err: %s", err.Error())
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-	if rbg.DeletionTimestamp != nil {
-		return ctrl.Result{}, nil
-	}
 
 	logger := log.FromContext(ctx).WithValues("rbg", klog.KObj(rbg))
 	ctx = ctrl.LoggerInto(ctx, logger)
+	if rbg.DeletionTimestamp != nil {
+		return r.finalize(ctx, rbg)
+	}
 	logger.Info("Start reconciling")
 
+	if err := r.addFinalizer(ctx, rbg); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if rbg.Spec.RollbackTo != nil {
+		if err := r.rollback(ctx, rbg); err != nil {
+			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedRollback,
+				"Failed to roll back %s: %v", rbg.Name, err)
+			return ctrl.Result{}, err
+		}
+		// the restored spec triggers a new reconcile
+		return ctrl.Result{}, nil
+	}
+
+	// Evict all the roles together once some pods were preempted
+	evicting, err := r.reconcilePreemption(ctx, rbg)
+	if err != nil {
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedEvictRoles,
+			"Failed to evict the roles of %s after preemption: %v", rbg.Name, err)
+		return ctrl.Result{}, err
+	}
+	if evicting {
+		return ctrl.Result{RequeueAfter: deletionRequeueInterval}, nil
+	}
+
+	// Advance the restart in progress, the roles being deleted are not reconciled
+	restarting, err := r.reconcileRestart(ctx, rbg)
+	if err != nil {
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedRestart,
+			"Failed to restart %s: %v", rbg.Name, err)
+		return ctrl.Result{}, err
+	}
+
 	// Process roles in dependency order
 	dependencyManager := dependency.NewDefaultDependencyManager(r.scheme, r.client)
-	sortedRoles, err := dependencyManager.SortRoles(ctx, rbg)
+	dependencyManager.SetProber(r.prober)
+	roleLevels, err := dependencyManager.LevelRoles(ctx, rbg)
 	if err != nil {
 		r.recorder.Event(rbg, corev1.EventTypeWarning, InvalidRoleDependency, err.Error())
 		return ctrl.Result{}, err
 	}
+	var sortedRoles []*workloadsv1alpha1.RoleSpec
+	for _, level := range roleLevels {
+		sortedRoles = append(sortedRoles, level...)
+	}
 
-	// watch podGroup
-	_, podGroupExist := watchedWorkload.Load(utils.PodGroupCrdName)
-	if rbg.EnableGangScheduling() && !podGroupExist {
-		err = utils.CheckCrdExists(r.apiReader, utils.PodGroupCrdName)
-		if err == nil {
-			watchedWorkload.LoadOrStore(utils.PodGroupCrdName, struct{}{})
-			runtimeController.Owns(&schev1alpha1.PodGroup{})
-			logger.Info("rbgs controller watch PodGroup CRD")
-			podGroupExist = true
-		} else {
-			logger.Error(err, "failed watch PodGroup CRD")
-		}
+	// Record the revision of each role
+	updateRevisions, err := history.NewDefaultRevisionManager(r.client).SyncRoleRevisions(ctx, rbg)
+	if err != nil {
+		r.recorder.Event(rbg, corev1.EventTypeWarning, FailedSyncRevision, err.Error())
+		return ctrl.Result{}, err
 	}
+
 	// Process PodGroup
-	if podGroupExist {
-		podGroupManager := scheduler.NewPodGroupScheduler(r.client)
-		if err := podGroupManager.Reconcile(ctx, rbg); err != nil {
+	podGroupScheduler := scheduler.NewPodGroupScheduler(r.client)
+	for _, backend := range podGroupScheduler.Backends() {
+		// watch podGroup
+		_, podGroupExist := watchedWorkload.Load(backend.CrdName())
+		if backend.Enabled(rbg) && !podGroupExist {
+			err = utils.CheckCrdExists(r.apiReader, backend.CrdName())
+			if err == nil {
+				watchedWorkload.LoadOrStore(backend.CrdName(), struct{}{})
+				runtimeController.Owns(backend.PodGroupObject())
+				logger.Info("rbgs controller watch PodGroup CRD", "crd", backend.CrdName())
+				podGroupExist = true
+			} else {
+				logger.Error(err, "failed watch PodGroup CRD", "crd", backend.CrdName())
+			}
+		}
+		if !podGroupExist {
+			continue
+		}
+		if err := podGroupScheduler.Reconcile(ctx, rbg, backend); err != nil {
 			r.recorder.Event(rbg, corev1.EventTypeWarning, FailedCreatePodGroup, err.Error())
 			return ctrl.Result{}, err
 		}
 	}
 
-	// Reconcile role, add & update
-	var roleStatuses []workloadsv1alpha1.RoleStatus
-	var updateStatus bool
-	for _, role := range sortedRoles {
-		logger := log.FromContext(ctx)
-		roleCtx := log.IntoContext(ctx, logger.WithValues("role", role.Name))
-
-		// first check whether watch lws cr
-		dynamicWatchCustomCRD(roleCtx, role.Workload.Kind)
-		// Check dependencies first
-		ready, err := dependencyManager.CheckDependencyReady(roleCtx, rbg, role)
-		if err != nil {
-			r.recorder.Event(rbg, corev1.EventTypeWarning, FailedCheckRoleDependency, err.Error())
-			return ctrl.Result{}, err
-		}
-		if !ready {
-			logger.Info("Dependencies not met, requeuing", "role", role.Name)
-			return ctrl.Result{RequeueAfter: 5}, nil
-		}
-
-		reconciler, err := reconciler.NewWorkloadReconciler(role.Workload, r.scheme, r.client)
-		if err != nil {
-			logger.Error(err, "Failed to create workload reconciler")
-			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
-				"Failed to reconcile role %s: %v", role.Name, err)
-			return ctrl.Result{}, err
+	// Hold the roles until Kueue admits the whole group
+	admitted, stopping, placements, err := r.reconcileAdmission(ctx, rbg)
+	if err != nil {
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedAdmission,
+			"Failed to reconcile the Kueue admission of %s: %v", rbg.Name, err)
+		return ctrl.Result{}, err
+	}
+	if !admitted {
+		logger.Info("Waiting for Kueue admission", "queue", rbg.GetQueueName())
+		if stopping {
+			return ctrl.Result{RequeueAfter: deletionRequeueInterval}, nil
 		}
+		return ctrl.Result{}, nil
+	}
 
-		if err := reconciler.Reconciler(roleCtx, rbg, role); err != nil {
-			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
-				"Failed to reconcile role %s: %v", role.Name, err)
-			return ctrl.Result{}, err
-		}
+	// Coordinate the rollout of the roles
+	rolloutGates, err := r.reconcileRollout(ctx, rbg, sortedRoles, updateRevisions)
+	if err != nil {
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedCoordinateRollout,
+			"Failed to coordinate the rollout of %s: %v", rbg.Name, err)
+		return ctrl.Result{}, err
+	}
 
-		if err := r.ReconcileScalingAdapter(roleCtx, rbg, role); err != nil {
-			logger.Error(err, "Failed to reconcile scaling adapter")
-			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedCreateScalingAdapter,
-				"Failed to reconcile scaling adapter for role %s: %v", role.Name, err)
-			return ctrl.Result{}, err
+	// Reconcile the roles level by level of the dependency DAG, the roles of a level concurrently
+	var roleStatuses []workloadsv1alpha1.RoleStatus
+	updateStatus := rbg.Status.ObservedGeneration != rbg.Generation
+	waitingRoles := sets.New[string]()
+	for _, level := range roleLevels {
+		for _, role := range level {
+			// first check whether the workload of the role is watched
+			dynamicWatchCustomCRD(ctx, role.Workload)
 		}
 
-		roleStatus, updateRoleStatus, err := reconciler.ConstructRoleStatus(roleCtx, rbg, role)
-		if err != nil {
-			if !apierrors.IsNotFound(err) {
-				r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
-					"Failed to construct role %s status: %v", role.Name, err)
+		results := make([]roleResult, len(level))
+		workqueue.ParallelizeUntil(ctx, max(r.maxConcurrentRoleReconciles, 1), len(level), func(i int) {
+			role := level[i]
+			roleCtx := log.IntoContext(ctx, logger.WithValues("role", role.Name))
+			if gate, ok := rolloutGates[role.Name]; ok {
+				roleCtx = reconciler.WithRolloutGate(roleCtx, gate)
 			}
-			return ctrl.Result{}, err
+			if placement, ok := placements[role.Name]; ok {
+				roleCtx = reconciler.WithPlacement(roleCtx, placement)
+			}
+			results[i] = r.reconcileRole(roleCtx, rbg, role, dependencyManager, waitingRoles, updateRevisions)
+		})
+
+		var errs []error
+		for i, result := range results {
+			if result.err != nil {
+				errs = append(errs, result.err)
+				continue
+			}
+			if result.waiting {
+				waitingRoles.Insert(level[i].Name)
+			}
+			updateStatus = updateStatus || result.updateStatus
+			roleStatuses = append(roleStatuses, result.status)
+		}
+		if len(errs) > 0 {
+			return ctrl.Result{}, errors.NewAggregate(errs)
 		}
-		updateStatus = updateStatus || updateRoleStatus
-		roleStatuses = append(roleStatuses, roleStatus)
 	}
 
 	if updateStatus {
@@ -187,71 +281,183 @@ func (r *RoleBasedGroupReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	}
 
 	// delete role
-	if err := r.deleteRoles(ctx, rbg); err != nil {
-		r.recorder.Eventf(rbg, corev1.EventTypeWarning, "delete role error",
+	deleted, err := r.deleteRoles(ctx, rbg)
+	if err != nil {
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedDeleteRole,
 			"Failed to delete roles for %s: %v", rbg.Name, err)
 		return ctrl.Result{}, err
 	}
+	if !deleted {
+		return ctrl.Result{RequeueAfter: deletionRequeueInterval}, nil
+	}
+
+	if restarting {
+		return ctrl.Result{RequeueAfter: restartRequeueInterval}, nil
+	}
+
+	if waitingRoles.Len() > 0 {
+		logger.Info("Dependencies not met, requeuing", "roles", sets.List(waitingRoles))
+		return ctrl.Result{RequeueAfter: dependencyRequeueInterval}, nil
+	}
 
 	r.recorder.Event(rbg, corev1.EventTypeNormal, Succeed, "ReconcileSucceed")
+	if rbg.Status.Rollout != nil {
+		// workloads do not notify every step of their rollout, check the progress periodically
+		return ctrl.Result{RequeueAfter: rolloutRequeueInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
-func (r *RoleBasedGroupReconciler) deleteRoles(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
-	errs := make([]error, 0)
-	deployRecon := reconciler.NewDeploymentReconciler(r.scheme, r.client)
-	if err := deployRecon.CleanupOrphanedWorkloads(ctx, rbg); err != nil {
-		errs = append(errs, err)
+// roleResult is the outcome of reconciling a role.
+type roleResult struct {
+	status       workloadsv1alpha1.RoleStatus
+	updateStatus bool
+	// waiting is true if the dependencies of the role are not ready, its workload is left untouched
+	waiting bool
+	err     error
+}
+
+// reconcileRole reconciles the workload of role once its dependencies are ready, and constructs its status.
+// Roles depending on a role in waitingRoles keep waiting without checking their dependencies.
+func (r *RoleBasedGroupReconciler) reconcileRole(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec, dependencyManager dependency.DependencyManager, waitingRoles sets.Set[string],
+	updateRevisions map[string]*appsv1.ControllerRevision) roleResult {
+	logger := log.FromContext(ctx)
+
+	// The workload of a role being deleted for a restart is recreated once it is gone
+	if roleRestartPhase(rbg, role.Name) == workloadsv1alpha1.RoleRestartDeleting {
+		logger.V(1).Info("Workload being deleted for the restart")
+		roleStatus := workloadsv1alpha1.RoleStatus{Name: role.Name}
+		if oldStatus, found := rbg.GetRoleStatus(role.Name); found {
+			roleStatus = oldStatus
+		}
+		return roleResult{status: roleStatus, waiting: true}
 	}
 
-	stsRecon := reconciler.NewStatefulSetReconciler(r.scheme, r.client)
-	if err := stsRecon.CleanupOrphanedWorkloads(ctx, rbg); err != nil {
-		errs = append(errs, err)
+	// Check dependencies first
+	var waitingFor []string
+	for _, dep := range role.Dependencies {
+		if waitingRoles.Has(dep) {
+			waitingFor = append(waitingFor, dep)
+		}
+	}
+	if len(waitingFor) == 0 {
+		ready, err := dependencyManager.CheckDependencyReady(ctx, rbg, role)
+		if err != nil {
+			r.recorder.Event(rbg, corev1.EventTypeWarning, FailedCheckRoleDependency, err.Error())
+			return roleResult{err: err}
+		}
+		if !ready {
+			waitingFor = role.Dependencies
+		}
+	}
+	if len(waitingFor) > 0 {
+		logger.V(1).Info("Dependencies not met", "dependencies", waitingFor)
+		roleStatus := workloadsv1alpha1.RoleStatus{Name: role.Name}
+		if oldStatus, found := rbg.GetRoleStatus(role.Name); found {
+			roleStatus = oldStatus
+		}
+		updateStatus := setRoleWaitingCondition(rbg, &roleStatus, waitingFor)
+		updateStatus = setRoleDependencies(rbg, &roleStatus, role) || updateStatus
+		return roleResult{status: roleStatus, updateStatus: updateStatus, waiting: true}
 	}
 
-	lwsRecon := reconciler.NewLeaderWorkerSetReconciler(r.scheme, r.client)
-	if err := lwsRecon.CleanupOrphanedWorkloads(ctx, rbg); err != nil {
-		errs = append(errs, err)
+	reconciler, err := reconciler.NewWorkloadReconciler(role.Workload, r.scheme, r.client)
+	if err != nil {
+		logger.Error(err, "Failed to create workload reconciler")
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
+			"Failed to reconcile role %s: %v", role.Name, err)
+		return roleResult{err: err}
 	}
 
-	if err := r.CleanupOrphanedScalingAdapters(ctx, rbg); err != nil {
-		errs = append(errs, err)
+	if err := reconciler.Reconciler(ctx, rbg, role); err != nil {
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
+			"Failed to reconcile role %s: %v", role.Name, err)
+		return roleResult{err: err}
+	}
+
+	if err := r.ReconcileScalingAdapter(ctx, rbg, role); err != nil {
+		logger.Error(err, "Failed to reconcile scaling adapter")
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedCreateScalingAdapter,
+			"Failed to reconcile scaling adapter for role %s: %v", role.Name, err)
+		return roleResult{err: err}
 	}
 
-	return errors.NewAggregate(errs)
+	roleStatus, updateRoleStatus, err := reconciler.ConstructRoleStatus(ctx, rbg, role)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
+				"Failed to construct role %s status: %v", role.Name, err)
+		}
+		return roleResult{err: err}
+	}
+	if updateRevision, ok := updateRevisions[role.Name]; ok {
+		updateRoleStatus = setRoleRevisions(rbg, &roleStatus, updateRevision.Name) || updateRoleStatus
+	}
+	observed, err := workloadObserved(ctx, reconciler, rbg, role)
+	if err != nil {
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
+			"Failed to construct role %s status: %v", role.Name, err)
+		return roleResult{err: err}
+	}
+	failure, err := workloadFailure(ctx, reconciler, rbg, role)
+	if err != nil {
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
+			"Failed to construct role %s status: %v", role.Name, err)
+		return roleResult{err: err}
+	}
+	wasFailed := roleFailed(rbg, role.Name)
+	if setRoleReadyCondition(rbg, &roleStatus, *role.Replicas, observed, failure) {
+		if failure != "" && !wasFailed {
+			r.recorder.Eventf(rbg, corev1.EventTypeWarning, WorkloadFailed, "Role %s failed: %s", role.Name, failure)
+		}
+		updateRoleStatus = true
+	}
+	updateRoleStatus = setRoleDependencies(rbg, &roleStatus, role) || updateRoleStatus
+	if oldStatus, _ := rbg.GetRoleStatus(role.Name); oldStatus.DeletionStartTime != nil {
+		// the role was added back to the spec while it was being deleted
+		updateRoleStatus = true
+	}
+	return roleResult{status: roleStatus, updateStatus: updateRoleStatus}
 }
 
-func (r *RoleBasedGroupReconciler) updateRBGStatus(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleStatus []workloadsv1alpha1.RoleStatus) error {
-	// update ready condition
-	rbgReady := true
-	for _, role := range roleStatus {
-		if role.ReadyReplicas != role.Replicas {
-			rbgReady = false
-			break
+// deleteRoles deletes the roles removed from the spec of rbg in reverse dependency order, then the workloads
+// and scaling adapters left behind. It returns whether the removed roles are deleted.
+func (r *RoleBasedGroupReconciler) deleteRoles(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (bool, error) {
+	deletions, err := removedRoleDeletions(ctx, rbg)
+	if err != nil {
+		return false, err
+	}
+	if deleted, err := r.deleteRolesInOrder(ctx, rbg, deletions); err != nil || !deleted {
+		return false, err
+	}
+	if len(deletions) > 0 {
+		// forget the removed roles once they are deleted
+		rbg.Status.RoleStatuses = rbg.SpecRoleStatuses()
+		if err := r.patchRBGStatus(ctx, rbg); err != nil {
+			return false, err
 		}
 	}
 
-	var readyCondition metav1.Condition
-	if rbgReady {
-		readyCondition = metav1.Condition{
-			Type:               string(workloadsv1alpha1.RoleBasedGroupReady),
-			Status:             metav1.ConditionTrue,
-			LastTransitionTime: metav1.Now(),
-			Reason:             "AllRolesReady",
-			Message:            "All roles are ready",
+	errs := make([]error, 0)
+	for _, plugin := range reconciler.WorkloadPlugins() {
+		if _, watched := watchedWorkload.Load(plugin.CrdName); plugin.CrdName != "" && !watched {
+			// the CRD is not installed, no workload of this kind was created
+			continue
 		}
-	} else {
-		readyCondition = metav1.Condition{
-			Type:               string(workloadsv1alpha1.RoleBasedGroupReady),
-			Status:             metav1.ConditionFalse,
-			LastTransitionTime: metav1.Now(),
-			Reason:             "RoleNotReady",
-			Message:            "Not all role ready",
+		if err := plugin.NewReconciler(r.scheme, r.client).CleanupOrphanedWorkloads(ctx, rbg); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
-	setCondition(rbg, readyCondition)
+	if err := r.CleanupOrphanedScalingAdapters(ctx, rbg); err != nil {
+		errs = append(errs, err)
+	}
+
+	return true, errors.NewAggregate(errs)
+}
 
+func (r *RoleBasedGroupReconciler) updateRBGStatus(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleStatus []workloadsv1alpha1.RoleStatus) error {
 	// update role status
 	for i := range roleStatus {
 		found := false
@@ -259,7 +465,7 @@ func (r *RoleBasedGroupReconciler) updateRBGStatus(ctx context.Context, rbg *wor
 			// if found, update
 			if roleStatus[i].Name == oldStatus.Name {
 				found = true
-				if roleStatus[i].Replicas != oldStatus.Replicas || roleStatus[i].ReadyReplicas != oldStatus.ReadyReplicas {
+				if !apiequality.Semantic.DeepEqual(roleStatus[i], oldStatus) {
 					rbg.Status.RoleStatuses[j] = roleStatus[i]
 				}
 				break
@@ -270,12 +476,229 @@ func (r *RoleBasedGroupReconciler) updateRBGStatus(ctx context.Context, rbg *wor
 		}
 	}
 
-	// update rbg status
+	// update conditions from the status of the roles in spec, some of them may not have been reconciled in this round
+	rbg.Status.ObservedGeneration = rbg.Generation
+	setCondition(rbg, readyCondition(rbg))
+	if cond := preemptionRecoveredCondition(rbg); cond != nil {
+		setCondition(rbg, *cond)
+	}
+	specRoleStatuses := rbg.SpecRoleStatuses()
+	setCondition(rbg, rollingUpdateCondition(specRoleStatuses))
+	setCondition(rbg, progressingCondition(specRoleStatuses))
+
+	return r.patchRBGStatus(ctx, rbg)
+}
+
+// patchRBGStatus applies the status of rbg.
+func (r *RoleBasedGroupReconciler) patchRBGStatus(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
 	rbgApplyConfig := utils.RoleBasedGroup(rbg.Name, rbg.Namespace, rbg.Kind, rbg.APIVersion).
-		WithStatus(utils.RbgStatus().WithRoleStatuses(rbg.Status.RoleStatuses).WithConditions(rbg.Status.Conditions))
+		WithStatus(utils.RbgStatus().WithRoleStatuses(rbg.Status.RoleStatuses).WithConditions(rbg.Status.Conditions).
+			WithRollout(rbg.Status.Rollout).WithRestarts(rbg.Status.Restarts).WithObservedGeneration(rbg.Status.ObservedGeneration))
 
 	return utils.PatchObjectApplyConfiguration(ctx, r.client, rbgApplyConfig, utils.PatchStatus)
+}
+
+// readyCondition is true once every role of rbg is ready at the current generation.
+func readyCondition(rbg *workloadsv1alpha1.RoleBasedGroup) metav1.Condition {
+	var notReadyRoles, failedRoles []string
+	for _, role := range rbg.Spec.Roles {
+		status, found := rbg.GetRoleStatus(role.Name)
+		if !found || status.ObservedGeneration != rbg.Generation ||
+			!apimeta.IsStatusConditionTrue(status.Conditions, string(workloadsv1alpha1.RoleBasedGroupReady)) {
+			notReadyRoles = append(notReadyRoles, role.Name)
+		}
+		if roleFailed(rbg, role.Name) {
+			failedRoles = append(failedRoles, role.Name)
+		}
+	}
 
+	if len(failedRoles) > 0 {
+		return metav1.Condition{
+			Type:    string(workloadsv1alpha1.RoleBasedGroupReady),
+			Status:  metav1.ConditionFalse,
+			Reason:  "RoleFailed",
+			Message: fmt.Sprintf("Roles %v failed", failedRoles),
+		}
+	}
+	if len(notReadyRoles) > 0 {
+		return metav1.Condition{
+			Type:    string(workloadsv1alpha1.RoleBasedGroupReady),
+			Status:  metav1.ConditionFalse,
+			Reason:  "RoleNotReady",
+			Message: fmt.Sprintf("Roles %v are not ready", notReadyRoles),
+		}
+	}
+	return metav1.Condition{
+		Type:    string(workloadsv1alpha1.RoleBasedGroupReady),
+		Status:  metav1.ConditionTrue,
+		Reason:  "AllRolesReady",
+		Message: "All roles are ready",
+	}
+}
+
+// roleFailed reports whether the Ready condition of the role of rbg named roleName reports its workload failed.
+func roleFailed(rbg *workloadsv1alpha1.RoleBasedGroup, roleName string) bool {
+	status, _ := rbg.GetRoleStatus(roleName)
+	cond := apimeta.FindStatusCondition(status.Conditions, string(workloadsv1alpha1.RoleBasedGroupReady))
+	return cond != nil && cond.Reason == workloadsv1alpha1.RoleWorkloadFailedReason
+}
+
+// setRoleReadyCondition records in roleStatus that the role has been reconciled at the current generation
+// of rbg, and sets its Ready condition. A non-empty failure marks the workload as failed for good.
+// It returns whether the role status changed.
+func setRoleReadyCondition(rbg *workloadsv1alpha1.RoleBasedGroup, roleStatus *workloadsv1alpha1.RoleStatus,
+	replicas int32, observed bool, failure string) bool {
+	oldStatus, _ := rbg.GetRoleStatus(roleStatus.Name)
+	roleStatus.ObservedGeneration = rbg.Generation
+	// never modify the conditions of rbg in place
+	roleStatus.Conditions = slices.Clone(oldStatus.Conditions)
+
+	condition := metav1.Condition{
+		Type:               string(workloadsv1alpha1.RoleBasedGroupReady),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: rbg.Generation,
+		Reason:             "RoleReady",
+		Message:            "All replicas are ready",
+	}
+	switch {
+	case failure != "":
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = workloadsv1alpha1.RoleWorkloadFailedReason
+		condition.Message = failure
+	case !observed:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "WorkloadNotObserved"
+		condition.Message = "The workload has not observed the latest role spec"
+	case roleStatus.Replicas != replicas || roleStatus.ReadyReplicas < replicas:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReplicasNotReady"
+		condition.Message = fmt.Sprintf("%d of %d replicas are ready", roleStatus.ReadyReplicas, replicas)
+	}
+
+	conditionChanged := apimeta.SetStatusCondition(&roleStatus.Conditions, condition)
+	return conditionChanged || roleStatus.ObservedGeneration != oldStatus.ObservedGeneration
+}
+
+// setRoleWaitingCondition records in roleStatus that the role is waiting for the dependencies in waitingFor
+// at the current generation of rbg. It returns whether the role status changed.
+func setRoleWaitingCondition(rbg *workloadsv1alpha1.RoleBasedGroup, roleStatus *workloadsv1alpha1.RoleStatus, waitingFor []string) bool {
+	oldStatus, _ := rbg.GetRoleStatus(roleStatus.Name)
+	roleStatus.ObservedGeneration = rbg.Generation
+	// never modify the conditions of rbg in place
+	roleStatus.Conditions = slices.Clone(oldStatus.Conditions)
+
+	conditionChanged := apimeta.SetStatusCondition(&roleStatus.Conditions, metav1.Condition{
+		Type:               string(workloadsv1alpha1.RoleBasedGroupReady),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: rbg.Generation,
+		Reason:             "WaitingForDependencies",
+		Message:            fmt.Sprintf("Waiting for dependencies %v to be ready", waitingFor),
+	})
+	return conditionChanged || roleStatus.ObservedGeneration != oldStatus.ObservedGeneration
+}
+
+// setRoleDependencies records the dependencies of role in roleStatus, to delete the role in order once it is
+// removed from the spec. It returns whether the role status changed.
+func setRoleDependencies(rbg *workloadsv1alpha1.RoleBasedGroup, roleStatus *workloadsv1alpha1.RoleStatus, role *workloadsv1alpha1.RoleSpec) bool {
+	oldStatus, _ := rbg.GetRoleStatus(roleStatus.Name)
+	roleStatus.Dependencies = role.Dependencies
+	return !slices.Equal(roleStatus.Dependencies, oldStatus.Dependencies)
+}
+
+// workloadObserved reports whether the workload controller has observed the latest spec of the workload of role.
+func workloadObserved(ctx context.Context, workloadReconciler reconciler.WorkloadReconciler,
+	rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (bool, error) {
+	stateReader, ok := workloadReconciler.(reconciler.RolloutStateReader)
+	if !ok {
+		return true, nil
+	}
+	state, err := stateReader.RolloutState(ctx, rbg, role)
+	if err != nil || state == nil {
+		return false, err
+	}
+	return state.Observed, nil
+}
+
+// workloadFailure returns why the workload of role failed for good, or an empty string if it did not.
+func workloadFailure(ctx context.Context, workloadReconciler reconciler.WorkloadReconciler,
+	rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (string, error) {
+	failureReader, ok := workloadReconciler.(reconciler.WorkloadFailureReader)
+	if !ok {
+		return "", nil
+	}
+	return failureReader.WorkloadFailure(ctx, rbg, role)
+}
+
+// rollingUpdateCondition is true while a role has not been fully rolled out to its update revision.
+func rollingUpdateCondition(roleStatuses []workloadsv1alpha1.RoleStatus) metav1.Condition {
+	var updatingRoles []string
+	for _, role := range roleStatuses {
+		if role.CurrentRevision != role.UpdateRevision {
+			updatingRoles = append(updatingRoles, role.Name)
+		}
+	}
+
+	if len(updatingRoles) > 0 {
+		return metav1.Condition{
+			Type:               string(workloadsv1alpha1.RoleBasedGroupRollingUpdateInProgress),
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "RolesUpdating",
+			Message:            fmt.Sprintf("Rolling update in progress for roles %v", updatingRoles),
+		}
+	}
+	return metav1.Condition{
+		Type:               string(workloadsv1alpha1.RoleBasedGroupRollingUpdateInProgress),
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "RolesUpdated",
+		Message:            "All roles are rolled out",
+	}
+}
+
+// progressingCondition is true while a role has replicas not updated or not ready.
+func progressingCondition(roleStatuses []workloadsv1alpha1.RoleStatus) metav1.Condition {
+	var progressingRoles []string
+	for _, role := range roleStatuses {
+		if role.UpdatedReadyReplicas < role.Replicas || role.ReadyReplicas < role.Replicas {
+			progressingRoles = append(progressingRoles, role.Name)
+		}
+	}
+
+	if len(progressingRoles) > 0 {
+		return metav1.Condition{
+			Type:               string(workloadsv1alpha1.RoleBasedGroupProgressing),
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "RolesProgressing",
+			Message:            fmt.Sprintf("Replicas of roles %v are not updated and ready", progressingRoles),
+		}
+	}
+	return metav1.Condition{
+		Type:               string(workloadsv1alpha1.RoleBasedGroupProgressing),
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "AllRolesUpdatedAndReady",
+		Message:            "All replicas are updated and ready",
+	}
+}
+
+// setRoleRevisions records the update revision of role, and promotes it to the current revision
+// once all the replicas of the role are updated and ready. It returns whether the role status changed.
+func setRoleRevisions(rbg *workloadsv1alpha1.RoleBasedGroup, roleStatus *workloadsv1alpha1.RoleStatus, updateRevision string) bool {
+	oldStatus, _ := rbg.GetRoleStatus(roleStatus.Name)
+	roleStatus.CurrentRevision = oldStatus.CurrentRevision
+	roleStatus.UpdateRevision = updateRevision
+
+	switch {
+	case roleStatus.CurrentRevision == "":
+		roleStatus.CurrentRevision = updateRevision
+	case oldStatus.UpdateRevision == updateRevision && roleStatus.UpdatedReadyReplicas == roleStatus.Replicas &&
+		roleStatus.ReadyReplicas == roleStatus.Replicas:
+		// the workload has been observed at least once since the update revision was recorded
+		roleStatus.CurrentRevision = updateRevision
+	}
+	return roleStatus.CurrentRevision != oldStatus.CurrentRevision || roleStatus.UpdateRevision != oldStatus.UpdateRevision
 }
 
 func (r *RoleBasedGroupReconciler) ReconcileScalingAdapter(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleSpec *workloadsv1alpha1.RoleSpec) error {
@@ -374,20 +797,27 @@ func (r *RoleBasedGroupReconciler) SetupWithManager(mgr ctrl.Manager, options co
 	runtimeController = ctrl.NewControllerManagedBy(mgr).
 		WithOptions(options).
 		For(&workloadsv1alpha1.RoleBasedGroup{}, builder.WithPredicates(RBGPredicate())).
-		Owns(&appsv1.StatefulSet{}, builder.WithPredicates(WorkloadPredicate())).
-		Owns(&appsv1.Deployment{}, builder.WithPredicates(WorkloadPredicate())).
 		Owns(&corev1.Service{}).
 		Named("workloads-rolebasedgroup")
 
-	err := utils.CheckCrdExists(r.apiReader, utils.LwsCrdName)
-	if err == nil {
-		watchedWorkload.LoadOrStore(utils.LwsCrdName, struct{}{})
-		runtimeController.Owns(&lwsv1.LeaderWorkerSet{}, builder.WithPredicates(WorkloadPredicate()))
+	for _, plugin := range reconciler.WorkloadPlugins() {
+		if plugin.CrdName != "" {
+			if err := utils.CheckCrdExists(r.apiReader, plugin.CrdName); err != nil {
+				continue
+			}
+			watchedWorkload.LoadOrStore(plugin.CrdName, struct{}{})
+		}
+		runtimeController.Owns(plugin.NewObject(), builder.WithPredicates(WorkloadPredicate()))
 	}
-	err = utils.CheckCrdExists(r.apiReader, utils.PodGroupCrdName)
-	if err == nil {
-		watchedWorkload.LoadOrStore(utils.PodGroupCrdName, struct{}{})
-		runtimeController.Owns(&schev1alpha1.PodGroup{})
+	for _, backend := range scheduler.NewPodGroupScheduler(r.client).Backends() {
+		if err := utils.CheckCrdExists(r.apiReader, backend.CrdName()); err == nil {
+			watchedWorkload.LoadOrStore(backend.CrdName(), struct{}{})
+			runtimeController.Owns(backend.PodGroupObject())
+		}
+	}
+	if err := utils.CheckCrdExists(r.apiReader, kueue.CrdName); err == nil {
+		watchedWorkload.LoadOrStore(kueue.CrdName, struct{}{})
+		runtimeController.Owns(kueue.NewWorkload())
 	}
 
 	return runtimeController.Complete(r)
@@ -426,6 +856,18 @@ func RBGPredicate() predicate.Funcs {
 					ctrl.Log.Info("enqueue: rbg update event", "rbg", klog.KObj(e.ObjectOld))
 					return true
 				}
+				if oldRbg.GetQueueName() != newRbg.GetQueueName() {
+					ctrl.Log.Info("enqueue: rbg queue name update event", "rbg", klog.KObj(e.ObjectOld))
+					return true
+				}
+				if !preemptionEvicting(oldRbg) && preemptionEvicting(newRbg) {
+					ctrl.Log.Info("enqueue: rbg preemption event", "rbg", klog.KObj(e.ObjectOld))
+					return true
+				}
+				if !restartRequested(oldRbg) && restartRequested(newRbg) {
+					ctrl.Log.Info("enqueue: rbg restart event", "rbg", klog.KObj(e.ObjectOld))
+					return true
+				}
 			}
 			return false
 		},
@@ -506,19 +948,15 @@ func getRbgGVK() schema.GroupVersionKind {
 	return schema.FromAPIVersionAndKind(workloadsv1alpha1.GroupVersion.String(), "RoleBasedGroup")
 }
 
-func getLwsGVK() schema.GroupVersionKind {
-	return schema.FromAPIVersionAndKind(lwsv1.GroupVersion.String(), "LeaderWorkerSet")
-}
-
-func dynamicWatchCustomCRD(ctx context.Context, kind string) {
+// dynamicWatchCustomCRD watches the workload of a role defined by a CRD installed after the controller started.
+func dynamicWatchCustomCRD(ctx context.Context, workload workloadsv1alpha1.WorkloadSpec) {
 	logger := log.FromContext(ctx)
-	switch kind {
-	case getLwsGVK().Kind:
-		_, lwsExist := watchedWorkload.Load(utils.LwsCrdName)
-		if !lwsExist {
-			watchedWorkload.LoadOrStore(utils.LwsCrdName, struct{}{})
-			runtimeController.Owns(&lwsv1.LeaderWorkerSet{}, builder.WithPredicates(WorkloadPredicate()))
-			logger.Info("rbgs controller watch LeaderWorkerSet CRD")
-		}
+	plugin, ok := reconciler.LookupWorkload(workload)
+	if !ok || plugin.CrdName == "" {
+		return
+	}
+	if _, loaded := watchedWorkload.LoadOrStore(plugin.CrdName, struct{}{}); !loaded {
+		runtimeController.Owns(plugin.NewObject(), builder.WithPredicates(WorkloadPredicate()))
+		logger.Info("rbgs controller watch workload CRD", "crd", plugin.CrdName)
 	}
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package workloads
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -27,10 +28,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/dependency"
 	"sigs.k8s.io/rbgs/pkg/utils"
+	"sigs.k8s.io/rbgs/test/wrappers"
 )
 
 func TestRoleBasedGroupReconciler_CheckCrdExists(t *testing.T) {
@@ -350,3 +356,182 @@ func Test_hasValidOwnerRef(t *testing.T) {
 		})
 	}
 }
+
+func Test_rolloutConditions(t *testing.T) {
+	tests := []struct {
+		name            string
+		roleStatuses    []workloadsv1alpha1.RoleStatus
+		wantRolling     metav1.ConditionStatus
+		wantProgressing metav1.ConditionStatus
+	}{
+		{
+			name: "all roles updated and ready",
+			roleStatuses: []workloadsv1alpha1.RoleStatus{
+				{Name: "prefill", Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 2, UpdatedReadyReplicas: 2,
+					CurrentRevision: "r1", UpdateRevision: "r1"},
+			},
+			wantRolling:     metav1.ConditionFalse,
+			wantProgressing: metav1.ConditionFalse,
+		},
+		{
+			name: "role rolling out",
+			roleStatuses: []workloadsv1alpha1.RoleStatus{
+				{Name: "prefill", Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 2, UpdatedReadyReplicas: 2,
+					CurrentRevision: "r1", UpdateRevision: "r1"},
+				{Name: "decode", Replicas: 4, ReadyReplicas: 4, UpdatedReplicas: 2, UpdatedReadyReplicas: 2,
+					CurrentRevision: "r1", UpdateRevision: "r2"},
+			},
+			wantRolling:     metav1.ConditionTrue,
+			wantProgressing: metav1.ConditionTrue,
+		},
+		{
+			name: "updated replicas not ready",
+			roleStatuses: []workloadsv1alpha1.RoleStatus{
+				{Name: "decode", Replicas: 4, ReadyReplicas: 3, UpdatedReplicas: 4, UpdatedReadyReplicas: 3,
+					CurrentRevision: "r2", UpdateRevision: "r2"},
+			},
+			wantRolling:     metav1.ConditionFalse,
+			wantProgressing: metav1.ConditionTrue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rollingUpdateCondition(tt.roleStatuses); got.Status != tt.wantRolling {
+				t.Errorf("rollingUpdateCondition() = %v, want %v", got.Status, tt.wantRolling)
+			}
+			if got := progressingCondition(tt.roleStatuses); got.Status != tt.wantProgressing {
+				t.Errorf("progressingCondition() = %v, want %v", got.Status, tt.wantProgressing)
+			}
+		})
+	}
+}
+
+func Test_setRoleReadyCondition(t *testing.T) {
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec: workloadsv1alpha1.RoleBasedGroupSpec{
+			Roles: []workloadsv1alpha1.RoleSpec{{Name: "prefill"}, {Name: "decode"}},
+		},
+	}
+	reconcileRole := func(name string, ready int32, observed bool) bool {
+		roleStatus := workloadsv1alpha1.RoleStatus{Name: name, Replicas: 2, ReadyReplicas: ready}
+		changed := setRoleReadyCondition(rbg, &roleStatus, 2, observed, "")
+		for i := range rbg.Status.RoleStatuses {
+			if rbg.Status.RoleStatuses[i].Name == name {
+				rbg.Status.RoleStatuses[i] = roleStatus
+				return changed
+			}
+		}
+		rbg.Status.RoleStatuses = append(rbg.Status.RoleStatuses, roleStatus)
+		return changed
+	}
+
+	if !reconcileRole("prefill", 2, true) {
+		t.Fatalf("setRoleReadyCondition() did not record the first reconcile")
+	}
+	if cond := readyCondition(rbg); cond.Status != metav1.ConditionFalse {
+		t.Fatalf("readyCondition() = %v with a role never reconciled", cond.Status)
+	}
+	reconcileRole("decode", 2, true)
+	if cond := readyCondition(rbg); cond.Status != metav1.ConditionTrue {
+		t.Fatalf("readyCondition() = %v, want True", cond.Status)
+	}
+	status, _ := rbg.GetRoleStatus("decode")
+	transitionTime := status.Conditions[0].LastTransitionTime
+	if reconcileRole("decode", 2, true) {
+		t.Errorf("setRoleReadyCondition() changed status without any change")
+	}
+	if status, _ = rbg.GetRoleStatus("decode"); status.Conditions[0].LastTransitionTime != transitionTime {
+		t.Errorf("role condition lastTransitionTime changed without any transition")
+	}
+
+	// a new generation is not ready until every role is reconciled at it
+	rbg.Generation = 2
+	if cond := readyCondition(rbg); cond.Status != metav1.ConditionFalse {
+		t.Fatalf("readyCondition() = %v against a stale spec", cond.Status)
+	}
+	reconcileRole("prefill", 2, true)
+	reconcileRole("decode", 2, false)
+	if cond := readyCondition(rbg); cond.Status != metav1.ConditionFalse {
+		t.Fatalf("readyCondition() = %v with a workload not observed", cond.Status)
+	}
+	reconcileRole("decode", 2, true)
+	if cond := readyCondition(rbg); cond.Status != metav1.ConditionTrue {
+		t.Fatalf("readyCondition() = %v, want True", cond.Status)
+	}
+	status, _ = rbg.GetRoleStatus("decode")
+	if status.Conditions[0].ObservedGeneration != 2 {
+		t.Errorf("role condition observedGeneration = %d, want 2", status.Conditions[0].ObservedGeneration)
+	}
+
+	// a failed workload is reported even if its replicas were ready before
+	if roleFailed(rbg, "decode") {
+		t.Fatalf("roleFailed() = true before the failure")
+	}
+	if !setRoleReadyCondition(rbg, &status, 2, true, "job failed: BackoffLimitExceeded") {
+		t.Fatalf("setRoleReadyCondition() did not record the failure")
+	}
+	rbg.Status.RoleStatuses[1] = status
+	if cond := readyCondition(rbg); cond.Status != metav1.ConditionFalse || cond.Reason != "RoleFailed" {
+		t.Errorf("readyCondition() = %v/%s, want False/RoleFailed", cond.Status, cond.Reason)
+	}
+	if !roleFailed(rbg, "decode") || roleFailed(rbg, "prefill") {
+		t.Errorf("roleFailed() does not report the failed role only")
+	}
+}
+
+func TestRoleBasedGroupReconciler_reconcileRoleWaiting(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("prefill").Obj(),
+		wrappers.BuildBasicRole("decode").WithDependencies([]string{"prefill"}).Obj(),
+		wrappers.BuildBasicRole("router").WithDependencies([]string{"decode", "prefill"}).Obj(),
+	}).Obj()
+	rbg.Generation = 1
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &RoleBasedGroupReconciler{client: fakeClient, scheme: scheme, recorder: record.NewFakeRecorder(10)}
+	dependencyManager := dependency.NewDefaultDependencyManager(scheme, fakeClient)
+
+	tests := []struct {
+		name         string
+		role         *workloadsv1alpha1.RoleSpec
+		waitingRoles sets.Set[string]
+		wantMessage  string
+	}{
+		{
+			name:         "dependency workload not created",
+			role:         &rbg.Spec.Roles[1],
+			waitingRoles: sets.New[string](),
+			wantMessage:  "Waiting for dependencies [prefill] to be ready",
+		},
+		{
+			name:         "dependency waiting",
+			role:         &rbg.Spec.Roles[2],
+			waitingRoles: sets.New("decode"),
+			wantMessage:  "Waiting for dependencies [decode] to be ready",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := r.reconcileRole(context.TODO(), rbg, tt.role, dependencyManager, tt.waitingRoles, nil)
+			if result.err != nil {
+				t.Fatalf("reconcileRole() error = %v", result.err)
+			}
+			if !result.waiting || !result.updateStatus {
+				t.Fatalf("reconcileRole() waiting = %v, updateStatus = %v, want both", result.waiting, result.updateStatus)
+			}
+			cond := result.status.Conditions[0]
+			if cond.Reason != "WaitingForDependencies" || cond.Message != tt.wantMessage {
+				t.Errorf("reconcileRole() condition = %s: %s, want WaitingForDependencies: %s", cond.Reason, cond.Message, tt.wantMessage)
+			}
+			if result.status.ObservedGeneration != rbg.Generation {
+				t.Errorf("reconcileRole() observedGeneration = %d, want %d", result.status.ObservedGeneration, rbg.Generation)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package workloads
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/dependency"
+	"sigs.k8s.io/rbgs/pkg/reconciler"
+)
+
+const (
+	// deletionRequeueInterval is the interval the termination of the pods of a deleted role is checked at.
+	deletionRequeueInterval = 2 * time.Second
+	// defaultRoleDeletionTimeout is how long the pods of a deleted role are waited for by default,
+	// from the deletion of its workload.
+	defaultRoleDeletionTimeout = 300 * time.Second
+)
+
+// roleDeletion is a role to delete. The workload of a role removed from the spec is unknown.
+type roleDeletion struct {
+	name     string
+	workload *workloadsv1alpha1.WorkloadSpec
+	timeout  time.Duration
+}
+
+// addFinalizer makes the deletion of rbg wait for its roles to be deleted in order.
+func (r *RoleBasedGroupReconciler) addFinalizer(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	if controllerutil.ContainsFinalizer(rbg, workloadsv1alpha1.OrderedDeletionFinalizer) {
+		return nil
+	}
+	patch := client.MergeFromWithOptions(rbg.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.AddFinalizer(rbg, workloadsv1alpha1.OrderedDeletionFinalizer)
+	return r.client.Patch(ctx, rbg, patch)
+}
+
+// finalize deletes the roles of rbg in reverse dependency order, and releases rbg once they are all gone.
+func (r *RoleBasedGroupReconciler) finalize(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(rbg, workloadsv1alpha1.OrderedDeletionFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	deleted, err := r.deleteRolesInOrder(ctx, rbg, specRoleDeletions(ctx, rbg))
+	if err != nil {
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedDeleteRole,
+			"Failed to delete roles for %s: %v", rbg.Name, err)
+		return ctrl.Result{}, err
+	}
+	if !deleted {
+		return ctrl.Result{RequeueAfter: deletionRequeueInterval}, nil
+	}
+
+	patch := client.MergeFromWithOptions(rbg.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.RemoveFinalizer(rbg, workloadsv1alpha1.OrderedDeletionFinalizer)
+	return ctrl.Result{}, client.IgnoreNotFound(r.client.Patch(ctx, rbg, patch))
+}
+
+// specRoleDeletions returns the roles of rbg in reverse dependency order.
+func specRoleDeletions(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) []roleDeletion {
+	sortedRoles, err := dependency.NewDefaultDependencyManager(nil, nil).SortRoles(ctx, rbg)
+	if err != nil {
+		// the dependencies are invalid, nothing was created in dependency order
+		log.FromContext(ctx).Error(err, "Failed to sort roles, deleting them in spec order")
+		sortedRoles = nil
+		for i := range rbg.Spec.Roles {
+			sortedRoles = append(sortedRoles, &rbg.Spec.Roles[i])
+		}
+	}
+
+	deletions := make([]roleDeletion, 0, len(sortedRoles))
+	for _, role := range slices.Backward(sortedRoles) {
+		timeout := defaultRoleDeletionTimeout
+		if role.DeletionTimeoutSeconds != nil {
+			timeout = time.Duration(*role.DeletionTimeoutSeconds) * time.Second
+		}
+		deletions = append(deletions, roleDeletion{name: role.Name, workload: &role.Workload, timeout: timeout})
+	}
+	return deletions
+}
+
+// removedRoleDeletions returns the roles removed from the spec of rbg in reverse dependency order,
+// following the dependencies recorded in their status.
+func removedRoleDeletions(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) ([]roleDeletion, error) {
+	dependencies := map[string][]string{}
+	for _, status := range rbg.Status.RoleStatuses {
+		if _, err := rbg.GetRole(status.Name); err != nil {
+			dependencies[status.Name] = status.Dependencies
+		}
+	}
+	if len(dependencies) == 0 {
+		return nil, nil
+	}
+
+	sortedRoles, err := dependency.SortRoleNames(ctx, dependencies)
+	if err != nil {
+		return nil, err
+	}
+	deletions := make([]roleDeletion, 0, len(sortedRoles))
+	for _, name := range slices.Backward(sortedRoles) {
+		deletions = append(deletions, roleDeletion{name: name, timeout: defaultRoleDeletionTimeout})
+	}
+	return deletions, nil
+}
+
+// deleteRolesInOrder deletes the workloads of the roles one after the other, moving to the next role
+// once the pods of the role are gone or their deletion timed out. It returns whether all the roles are deleted.
+func (r *RoleBasedGroupReconciler) deleteRolesInOrder(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	deletions []roleDeletion) (bool, error) {
+	logger := log.FromContext(ctx)
+	for _, deletion := range deletions {
+		if err := r.deleteRoleWorkload(ctx, rbg, deletion); err != nil {
+			return false, err
+		}
+
+		podList := &corev1.PodList{}
+		if err := r.client.List(ctx, podList, client.InNamespace(rbg.Namespace), client.MatchingLabels{
+			workloadsv1alpha1.SetNameLabelKey: rbg.Name,
+			workloadsv1alpha1.SetRoleLabelKey: deletion.name,
+		}); err != nil {
+			return false, err
+		}
+		if len(podList.Items) == 0 {
+			continue
+		}
+		now := time.Now()
+		started, err := r.roleDeletionStarted(ctx, rbg, deletion.name, now)
+		if err != nil {
+			return false, err
+		}
+		if now.Before(started.Add(deletion.timeout)) {
+			logger.Info("Waiting for the pods of the deleted role to terminate", "role", deletion.name)
+			return false, nil
+		}
+		logger.Info("Timed out waiting for the pods of the deleted role to terminate", "role", deletion.name,
+			"pods", len(podList.Items))
+	}
+	return true, nil
+}
+
+// roleDeletionStarted returns when the deletion of the role of rbg named roleName started,
+// and records now in the status of rbg if it is the first time the role is waited for.
+func (r *RoleBasedGroupReconciler) roleDeletionStarted(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	roleName string, now time.Time) (time.Time, error) {
+	startTime := &metav1.Time{Time: now}
+	found := false
+	for i := range rbg.Status.RoleStatuses {
+		status := &rbg.Status.RoleStatuses[i]
+		if status.Name != roleName {
+			continue
+		}
+		if status.DeletionStartTime != nil {
+			return status.DeletionStartTime.Time, nil
+		}
+		status.DeletionStartTime, found = startTime, true
+		break
+	}
+	if !found {
+		// the role was never reconciled
+		rbg.Status.RoleStatuses = append(rbg.Status.RoleStatuses,
+			workloadsv1alpha1.RoleStatus{Name: roleName, DeletionStartTime: startTime})
+	}
+	return now, r.patchRBGStatus(ctx, rbg)
+}
+
+// deleteRoleWorkload deletes the workload of the role, looking it up in every workload watched if it is unknown.
+func (r *RoleBasedGroupReconciler) deleteRoleWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, deletion roleDeletion) error {
+	plugins := reconciler.WorkloadPlugins()
+	if deletion.workload != nil {
+		plugin, ok := reconciler.LookupWorkload(*deletion.workload)
+		if !ok {
+			return nil
+		}
+		plugins = []reconciler.WorkloadPlugin{plugin}
+	}
+
+	for _, plugin := range plugins {
+		if _, watched := watchedWorkload.Load(plugin.CrdName); plugin.CrdName != "" && !watched {
+			// the CRD is not installed, no workload of this kind was created
+			continue
+		}
+		if err := reconciler.DeleteRoleWorkload(ctx, r.scheme, r.client, plugin, rbg, deletion.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package workloads
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+func Test_removedRoleDeletions(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("decode").Obj(),
+	}).Obj()
+	rbg.Status.RoleStatuses = []workloadsv1alpha1.RoleStatus{
+		{Name: "prefill"},
+		{Name: "router", Dependencies: []string{"prefill", "decode"}},
+		{Name: "decode"},
+		{Name: "monitor", Dependencies: []string{"router"}},
+	}
+
+	deletions, err := removedRoleDeletions(context.TODO(), rbg)
+	if err != nil {
+		t.Fatalf("removedRoleDeletions() error = %v", err)
+	}
+	var got []string
+	for _, deletion := range deletions {
+		got = append(got, deletion.name)
+	}
+	if want := []string{"monitor", "router", "prefill"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("removedRoleDeletions() = %v, want %v", got, want)
+	}
+}
+
+func TestRoleBasedGroupReconciler_finalize(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("router").WithDependencies([]string{"backend"}).Obj(),
+		wrappers.BuildBasicRole("backend").Obj(),
+	}).Obj()
+	rbg.UID = "rbg-uid"
+	rbg.Finalizers = []string{workloadsv1alpha1.OrderedDeletionFinalizer}
+	rbg.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	ownerRef := *metav1.NewControllerRef(rbg, workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroup"))
+	statefulSet := func(role string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "test-rbg-" + role, Namespace: "default", OwnerReferences: []metav1.OwnerReference{ownerRef},
+		}}
+	}
+	routerPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "test-rbg-router-0", Namespace: "default",
+		Labels: map[string]string{
+			workloadsv1alpha1.SetNameLabelKey: rbg.Name,
+			workloadsv1alpha1.SetRoleLabelKey: "router",
+		},
+	}}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(rbg, statefulSet("router"), statefulSet("backend"), routerPod).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(context.Context, client.Client, string, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+				return nil
+			},
+		}).Build()
+	r := &RoleBasedGroupReconciler{client: fakeClient, scheme: scheme, recorder: record.NewFakeRecorder(10)}
+	exists := func(obj client.Object, name string) bool {
+		err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: name}, obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return err == nil
+	}
+
+	// the router is deleted first, the backend waits for its pods to terminate
+	result, err := r.finalize(context.TODO(), rbg)
+	if err != nil {
+		t.Fatalf("finalize() error = %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Errorf("finalize() did not requeue while pods of the router are left")
+	}
+	if exists(&appsv1.StatefulSet{}, "test-rbg-router") {
+		t.Errorf("finalize() did not delete the router")
+	}
+	if !exists(&appsv1.StatefulSet{}, "test-rbg-backend") {
+		t.Errorf("finalize() deleted the backend before the pods of the router terminated")
+	}
+	status, _ := rbg.GetRoleStatus("router")
+	if status.DeletionStartTime == nil {
+		t.Fatalf("finalize() did not record when the deletion of the router started")
+	}
+	startTime := status.DeletionStartTime.DeepCopy()
+	if _, err := r.finalize(context.TODO(), rbg); err != nil {
+		t.Fatalf("finalize() error = %v", err)
+	}
+	if status, _ = rbg.GetRoleStatus("router"); !status.DeletionStartTime.Equal(startTime) {
+		t.Errorf("finalize() deletion start time = %v, want %v", status.DeletionStartTime, startTime)
+	}
+
+	// the backend is deleted once the pods of the router are gone
+	if err := fakeClient.Delete(context.TODO(), routerPod); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := r.finalize(context.TODO(), rbg); err != nil {
+		t.Fatalf("finalize() error = %v", err)
+	}
+	if exists(&appsv1.StatefulSet{}, "test-rbg-backend") {
+		t.Errorf("finalize() did not delete the backend")
+	}
+	if exists(&workloadsv1alpha1.RoleBasedGroup{}, rbg.Name) {
+		t.Errorf("finalize() did not release the rbg")
+	}
+}
+
+func TestRoleBasedGroupReconciler_deleteRolesInOrderTimeout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").Obj()
+	// the pod is never marked for deletion, e.g. its workload is orphaned
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "test-rbg-router-0", Namespace: "default",
+		Labels: map[string]string{
+			workloadsv1alpha1.SetNameLabelKey: rbg.Name,
+			workloadsv1alpha1.SetRoleLabelKey: "router",
+		},
+	}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rbg, pod).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(context.Context, client.Client, string, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+				return nil
+			},
+		}).Build()
+	r := &RoleBasedGroupReconciler{client: fakeClient, scheme: scheme, recorder: record.NewFakeRecorder(10)}
+	deletions := []roleDeletion{{name: "router", timeout: time.Minute}}
+
+	tests := []struct {
+		name      string
+		startTime *metav1.Time
+		want      bool
+	}{
+		{name: "deletion starting", want: false},
+		{name: "deletion in progress", startTime: &metav1.Time{Time: time.Now().Add(-10 * time.Second)}, want: false},
+		{name: "deletion timed out", startTime: &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg.Status.RoleStatuses = []workloadsv1alpha1.RoleStatus{{Name: "router", DeletionStartTime: tt.startTime}}
+			got, err := r.deleteRolesInOrder(context.TODO(), rbg, deletions)
+			if err != nil {
+				t.Fatalf("deleteRolesInOrder() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("deleteRolesInOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
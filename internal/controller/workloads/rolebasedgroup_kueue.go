@@ -0,0 +1,130 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/kueue"
+	"sigs.k8s.io/rbgs/pkg/reconciler"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// suspendedReasonResizing is the reason of the Suspended condition of a rbg running while Kueue admits
+// its Workload recreated for a resize.
+const suspendedReasonResizing = "Resizing"
+
+// reconcileAdmission holds the roles of a rbg labeled with a Kueue queue name until Kueue admits the rbg
+// as a whole. The roles of an evicted rbg are stopped together before its quota is released, while the
+// roles of a resized rbg keep running, unchanged, until Kueue admits its new Workload.
+// It returns whether the roles of rbg can run, whether their workloads are still being stopped, and the nodes
+// the pods of the admitted roles are placed on, by role name.
+func (r *RoleBasedGroupReconciler) reconcileAdmission(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (
+	bool, bool, map[string]*reconciler.Placement, error) {
+	logger := log.FromContext(ctx)
+	workloadManager := kueue.NewDefaultWorkloadManager(r.scheme, r.client)
+	_, watched := watchedWorkload.Load(kueue.CrdName)
+	if rbg.GetQueueName() == "" {
+		if watched {
+			return true, false, nil, workloadManager.Delete(ctx, rbg)
+		}
+		return true, false, nil, nil
+	}
+
+	if !watched {
+		if err := utils.CheckCrdExists(r.apiReader, kueue.CrdName); err != nil {
+			return false, false, nil, fmt.Errorf("rbg is labeled with %s but Kueue is not installed: %w",
+				workloadsv1alpha1.QueueNameLabelKey, err)
+		}
+		watchedWorkload.LoadOrStore(kueue.CrdName, struct{}{})
+		runtimeController.Owns(kueue.NewWorkload())
+		logger.Info("rbgs controller watch Kueue Workload CRD", "crd", kueue.CrdName)
+	}
+
+	state, err := workloadManager.Reconcile(ctx, rbg)
+	if err != nil {
+		return false, false, nil, err
+	}
+	// the Workload recreated for a resize is deleted and created again over several reconciles
+	if !state.Admitted && !state.Evicted && resizing(rbg) {
+		state.Resizing = true
+	}
+	if err := r.setSuspendedCondition(ctx, rbg, state); err != nil {
+		return false, false, nil, err
+	}
+	if state.Admitted {
+		return true, false, state.Placements, nil
+	}
+	if state.Resizing {
+		return false, false, nil, nil
+	}
+
+	stopped, err := r.stopRoles(ctx, rbg)
+	if err != nil || !stopped {
+		return false, !stopped, nil, err
+	}
+	if state.Evicted {
+		return false, false, nil, workloadManager.ReleaseQuota(ctx, rbg)
+	}
+	return false, false, nil, nil
+}
+
+// stopRoles deletes the workloads of all the roles of rbg, and returns whether they are all gone.
+func (r *RoleBasedGroupReconciler) stopRoles(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (bool, error) {
+	stopped := true
+	for _, role := range rbg.Spec.Roles {
+		plugin, ok := reconciler.LookupWorkload(role.Workload)
+		if !ok {
+			return false, fmt.Errorf("unsupported workload type: %s", role.Workload.String())
+		}
+		exists, err := reconciler.RoleWorkloadExists(ctx, r.scheme, r.client, plugin, rbg, role.Name)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			continue
+		}
+		stopped = false
+		if err := reconciler.DeleteRoleWorkload(ctx, r.scheme, r.client, plugin, rbg, role.Name); err != nil {
+			return false, err
+		}
+	}
+	return stopped, nil
+}
+
+// resizing reports whether rbg keeps running while Kueue admits its Workload recreated for a resize.
+func resizing(rbg *workloadsv1alpha1.RoleBasedGroup) bool {
+	cond := apimeta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupSuspended))
+	return cond != nil && cond.Reason == suspendedReasonResizing
+}
+
+// setSuspendedCondition reports in the status of rbg whether it waits for Kueue.
+func (r *RoleBasedGroupReconciler) setSuspendedCondition(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, state kueue.State) error {
+	condition := metav1.Condition{
+		Type:    string(workloadsv1alpha1.RoleBasedGroupSuspended),
+		Status:  metav1.ConditionTrue,
+		Reason:  "WaitingForAdmission",
+		Message: state.Message,
+	}
+	switch {
+	case state.Admitted:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Admitted"
+	case state.Resizing:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = suspendedReasonResizing
+	case state.Evicted:
+		condition.Reason = "Evicted"
+	}
+
+	old := apimeta.FindStatusCondition(rbg.Status.Conditions, condition.Type)
+	if old != nil && old.Status == condition.Status && old.Reason == condition.Reason &&
+		old.Message == condition.Message && old.ObservedGeneration == rbg.Generation {
+		return nil
+	}
+	setCondition(rbg, condition)
+	return r.patchRBGStatus(ctx, rbg)
+}
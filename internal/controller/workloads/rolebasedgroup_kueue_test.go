@@ -0,0 +1,126 @@
+package workloads
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/kueue"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+// setKueueConditions sets the conditions Kueue reports on the Workload of rbg.
+func setKueueConditions(t *testing.T, c client.Client, rbg *workloadsv1alpha1.RoleBasedGroup, types ...string) {
+	wl := kueue.NewWorkload()
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: rbg.Namespace, Name: kueue.WorkloadName(rbg)}, wl); err != nil {
+		t.Fatalf("get workload: %v", err)
+	}
+	var values []interface{}
+	for _, conditionType := range types {
+		value, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(&metav1.Condition{
+			Type: conditionType, Status: metav1.ConditionTrue, Reason: conditionType,
+		})
+		values = append(values, value)
+	}
+	_ = unstructured.SetNestedSlice(wl.Object, values, "status", "conditions")
+	if err := c.Status().Update(context.TODO(), wl); err != nil {
+		t.Fatalf("update workload: %v", err)
+	}
+}
+
+func TestRoleBasedGroupReconciler_reconcileAdmissionResize(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+	watchedWorkload.LoadOrStore(kueue.CrdName, struct{}{})
+
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("prefill").Obj(),
+		wrappers.BuildBasicRole("decode").WithReplicas(2).Obj(),
+	}).Obj()
+	rbg.UID = "rbg-uid"
+	rbg.Labels = map[string]string{workloadsv1alpha1.QueueNameLabelKey: "gpu"}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(kueue.NewWorkload()).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(context.Context, client.Client, string, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+				return nil
+			},
+		}).Build()
+	r := &RoleBasedGroupReconciler{client: fakeClient, scheme: scheme, recorder: record.NewFakeRecorder(10)}
+
+	reconcile := func(wantAdmitted bool, wantReason string) {
+		t.Helper()
+		admitted, stopping, _, err := r.reconcileAdmission(context.TODO(), rbg)
+		if err != nil {
+			t.Fatalf("reconcileAdmission() error = %v", err)
+		}
+		if admitted != wantAdmitted || stopping {
+			t.Errorf("reconcileAdmission() = %v, %v, want %v, false", admitted, stopping, wantAdmitted)
+		}
+		cond := apimeta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupSuspended))
+		if cond == nil || cond.Reason != wantReason {
+			t.Errorf("reconcileAdmission() suspended condition = %+v, want reason %s", cond, wantReason)
+		}
+	}
+	workloadsExist := func() bool {
+		t.Helper()
+		for _, name := range []string{"test-rbg-prefill", "test-rbg-decode"} {
+			err := fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: name}, &appsv1.StatefulSet{})
+			if apierrors.IsNotFound(err) {
+				return false
+			}
+			if err != nil {
+				t.Fatalf("get %s: %v", name, err)
+			}
+		}
+		return true
+	}
+
+	// the group is admitted and its roles run
+	reconcile(false, "WaitingForAdmission")
+	setKueueConditions(t, fakeClient, rbg, "QuotaReserved", "Admitted")
+	reconcile(true, "Admitted")
+	for _, name := range []string{"test-rbg-prefill", "test-rbg-decode"} {
+		if err := fakeClient.Create(context.TODO(), &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(rbg, workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroup")),
+			}},
+			Spec: appsv1.StatefulSetSpec{Replicas: ptr.To[int32](1)},
+		}); err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+	}
+
+	// scaling decode recreates the Workload, the roles keep running until it is admitted again
+	rbg.Spec.Roles[1].Replicas = ptr.To[int32](4)
+	reconcile(false, "Resizing")
+	reconcile(false, "Resizing")
+	reconcile(false, "Resizing")
+	if !workloadsExist() {
+		t.Fatalf("reconcileAdmission() stopped the roles of the resized group")
+	}
+	setKueueConditions(t, fakeClient, rbg, "QuotaReserved", "Admitted")
+	reconcile(true, "Admitted")
+
+	// an eviction still stops the roles
+	setKueueConditions(t, fakeClient, rbg, "QuotaReserved", "Admitted", "Evicted")
+	admitted, stopping, _, err := r.reconcileAdmission(context.TODO(), rbg)
+	if err != nil {
+		t.Fatalf("reconcileAdmission() error = %v", err)
+	}
+	if admitted || !stopping || workloadsExist() {
+		t.Errorf("reconcileAdmission() = %v, %v, want the roles of the evicted group stopped", admitted, stopping)
+	}
+}
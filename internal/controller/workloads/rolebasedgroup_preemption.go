@@ -0,0 +1,70 @@
+package workloads
+
+import (
+	"context"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+const (
+	// preemptionEvictingReason is the reason of the Preempted condition while the roles are being evicted.
+	preemptionEvictingReason = "Evicting"
+	// preemptionEvictedReason is the reason of the Preempted condition once the roles are evicted,
+	// until they are all ready again.
+	preemptionEvictedReason = "Evicted"
+)
+
+// preemptionEvicting reports whether the roles of rbg are being evicted after some of its pods were preempted.
+func preemptionEvicting(rbg *workloadsv1alpha1.RoleBasedGroup) bool {
+	cond := apimeta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupPreempted))
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == preemptionEvictingReason
+}
+
+// reconcilePreemption evicts all the roles of rbg together once the scheduler preempted some of its pods.
+// The roles are recreated as usual once all of their workloads are gone, their pods then wait for the
+// resources to be available again. It returns whether the roles are still being evicted.
+func (r *RoleBasedGroupReconciler) reconcilePreemption(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (bool, error) {
+	if !preemptionEvicting(rbg) {
+		return false, nil
+	}
+	stopped, err := r.stopRoles(ctx, rbg)
+	if err != nil || !stopped {
+		return true, err
+	}
+	log.FromContext(ctx).Info("Roles evicted after preemption, recreating them")
+
+	setCondition(rbg, metav1.Condition{
+		Type:    string(workloadsv1alpha1.RoleBasedGroupPreempted),
+		Status:  metav1.ConditionTrue,
+		Reason:  preemptionEvictedReason,
+		Message: "All the roles were evicted after pods were preempted, waiting for them to be ready again",
+	})
+	// the roles of a restart in progress are all recreated anyway
+	if restartRequested(rbg) || restartConditionTrue(rbg.Status) {
+		rbg.Status.Restarts = rbg.Status.Restarts.DeepCopy()
+		if rbg.Status.Restarts != nil {
+			rbg.Status.Restarts.Roles = nil
+		}
+		setCondition(rbg, restartCondition(true))
+	}
+	return false, r.patchRBGStatus(ctx, rbg)
+}
+
+// preemptionRecoveredCondition returns the Preempted condition of rbg once all its roles are ready again
+// after their eviction, or nil if the condition does not change.
+func preemptionRecoveredCondition(rbg *workloadsv1alpha1.RoleBasedGroup) *metav1.Condition {
+	cond := apimeta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupPreempted))
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != preemptionEvictedReason ||
+		!apimeta.IsStatusConditionTrue(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupReady)) {
+		return nil
+	}
+	return &metav1.Condition{
+		Type:    string(workloadsv1alpha1.RoleBasedGroupPreempted),
+		Status:  metav1.ConditionFalse,
+		Reason:  "Recovered",
+		Message: "All the roles are ready again after their eviction",
+	}
+}
@@ -0,0 +1,88 @@
+package workloads
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+func TestRoleBasedGroupReconciler_reconcilePreemption(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("prefill").Obj(),
+		wrappers.BuildBasicRole("decode").Obj(),
+	}).Obj()
+	rbg.UID = "rbg-uid"
+	rbg.Status.Conditions = []metav1.Condition{
+		{Type: string(workloadsv1alpha1.RoleBasedGroupPreempted), Status: metav1.ConditionTrue, Reason: preemptionEvictingReason},
+		restartCondition(false),
+	}
+	rbg.Status.Restarts = &workloadsv1alpha1.RestartStatus{Count: 1, Roles: []workloadsv1alpha1.RoleRestartStatus{
+		{Name: "prefill", Phase: workloadsv1alpha1.RoleRestartWaitingForReady},
+	}}
+	statefulSet := func(name string) *appsv1.StatefulSet {
+		sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		_ = controllerutil.SetControllerReference(rbg, sts, scheme)
+		return sts
+	}
+
+	// the fake client does not support apply patches, the status of rbg is checked in memory
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(rbg).
+		WithObjects(rbg, statefulSet("test-rbg-prefill"), statefulSet("test-rbg-decode")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(context.Context, client.Client, string, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+				return nil
+			},
+		}).Build()
+	r := &RoleBasedGroupReconciler{client: fakeClient, scheme: scheme, recorder: record.NewFakeRecorder(10)}
+
+	// the workloads of all the roles are deleted first
+	evicting, err := r.reconcilePreemption(context.TODO(), rbg)
+	if err != nil || !evicting {
+		t.Fatalf("reconcilePreemption() = %v, %v, want evicting", evicting, err)
+	}
+	for _, name := range []string{"test-rbg-prefill", "test-rbg-decode"} {
+		err := fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: name}, &appsv1.StatefulSet{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("reconcilePreemption() kept workload %s, get error = %v", name, err)
+		}
+	}
+
+	// the eviction completes once they are gone, replacing the restart in progress
+	evicting, err = r.reconcilePreemption(context.TODO(), rbg)
+	if err != nil || evicting {
+		t.Fatalf("reconcilePreemption() = %v, %v, want evicted", evicting, err)
+	}
+	cond := apimeta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupPreempted))
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != preemptionEvictedReason {
+		t.Errorf("reconcilePreemption() Preempted condition = %+v, want evicted", cond)
+	}
+	if restartRequested(rbg) || restartConditionTrue(rbg.Status) {
+		t.Errorf("reconcilePreemption() kept the restart in progress, restarts = %+v", rbg.Status.Restarts)
+	}
+
+	// the condition is cleared once all the roles are ready again
+	if got := preemptionRecoveredCondition(rbg); got != nil {
+		t.Errorf("preemptionRecoveredCondition() = %+v before the roles are ready", got)
+	}
+	setCondition(rbg, metav1.Condition{Type: string(workloadsv1alpha1.RoleBasedGroupReady), Status: metav1.ConditionTrue, Reason: "AllRolesReady"})
+	if got := preemptionRecoveredCondition(rbg); got == nil || got.Status != metav1.ConditionFalse {
+		t.Errorf("preemptionRecoveredCondition() = %+v, want recovered", got)
+	}
+}
@@ -0,0 +1,214 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/reconciler"
+)
+
+const (
+	// restartRequeueInterval is the interval the progress of a group restart is checked at.
+	restartRequeueInterval = 5 * time.Second
+	// defaultRestartReadyTimeout is how long a recreated role is waited for to be ready by default.
+	defaultRestartReadyTimeout = 5 * time.Minute
+)
+
+// restartReadyTimeout returns how long a role recreated by a restart of rbg is waited for to be ready.
+func restartReadyTimeout(rbg *workloadsv1alpha1.RoleBasedGroup) time.Duration {
+	if rbg.Spec.RestartStrategy == nil || rbg.Spec.RestartStrategy.ReadyTimeoutSeconds <= 0 {
+		return defaultRestartReadyTimeout
+	}
+	return time.Duration(rbg.Spec.RestartStrategy.ReadyTimeoutSeconds) * time.Second
+}
+
+// roleRestartDone reports whether the restart of a role at phase is over, so that the next role is restarted.
+func roleRestartDone(phase workloadsv1alpha1.RoleRestartPhase) bool {
+	return phase == workloadsv1alpha1.RoleRestartCompleted || phase == workloadsv1alpha1.RoleRestartTimedOut
+}
+
+// restartRequested reports whether a restart of rbg is in progress.
+func restartRequested(rbg *workloadsv1alpha1.RoleBasedGroup) bool {
+	return rbg.Status.Restarts != nil && len(rbg.Status.Restarts.Roles) > 0
+}
+
+// roleRestartPhase returns the phase of the role roleName in the restart of rbg in progress,
+// or an empty phase if the role is not restarted.
+func roleRestartPhase(rbg *workloadsv1alpha1.RoleBasedGroup, roleName string) workloadsv1alpha1.RoleRestartPhase {
+	if rbg.Status.Restarts == nil {
+		return ""
+	}
+	for _, role := range rbg.Status.Restarts.Roles {
+		if role.Name == roleName {
+			return role.Phase
+		}
+	}
+	return ""
+}
+
+// reconcileRestart advances the restart of rbg in progress by a step, recreating its roles one after
+// another in the order of status.restarts.roles. The progress is persisted in the status of rbg so that
+// the restart goes on after the controller restarts. A role not ready in time does not hold the roles
+// after it back, the restart ends with the RBGRestartTimedOut reason instead once they are all recreated.
+// It returns whether the restart is still in progress.
+func (r *RoleBasedGroupReconciler) reconcileRestart(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (bool, error) {
+	// a restart without roles to recreate, like one started by an older controller, completes right away
+	if !restartRequested(rbg) && !restartConditionTrue(rbg.Status) {
+		return false, nil
+	}
+	logger := log.FromContext(ctx)
+
+	var roles []workloadsv1alpha1.RoleRestartStatus
+	if rbg.Status.Restarts != nil {
+		roles = slices.Clone(rbg.Status.Restarts.Roles)
+	}
+	inProgress, changed := false, false
+	var advanceErr error
+	var timedOutRoles []string
+	for i := range roles {
+		if roles[i].Phase == workloadsv1alpha1.RoleRestartTimedOut {
+			timedOutRoles = append(timedOutRoles, roles[i].Name)
+			continue
+		}
+		phase, err := r.advanceRoleRestart(ctx, rbg, roles[i])
+		if err != nil {
+			advanceErr = err
+			inProgress = true
+			break
+		}
+		if phase != roles[i].Phase {
+			logger.Info("Role restart advanced", "role", roles[i].Name, "from", roles[i].Phase, "to", phase)
+			roles[i].Phase = phase
+			roles[i].LastTransitionTime = metav1.Now()
+			changed = true
+		}
+		if phase == workloadsv1alpha1.RoleRestartTimedOut {
+			// give the role up, the roles after it are still recreated
+			timedOutRoles = append(timedOutRoles, roles[i].Name)
+			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedRestart,
+				"Role %s was not ready within %v after its restart", roles[i].Name, restartReadyTimeout(rbg))
+		}
+		if !roleRestartDone(phase) {
+			inProgress = true
+			break
+		}
+	}
+
+	switch {
+	case inProgress:
+	case len(timedOutRoles) > 0:
+		logger.Info("RoleBasedGroup restart timed out", "roles", timedOutRoles)
+		roles = nil
+		setCondition(rbg, restartTimedOutCondition(fmt.Sprintf("Roles %s were not ready within %v after their restart",
+			strings.Join(timedOutRoles, ", "), restartReadyTimeout(rbg))))
+		changed = true
+	default:
+		logger.Info("RoleBasedGroup restart completed")
+		roles = nil
+		setCondition(rbg, restartCondition(true))
+		changed = true
+	}
+	if changed {
+		if rbg.Status.Restarts != nil {
+			rbg.Status.Restarts = rbg.Status.Restarts.DeepCopy()
+			rbg.Status.Restarts.Roles = roles
+		}
+		if err := r.patchRBGStatus(ctx, rbg); err != nil {
+			return inProgress, err
+		}
+	}
+	return inProgress, advanceErr
+}
+
+// advanceRoleRestart returns the phase the role of status moves to in the restart of rbg.
+func (r *RoleBasedGroupReconciler) advanceRoleRestart(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	status workloadsv1alpha1.RoleRestartStatus) (workloadsv1alpha1.RoleRestartPhase, error) {
+	role, err := rbg.GetRole(status.Name)
+	if err != nil {
+		// the role was removed from the spec meanwhile
+		return workloadsv1alpha1.RoleRestartCompleted, nil
+	}
+	plugin, ok := reconciler.LookupWorkload(role.Workload)
+	if !ok {
+		return status.Phase, fmt.Errorf("unsupported workload type: %s", role.Workload.String())
+	}
+
+	switch status.Phase {
+	case workloadsv1alpha1.RoleRestartPending:
+		if err := plugin.NewReconciler(r.scheme, r.client).RecreateWorkload(ctx, rbg, role); err != nil {
+			return status.Phase, err
+		}
+		return workloadsv1alpha1.RoleRestartDeleting, nil
+
+	case workloadsv1alpha1.RoleRestartDeleting:
+		exists, err := reconciler.RoleWorkloadExists(ctx, r.scheme, r.client, plugin, rbg, role.Name)
+		if err != nil || exists {
+			return status.Phase, err
+		}
+		return workloadsv1alpha1.RoleRestartWaitingForRecreate, nil
+
+	case workloadsv1alpha1.RoleRestartWaitingForRecreate:
+		exists, err := reconciler.RoleWorkloadExists(ctx, r.scheme, r.client, plugin, rbg, role.Name)
+		if err != nil || !exists {
+			return status.Phase, err
+		}
+		return workloadsv1alpha1.RoleRestartWaitingForReady, nil
+
+	case workloadsv1alpha1.RoleRestartWaitingForReady:
+		ready, err := plugin.NewReconciler(r.scheme, r.client).CheckWorkloadReady(ctx, rbg, role)
+		if apierrors.IsNotFound(err) {
+			// the workload is recreated by the next reconcile
+			err, ready = nil, false
+		}
+		if err != nil {
+			return status.Phase, err
+		}
+		if !ready {
+			if !status.LastTransitionTime.IsZero() && time.Since(status.LastTransitionTime.Time) > restartReadyTimeout(rbg) {
+				return workloadsv1alpha1.RoleRestartTimedOut, nil
+			}
+			return status.Phase, nil
+		}
+		return workloadsv1alpha1.RoleRestartCompleted, nil
+	}
+	return status.Phase, nil
+}
+
+// restartCondition is the RestartInProgress condition of a rbg whose restart started or completed.
+func restartCondition(restartCompleted bool) metav1.Condition {
+	if restartCompleted {
+		return metav1.Condition{
+			Type:               string(workloadsv1alpha1.RoleBasedGroupRestartInProgress),
+			Status:             metav1.ConditionStatus(corev1.ConditionFalse),
+			LastTransitionTime: metav1.Now(),
+			Reason:             "RBGRestartCompleted",
+			Message:            "RBG Restart Completed",
+		}
+	}
+	return metav1.Condition{
+		Type:               string(workloadsv1alpha1.RoleBasedGroupRestartInProgress),
+		Status:             metav1.ConditionStatus(corev1.ConditionTrue),
+		LastTransitionTime: metav1.Now(),
+		Reason:             "RBGRestart",
+		Message:            "RBG Restart in progress",
+	}
+}
+
+// restartTimedOutCondition is the RestartInProgress condition of a rbg whose restart was given up.
+func restartTimedOutCondition(message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(workloadsv1alpha1.RoleBasedGroupRestartInProgress),
+		Status:             metav1.ConditionStatus(corev1.ConditionFalse),
+		LastTransitionTime: metav1.Now(),
+		Reason:             "RBGRestartTimedOut",
+		Message:            message,
+	}
+}
@@ -0,0 +1,248 @@
+package workloads
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+func TestRoleBasedGroupReconciler_advanceRoleRestart(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("worker").Obj(),
+	}).Obj()
+	statefulSet := func(readyReplicas int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-rbg-worker", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](1)},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: readyReplicas},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		role        string
+		phase       workloadsv1alpha1.RoleRestartPhase
+		since       time.Duration
+		objects     []client.Object
+		want        workloadsv1alpha1.RoleRestartPhase
+		wantDeleted bool
+	}{
+		{
+			name:        "pending role is deleted",
+			role:        "worker",
+			phase:       workloadsv1alpha1.RoleRestartPending,
+			objects:     []client.Object{statefulSet(1)},
+			want:        workloadsv1alpha1.RoleRestartDeleting,
+			wantDeleted: true,
+		},
+		{
+			name:    "workload still terminating",
+			role:    "worker",
+			phase:   workloadsv1alpha1.RoleRestartDeleting,
+			objects: []client.Object{statefulSet(1)},
+			want:    workloadsv1alpha1.RoleRestartDeleting,
+		},
+		{
+			name:  "workload deleted",
+			role:  "worker",
+			phase: workloadsv1alpha1.RoleRestartDeleting,
+			want:  workloadsv1alpha1.RoleRestartWaitingForRecreate,
+		},
+		{
+			name:  "workload not recreated yet",
+			role:  "worker",
+			phase: workloadsv1alpha1.RoleRestartWaitingForRecreate,
+			want:  workloadsv1alpha1.RoleRestartWaitingForRecreate,
+		},
+		{
+			name:    "workload recreated",
+			role:    "worker",
+			phase:   workloadsv1alpha1.RoleRestartWaitingForRecreate,
+			objects: []client.Object{statefulSet(0)},
+			want:    workloadsv1alpha1.RoleRestartWaitingForReady,
+		},
+		{
+			name:    "workload not ready",
+			role:    "worker",
+			phase:   workloadsv1alpha1.RoleRestartWaitingForReady,
+			objects: []client.Object{statefulSet(0)},
+			want:    workloadsv1alpha1.RoleRestartWaitingForReady,
+		},
+		{
+			name:    "workload not ready in time",
+			role:    "worker",
+			phase:   workloadsv1alpha1.RoleRestartWaitingForReady,
+			since:   defaultRestartReadyTimeout + time.Minute,
+			objects: []client.Object{statefulSet(0)},
+			want:    workloadsv1alpha1.RoleRestartTimedOut,
+		},
+		{
+			name:    "workload ready",
+			role:    "worker",
+			phase:   workloadsv1alpha1.RoleRestartWaitingForReady,
+			objects: []client.Object{statefulSet(1)},
+			want:    workloadsv1alpha1.RoleRestartCompleted,
+		},
+		{
+			name:  "role removed from the spec",
+			role:  "router",
+			phase: workloadsv1alpha1.RoleRestartPending,
+			want:  workloadsv1alpha1.RoleRestartCompleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+			r := &RoleBasedGroupReconciler{client: fakeClient, scheme: scheme, recorder: record.NewFakeRecorder(10)}
+
+			status := workloadsv1alpha1.RoleRestartStatus{
+				Name: tt.role, Phase: tt.phase, LastTransitionTime: metav1.NewTime(time.Now().Add(-tt.since)),
+			}
+			got, err := r.advanceRoleRestart(context.TODO(), rbg, status)
+			if err != nil {
+				t.Fatalf("advanceRoleRestart() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("advanceRoleRestart() = %v, want %v", got, tt.want)
+			}
+			if tt.wantDeleted {
+				err := fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "test-rbg-worker"}, &appsv1.StatefulSet{})
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("advanceRoleRestart() kept the workload, get error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRoleBasedGroupReconciler_reconcileRestartTimeout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("worker").Obj(),
+		wrappers.BuildBasicRole("router").Obj(),
+	}).Obj()
+	rbg.Spec.RestartStrategy = &workloadsv1alpha1.RestartStrategy{ReadyTimeoutSeconds: 60}
+	rbg.Status.Restarts = &workloadsv1alpha1.RestartStatus{Count: 1, Roles: []workloadsv1alpha1.RoleRestartStatus{{
+		Name:               "worker",
+		Phase:              workloadsv1alpha1.RoleRestartWaitingForReady,
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+	}, {
+		Name:  "router",
+		Phase: workloadsv1alpha1.RoleRestartPending,
+	}}}
+	setCondition(rbg, restartCondition(false))
+	statefulSet := func(name string, readyReplicas int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](1)},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: readyReplicas},
+		}
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(statefulSet("test-rbg-worker", 0), statefulSet("test-rbg-router", 1)).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(context.Context, client.Client, string, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+				return nil
+			},
+		}).Build()
+	r := &RoleBasedGroupReconciler{client: fakeClient, scheme: scheme, recorder: record.NewFakeRecorder(10)}
+
+	// the worker is given up, the router behind it is still recreated
+	inProgress, err := r.reconcileRestart(context.TODO(), rbg)
+	if err != nil {
+		t.Fatalf("reconcileRestart() error = %v", err)
+	}
+	if !inProgress {
+		t.Fatalf("reconcileRestart() ended the restart before the router was recreated")
+	}
+	if phase := roleRestartPhase(rbg, "worker"); phase != workloadsv1alpha1.RoleRestartTimedOut {
+		t.Errorf("reconcileRestart() worker phase = %v, want %v", phase, workloadsv1alpha1.RoleRestartTimedOut)
+	}
+	if phase := roleRestartPhase(rbg, "router"); phase != workloadsv1alpha1.RoleRestartDeleting {
+		t.Errorf("reconcileRestart() router phase = %v, want %v", phase, workloadsv1alpha1.RoleRestartDeleting)
+	}
+	err = fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "test-rbg-router"}, &appsv1.StatefulSet{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("reconcileRestart() kept the router workload, get error = %v", err)
+	}
+
+	// the router is deleted, recreated and ready
+	for _, recreate := range []bool{false, true, false} {
+		if recreate {
+			if err := fakeClient.Create(context.TODO(), statefulSet("test-rbg-router", 1)); err != nil {
+				t.Fatalf("create router: %v", err)
+			}
+		}
+		if inProgress, err = r.reconcileRestart(context.TODO(), rbg); err != nil {
+			t.Fatalf("reconcileRestart() error = %v", err)
+		}
+	}
+	if inProgress, err = r.reconcileRestart(context.TODO(), rbg); err != nil {
+		t.Fatalf("reconcileRestart() error = %v", err)
+	}
+	if inProgress || restartRequested(rbg) {
+		t.Errorf("reconcileRestart() kept the restart in progress after all the roles were recreated, roles = %+v",
+			rbg.Status.Restarts.Roles)
+	}
+	cond := apimeta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupRestartInProgress))
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "RBGRestartTimedOut" {
+		t.Errorf("reconcileRestart() condition = %+v, want False/RBGRestartTimedOut", cond)
+	}
+	if rbg.Status.Restarts.Count != 1 {
+		t.Errorf("reconcileRestart() restart count = %d, want 1", rbg.Status.Restarts.Count)
+	}
+}
+
+func Test_restartReadyTimeout(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").Obj()
+	if got := restartReadyTimeout(rbg); got != defaultRestartReadyTimeout {
+		t.Errorf("restartReadyTimeout() = %v, want %v", got, defaultRestartReadyTimeout)
+	}
+	rbg.Spec.RestartStrategy = &workloadsv1alpha1.RestartStrategy{ReadyTimeoutSeconds: 60}
+	if got := restartReadyTimeout(rbg); got != time.Minute {
+		t.Errorf("restartReadyTimeout() = %v, want %v", got, time.Minute)
+	}
+}
+
+func Test_roleRestartPhase(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").Obj()
+	if got := roleRestartPhase(rbg, "worker"); got != "" {
+		t.Errorf("roleRestartPhase() = %v, want no phase", got)
+	}
+	if restartRequested(rbg) {
+		t.Errorf("restartRequested() = true, want false")
+	}
+
+	rbg.Status.Restarts = &workloadsv1alpha1.RestartStatus{Roles: []workloadsv1alpha1.RoleRestartStatus{
+		{Name: "router", Phase: workloadsv1alpha1.RoleRestartCompleted},
+		{Name: "worker", Phase: workloadsv1alpha1.RoleRestartDeleting},
+	}}
+	if got := roleRestartPhase(rbg, "worker"); got != workloadsv1alpha1.RoleRestartDeleting {
+		t.Errorf("roleRestartPhase() = %v, want %v", got, workloadsv1alpha1.RoleRestartDeleting)
+	}
+	if !restartRequested(rbg) {
+		t.Errorf("restartRequested() = false, want true")
+	}
+}
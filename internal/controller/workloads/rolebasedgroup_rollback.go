@@ -0,0 +1,83 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/history"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// rollback restores the role templates selected by rbg.spec.rollbackTo and clears the field.
+// The restored templates are then rolled out by the next reconcile, following the rollout
+// strategy of each role.
+func (r *RoleBasedGroupReconciler) rollback(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	logger := log.FromContext(ctx)
+	rollbackTo := rbg.Spec.RollbackTo
+	revisionManager := history.NewDefaultRevisionManager(r.client)
+
+	var rolledBack []string
+	for i := range rbg.Spec.Roles {
+		role := &rbg.Spec.Roles[i]
+		if len(rollbackTo.Roles) > 0 && !utils.ContainsString(rollbackTo.Roles, role.Name) {
+			continue
+		}
+
+		revisions, err := revisionManager.ListRoleRevisions(ctx, rbg, role.Name)
+		if err != nil {
+			return err
+		}
+		status, _ := rbg.GetRoleStatus(role.Name)
+		revision := history.FindRollbackRevision(revisions, status.UpdateRevision, rollbackTo.Revision)
+		if revision == nil {
+			logger.Info("no revision to roll back to", "role", role.Name, "revision", rollbackTo.Revision)
+			continue
+		}
+		if err := history.RestoreRoleTemplate(role, revision); err != nil {
+			return err
+		}
+		rolledBack = append(rolledBack, fmt.Sprintf("%s to revision %d", role.Name, revision.Revision))
+	}
+
+	var condition metav1.Condition
+	if len(rolledBack) == 0 {
+		message := "Unable to roll back, no previous revision"
+		if rollbackTo.Revision > 0 {
+			message = fmt.Sprintf("Unable to find revision %d to roll back to", rollbackTo.Revision)
+		}
+		condition = metav1.Condition{
+			Type:               string(workloadsv1alpha1.RoleBasedGroupRolledBack),
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "RevisionNotFound",
+			Message:            message,
+		}
+		r.recorder.Event(rbg, corev1.EventTypeWarning, FailedRollback, condition.Message)
+	} else {
+		condition = metav1.Condition{
+			Type:               string(workloadsv1alpha1.RoleBasedGroupRolledBack),
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             RolledBack,
+			Message:            fmt.Sprintf("Rolled back %s", strings.Join(rolledBack, ", ")),
+		}
+		r.recorder.Event(rbg, corev1.EventTypeNormal, RolledBack, condition.Message)
+	}
+
+	rbg.Spec.RollbackTo = nil
+	if err := r.client.Update(ctx, rbg); err != nil {
+		return err
+	}
+	logger.Info("rollback done", "result", condition.Message)
+
+	setCondition(rbg, condition)
+	rbgApplyConfig := utils.RoleBasedGroup(rbg.Name, rbg.Namespace, rbg.Kind, rbg.APIVersion).
+		WithStatus(utils.RbgStatus().WithRoleStatuses(rbg.Status.RoleStatuses).WithConditions(rbg.Status.Conditions).
+			WithRollout(rbg.Status.Rollout).WithRestarts(rbg.Status.Restarts).WithObservedGeneration(rbg.Status.ObservedGeneration))
+	return utils.PatchObjectApplyConfiguration(ctx, r.client, rbgApplyConfig, utils.PatchStatus)
+}
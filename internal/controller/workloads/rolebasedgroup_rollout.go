@@ -0,0 +1,223 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/reconciler"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// reconcileRollout computes the rollout gate of each role following spec.rolloutStrategy,
+// and records the rollout progress in the status of rbg.
+// It returns nil gates when the roles roll out independently.
+func (r *RoleBasedGroupReconciler) reconcileRollout(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	sortedRoles []*workloadsv1alpha1.RoleSpec, updateRevisions map[string]*appsv1.ControllerRevision) (map[string]*reconciler.RolloutGate, error) {
+	var gates map[string]*reconciler.RolloutGate
+	var rolloutStatus *workloadsv1alpha1.GroupRolloutStatus
+	if rbg.Spec.RolloutStrategy != nil {
+		rollout, err := newCoordinatedRollout(ctx, r.scheme, r.client, rbg, sortedRoles, updateRevisions)
+		if err != nil {
+			return nil, err
+		}
+		rolloutStatus = rollout.plan(rbg.Status.Rollout)
+		gates = rollout.gates(rolloutStatus)
+	}
+
+	if !setRolloutStatus(rbg, rolloutStatus) {
+		return gates, nil
+	}
+	// persist the step right away, roles may not all be reconciled in this round
+	rbgApplyConfig := utils.RoleBasedGroup(rbg.Name, rbg.Namespace, rbg.Kind, rbg.APIVersion).
+		WithStatus(utils.RbgStatus().WithRoleStatuses(rbg.Status.RoleStatuses).WithConditions(rbg.Status.Conditions).
+			WithRollout(rbg.Status.Rollout).WithRestarts(rbg.Status.Restarts).WithObservedGeneration(rbg.Status.ObservedGeneration))
+	if err := utils.PatchObjectApplyConfiguration(ctx, r.client, rbgApplyConfig, utils.PatchStatus); err != nil {
+		return nil, err
+	}
+	return gates, nil
+}
+
+// roleRollout is the progress of a role in the coordinated rollout of its rbg.
+type roleRollout struct {
+	name     string
+	replicas int32
+	// perStep is the number of replicas released per step by the Ratio strategy
+	perStep int32
+	// revision is the hash of the update revision of the role
+	revision string
+	// state is nil when the workload does not exist yet
+	state *reconciler.RolloutState
+}
+
+// updated returns the number of replicas of the role running the update revision.
+func (r roleRollout) updated() int32 {
+	if r.state == nil {
+		return r.replicas
+	}
+	if r.state.Revision != r.revision {
+		// the workload has not been rolled out to the update revision yet
+		return 0
+	}
+	return min(r.state.UpdatedReplicas, r.replicas)
+}
+
+func (r roleRollout) ready() bool {
+	if r.state == nil {
+		return true
+	}
+	return r.state.Observed && r.state.ReadyReplicas >= r.replicas
+}
+
+// coordinatedRollout plans the steps moving the roles of rbg to their update revisions together.
+type coordinatedRollout struct {
+	strategy *workloadsv1alpha1.GroupRolloutStrategy
+	// roles in dependency order
+	roles []roleRollout
+}
+
+func (c *coordinatedRollout) totalSteps() int32 {
+	if c.strategy.Type != workloadsv1alpha1.RatioRolloutStrategyType {
+		return int32(len(c.roles))
+	}
+	total := int32(1)
+	for _, role := range c.roles {
+		total = max(total, (role.replicas+role.perStep-1)/role.perStep)
+	}
+	return total
+}
+
+// target returns the number of replicas of the i-th role released at step.
+func (c *coordinatedRollout) target(i int, step int32) int32 {
+	role := c.roles[i]
+	if c.strategy.Type != workloadsv1alpha1.RatioRolloutStrategyType {
+		if int32(i) < step {
+			return role.replicas
+		}
+		return 0
+	}
+	return min(role.replicas, step*role.perStep)
+}
+
+// stepDone reports whether every role reached its target of step and is ready.
+func (c *coordinatedRollout) stepDone(step int32) bool {
+	for i, role := range c.roles {
+		if role.updated() < c.target(i, step) || !role.ready() {
+			return false
+		}
+	}
+	return true
+}
+
+// plan returns the rollout status following current, or nil once all the roles are rolled out.
+// The step only moves forward, so that a replica going unready during a step never holds back
+// replicas already released.
+func (c *coordinatedRollout) plan(current *workloadsv1alpha1.GroupRolloutStatus) *workloadsv1alpha1.GroupRolloutStatus {
+	total := c.totalSteps()
+	if current == nil {
+		pending := false
+		for _, role := range c.roles {
+			if role.updated() < role.replicas {
+				pending = true
+				break
+			}
+		}
+		if !pending {
+			return nil
+		}
+		current = &workloadsv1alpha1.GroupRolloutStatus{Step: 1}
+	}
+
+	step := min(max(current.Step, 1), total)
+	for step < total && c.stepDone(step) {
+		step++
+	}
+	if step == total && c.stepDone(step) {
+		return nil
+	}
+	return &workloadsv1alpha1.GroupRolloutStatus{Step: step, TotalSteps: total}
+}
+
+// gates returns the rollout gate of each role for status.
+func (c *coordinatedRollout) gates(status *workloadsv1alpha1.GroupRolloutStatus) map[string]*reconciler.RolloutGate {
+	gates := make(map[string]*reconciler.RolloutGate, len(c.roles))
+	for i, role := range c.roles {
+		gate := &reconciler.RolloutGate{Revision: role.revision}
+		if status != nil {
+			gate.Partition = role.replicas - c.target(i, status.Step)
+		}
+		gates[role.name] = gate
+	}
+	return gates
+}
+
+// newCoordinatedRollout collects the rollout state of the roles of rbg.
+func newCoordinatedRollout(ctx context.Context, scheme *runtime.Scheme, client client.Client, rbg *workloadsv1alpha1.RoleBasedGroup,
+	sortedRoles []*workloadsv1alpha1.RoleSpec, updateRevisions map[string]*appsv1.ControllerRevision) (*coordinatedRollout, error) {
+	ratios := make(map[string]int32, len(rbg.Spec.RolloutStrategy.Ratio))
+	for _, ratio := range rbg.Spec.RolloutStrategy.Ratio {
+		ratios[ratio.Name] = ratio.Replicas
+	}
+
+	c := &coordinatedRollout{strategy: rbg.Spec.RolloutStrategy}
+	for _, role := range sortedRoles {
+		roleRollout := roleRollout{
+			name:     role.Name,
+			replicas: *role.Replicas,
+			perStep:  1,
+		}
+		if perStep, ok := ratios[role.Name]; ok && perStep > 0 {
+			roleRollout.perStep = perStep
+		}
+		if revision, ok := updateRevisions[role.Name]; ok {
+			roleRollout.revision = revision.Labels[appsv1.ControllerRevisionHashLabelKey]
+		}
+
+		workloadReconciler, err := reconciler.NewWorkloadReconciler(role.Workload, scheme, client)
+		if err != nil {
+			return nil, err
+		}
+		if stateReader, ok := workloadReconciler.(reconciler.RolloutStateReader); ok {
+			roleRollout.state, err = stateReader.RolloutState(ctx, rbg, role)
+			if err != nil {
+				return nil, err
+			}
+		}
+		c.roles = append(c.roles, roleRollout)
+	}
+	return c, nil
+}
+
+// setRolloutStatus records the rollout progress in the status of rbg, returning whether it changed.
+func setRolloutStatus(rbg *workloadsv1alpha1.RoleBasedGroup, status *workloadsv1alpha1.GroupRolloutStatus) bool {
+	oldStatus := rbg.Status.Rollout
+	rbg.Status.Rollout = status
+
+	var condition metav1.Condition
+	if status != nil {
+		condition = metav1.Condition{
+			Type:    string(workloadsv1alpha1.RoleBasedGroupCoordinatedRolloutInProgress),
+			Status:  metav1.ConditionTrue,
+			Reason:  "RolloutInProgress",
+			Message: fmt.Sprintf("Rolling out step %d of %d", status.Step, status.TotalSteps),
+		}
+	} else if apimeta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupCoordinatedRolloutInProgress)) != nil {
+		condition = metav1.Condition{
+			Type:    string(workloadsv1alpha1.RoleBasedGroupCoordinatedRolloutInProgress),
+			Status:  metav1.ConditionFalse,
+			Reason:  "RolloutCompleted",
+			Message: "All roles are rolled out",
+		}
+	} else {
+		return oldStatus != nil
+	}
+
+	conditionChanged := apimeta.SetStatusCondition(&rbg.Status.Conditions, condition)
+	statusChanged := (oldStatus == nil) != (status == nil) || (status != nil && *oldStatus != *status)
+	return conditionChanged || statusChanged
+}
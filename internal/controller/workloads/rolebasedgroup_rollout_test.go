@@ -0,0 +1,160 @@
+package workloads
+
+import (
+	"reflect"
+	"testing"
+
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/reconciler"
+)
+
+func TestCoordinatedRollout_plan(t *testing.T) {
+	state := func(revision string, updated, ready int32) *reconciler.RolloutState {
+		return &reconciler.RolloutState{Revision: revision, UpdatedReplicas: updated, ReadyReplicas: ready, Observed: true}
+	}
+	dependencyOrder := &workloadsv1alpha1.GroupRolloutStrategy{Type: workloadsv1alpha1.DependencyOrderRolloutStrategyType}
+	ratio := &workloadsv1alpha1.GroupRolloutStrategy{Type: workloadsv1alpha1.RatioRolloutStrategyType}
+
+	tests := []struct {
+		name           string
+		strategy       *workloadsv1alpha1.GroupRolloutStrategy
+		roles          []roleRollout
+		current        *workloadsv1alpha1.GroupRolloutStatus
+		wantStatus     *workloadsv1alpha1.GroupRolloutStatus
+		wantPartitions map[string]int32
+	}{
+		{
+			name:     "no workload yet",
+			strategy: dependencyOrder,
+			roles: []roleRollout{
+				{name: "prefill", replicas: 2, perStep: 1, revision: "v2"},
+				{name: "decode", replicas: 4, perStep: 1, revision: "v2"},
+			},
+			wantStatus:     nil,
+			wantPartitions: map[string]int32{"prefill": 0, "decode": 0},
+		},
+		{
+			name:     "dependency order starts with the first role",
+			strategy: dependencyOrder,
+			roles: []roleRollout{
+				{name: "prefill", replicas: 2, perStep: 1, revision: "v2", state: state("v1", 2, 2)},
+				{name: "decode", replicas: 4, perStep: 1, revision: "v2", state: state("v1", 4, 4)},
+			},
+			wantStatus:     &workloadsv1alpha1.GroupRolloutStatus{Step: 1, TotalSteps: 2},
+			wantPartitions: map[string]int32{"prefill": 0, "decode": 4},
+		},
+		{
+			name:     "dependency order moves to the next role once ready",
+			strategy: dependencyOrder,
+			roles: []roleRollout{
+				{name: "prefill", replicas: 2, perStep: 1, revision: "v2", state: state("v2", 2, 2)},
+				{name: "decode", replicas: 4, perStep: 1, revision: "v2", state: state("v2", 0, 4)},
+			},
+			current:        &workloadsv1alpha1.GroupRolloutStatus{Step: 1, TotalSteps: 2},
+			wantStatus:     &workloadsv1alpha1.GroupRolloutStatus{Step: 2, TotalSteps: 2},
+			wantPartitions: map[string]int32{"prefill": 0, "decode": 0},
+		},
+		{
+			name:     "ratio releases replicas of every role",
+			strategy: ratio,
+			roles: []roleRollout{
+				{name: "prefill", replicas: 2, perStep: 1, revision: "v2", state: state("v1", 2, 2)},
+				{name: "decode", replicas: 4, perStep: 2, revision: "v2", state: state("v1", 4, 4)},
+			},
+			wantStatus:     &workloadsv1alpha1.GroupRolloutStatus{Step: 1, TotalSteps: 2},
+			wantPartitions: map[string]int32{"prefill": 1, "decode": 2},
+		},
+		{
+			name:     "ratio waits for readiness",
+			strategy: ratio,
+			roles: []roleRollout{
+				{name: "prefill", replicas: 2, perStep: 1, revision: "v2", state: state("v2", 1, 2)},
+				{name: "decode", replicas: 4, perStep: 2, revision: "v2", state: state("v2", 2, 3)},
+			},
+			current:        &workloadsv1alpha1.GroupRolloutStatus{Step: 1, TotalSteps: 2},
+			wantStatus:     &workloadsv1alpha1.GroupRolloutStatus{Step: 1, TotalSteps: 2},
+			wantPartitions: map[string]int32{"prefill": 1, "decode": 2},
+		},
+		{
+			name:     "ratio never moves back",
+			strategy: ratio,
+			roles: []roleRollout{
+				{name: "prefill", replicas: 2, perStep: 1, revision: "v2", state: state("v2", 1, 1)},
+				{name: "decode", replicas: 4, perStep: 2, revision: "v2", state: state("v2", 2, 4)},
+			},
+			current:        &workloadsv1alpha1.GroupRolloutStatus{Step: 2, TotalSteps: 2},
+			wantStatus:     &workloadsv1alpha1.GroupRolloutStatus{Step: 2, TotalSteps: 2},
+			wantPartitions: map[string]int32{"prefill": 0, "decode": 0},
+		},
+		{
+			name:     "rollout completed",
+			strategy: ratio,
+			roles: []roleRollout{
+				{name: "prefill", replicas: 2, perStep: 1, revision: "v2", state: state("v2", 2, 2)},
+				{name: "decode", replicas: 4, perStep: 2, revision: "v2", state: state("v2", 4, 4)},
+			},
+			current:        &workloadsv1alpha1.GroupRolloutStatus{Step: 2, TotalSteps: 2},
+			wantStatus:     nil,
+			wantPartitions: map[string]int32{"prefill": 0, "decode": 0},
+		},
+		{
+			name:     "unobserved workload is not ready",
+			strategy: dependencyOrder,
+			roles: []roleRollout{
+				{name: "prefill", replicas: 2, perStep: 1, revision: "v2",
+					state: &reconciler.RolloutState{Revision: "v2", UpdatedReplicas: 2, ReadyReplicas: 2}},
+				{name: "decode", replicas: 4, perStep: 1, revision: "v2", state: state("v1", 4, 4)},
+			},
+			current:        &workloadsv1alpha1.GroupRolloutStatus{Step: 1, TotalSteps: 2},
+			wantStatus:     &workloadsv1alpha1.GroupRolloutStatus{Step: 1, TotalSteps: 2},
+			wantPartitions: map[string]int32{"prefill": 0, "decode": 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &coordinatedRollout{strategy: tt.strategy, roles: tt.roles}
+			status := c.plan(tt.current)
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("plan() = %v, want %v", status, tt.wantStatus)
+			}
+			partitions := map[string]int32{}
+			for name, gate := range c.gates(status) {
+				partitions[name] = gate.Partition
+				if gate.Revision != "v2" {
+					t.Errorf("gates() revision of %s = %s, want v2", name, gate.Revision)
+				}
+			}
+			if !reflect.DeepEqual(partitions, tt.wantPartitions) {
+				t.Errorf("gates() partitions = %v, want %v", partitions, tt.wantPartitions)
+			}
+		})
+	}
+}
+
+func TestSetRolloutStatus(t *testing.T) {
+	rbg := &workloadsv1alpha1.RoleBasedGroup{}
+	if setRolloutStatus(rbg, nil) {
+		t.Fatalf("setRolloutStatus() changed status without rollout")
+	}
+
+	if !setRolloutStatus(rbg, &workloadsv1alpha1.GroupRolloutStatus{Step: 1, TotalSteps: 2}) {
+		t.Fatalf("setRolloutStatus() did not record the rollout start")
+	}
+	if setRolloutStatus(rbg, &workloadsv1alpha1.GroupRolloutStatus{Step: 1, TotalSteps: 2}) {
+		t.Fatalf("setRolloutStatus() changed status for the same step")
+	}
+	if !setRolloutStatus(rbg, &workloadsv1alpha1.GroupRolloutStatus{Step: 2, TotalSteps: 2}) {
+		t.Fatalf("setRolloutStatus() did not record the next step")
+	}
+	if cond := rbg.Status.Conditions[0]; cond.Message != "Rolling out step 2 of 2" {
+		t.Errorf("condition message = %s", cond.Message)
+	}
+
+	if !setRolloutStatus(rbg, nil) {
+		t.Fatalf("setRolloutStatus() did not record the rollout completion")
+	}
+	if rbg.Status.Rollout != nil || rbg.Status.Conditions[0].Reason != "RolloutCompleted" {
+		t.Errorf("setRolloutStatus() status = %v, conditions = %v", rbg.Status.Rollout, rbg.Status.Conditions)
+	}
+}
@@ -35,7 +35,7 @@ import (
 )
 
 const (
-	RoleBasedGroupSetKey = "workload-rbgs-name"
+	RoleBasedGroupSetKey = workloadsv1alpha1.GroupSetLabelKey
 )
 
 // RoleBasedGroupSetReconciler reconciles a RoleBasedGroupSet object
@@ -119,7 +119,9 @@ func (r *RoleBasedGroupSetReconciler) createRBG(
 		return err
 	}
 
-	rbg.Spec.Roles = rbgset.Spec.Template.Roles
+	rbg.Spec = *rbgset.Spec.Template.DeepCopy()
+	// a new group has no revision to roll back to
+	rbg.Spec.RollbackTo = nil
 
 	err := r.client.Create(ctx, &rbg)
 	if err != nil {
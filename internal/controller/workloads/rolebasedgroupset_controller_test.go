@@ -17,20 +17,27 @@ limitations under the License.
 package workloads
 
 import (
+	"context"
 	"errors"
+	"reflect"
+	"sync"
 	"testing"
 
 	// . "github.com/onsi/ginkgo/v2"
 	// . "github.com/onsi/gomega"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 	"sigs.k8s.io/rbgs/pkg/utils"
+	"sigs.k8s.io/rbgs/test/wrappers"
 )
 
 func TestRoleBasedGroupSetReconciler_CheckCrdExists(t *testing.T) {
@@ -149,3 +156,57 @@ func TestRoleBasedGroupSetReconciler_CheckCrdExists(t *testing.T) {
 		})
 	}
 }
+
+func TestRoleBasedGroupSetReconciler_createRBG(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	rbg := wrappers.BuildBasicRoleBasedGroup("template", "default").
+		WithRoles([]workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("worker").Obj()}).
+		WithPriorityClassName("high").
+		WithPreemptionPolicy(workloadsv1alpha1.EvictRBGOnPodPreemption).Obj()
+	template := rbg.Spec
+	template.PodGroupPolicy = &workloadsv1alpha1.PodGroupPolicy{PodGroupPolicySource: workloadsv1alpha1.PodGroupPolicySource{
+		KubeScheduling: &workloadsv1alpha1.KubeSchedulingPodGroupPolicySource{ScheduleTimeoutSeconds: ptr.To[int32](30)},
+	}}
+	template.RevisionHistoryLimit = ptr.To[int32](5)
+	template.RollbackTo = &workloadsv1alpha1.RollbackConfig{Revision: 1}
+	template.RolloutStrategy = &workloadsv1alpha1.GroupRolloutStrategy{}
+	template.RestartStrategy = &workloadsv1alpha1.RestartStrategy{}
+	template.TopologyPolicy = &workloadsv1alpha1.TopologyPolicy{
+		TopologyKey: "kubernetes.io/hostname", Mode: workloadsv1alpha1.SpreadTopologyPolicyMode,
+	}
+	// every field of the template is set, so that a field added without being copied fails the test
+	for i, value := 0, reflect.ValueOf(template); i < value.NumField(); i++ {
+		if value.Field(i).IsZero() {
+			t.Fatalf("template field %s is not set", value.Type().Field(i).Name)
+		}
+	}
+
+	rbgset := &workloadsv1alpha1.RoleBasedGroupSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbgset", Namespace: "default", UID: "rbgset-uid"},
+		Spec:       workloadsv1alpha1.RoleBasedGroupSetSpec{Replicas: ptr.To[int32](1), Template: template},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rbgset).Build()
+	r := &RoleBasedGroupSetReconciler{client: fakeClient, scheme: scheme}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	if err := r.createRBG(context.TODO(), rbgset, &wg); err != nil {
+		t.Fatalf("createRBG() error = %v", err)
+	}
+	rbgList := &workloadsv1alpha1.RoleBasedGroupList{}
+	if err := fakeClient.List(context.TODO(), rbgList); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(rbgList.Items) != 1 {
+		t.Fatalf("createRBG() created %d rbgs, want 1", len(rbgList.Items))
+	}
+	// every field but rollbackTo is copied
+	want := *template.DeepCopy()
+	want.RollbackTo = nil
+	if got := rbgList.Items[0].Spec; !apiequality.Semantic.DeepEqual(got, want) {
+		t.Errorf("createRBG() spec = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,474 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/dependency"
+	"sigs.k8s.io/rbgs/pkg/discovery"
+	"sigs.k8s.io/rbgs/pkg/kueue"
+	"sigs.k8s.io/rbgs/pkg/reconciler"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// SetupRoleBasedGroupWebhookWithManager registers the defaulting and validating webhooks for RoleBasedGroup.
+// Exec dependency probes are only admitted when allowExecProbes is set, the controller must run them.
+func SetupRoleBasedGroupWebhookWithManager(mgr ctrl.Manager, allowExecProbes bool) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&workloadsv1alpha1.RoleBasedGroup{}).
+		WithDefaulter(&RoleBasedGroupCustomDefaulter{}).
+		WithValidator(&RoleBasedGroupCustomValidator{allowExecProbes: allowExecProbes}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-workloads-x-k8s-io-v1alpha1-rolebasedgroup,mutating=true,failurePolicy=fail,sideEffects=None,groups=workloads.x-k8s.io,resources=rolebasedgroups,verbs=create;update,versions=v1alpha1,name=mrolebasedgroup-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// RoleBasedGroupCustomDefaulter fills in the per-workload defaults of each role.
+type RoleBasedGroupCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &RoleBasedGroupCustomDefaulter{}
+
+func (d *RoleBasedGroupCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	rbg, ok := obj.(*workloadsv1alpha1.RoleBasedGroup)
+	if !ok {
+		return fmt.Errorf("expected a RoleBasedGroup object but got %T", obj)
+	}
+	log.FromContext(ctx).V(1).Info("defaulting rbg", "rbg", rbg.Name)
+
+	for i := range rbg.Spec.Roles {
+		defaultRole(&rbg.Spec.Roles[i])
+	}
+	return nil
+}
+
+func defaultRole(role *workloadsv1alpha1.RoleSpec) {
+	if role.Workload.APIVersion == "" && role.Workload.Kind == "" {
+		role.Workload = workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"}
+	}
+	if role.Replicas == nil {
+		role.Replicas = ptr.To[int32](1)
+	}
+
+	if role.RolloutStrategy == nil {
+		role.RolloutStrategy = &workloadsv1alpha1.RolloutStrategy{}
+	}
+	if role.RolloutStrategy.Type == "" {
+		role.RolloutStrategy.Type = workloadsv1alpha1.RollingUpdateStrategyType
+	}
+	if role.RolloutStrategy.RollingUpdate == nil {
+		role.RolloutStrategy.RollingUpdate = defaultRollingUpdate(role.Workload)
+	}
+
+	switch role.Workload.String() {
+	case workloadsv1alpha1.LeaderWorkerSetWorkloadType:
+		if role.RestartPolicy == "" {
+			role.RestartPolicy = workloadsv1alpha1.RecreateRoleInstanceOnPodRestart
+		}
+		if role.LeaderWorkerSet.Size == nil {
+			role.LeaderWorkerSet.Size = ptr.To[int32](1)
+		}
+	default:
+		if role.RestartPolicy == "" {
+			role.RestartPolicy = workloadsv1alpha1.NoneRestartPolicy
+		}
+	}
+}
+
+// defaultRollingUpdate returns the rolling update parameters each workload would use natively.
+func defaultRollingUpdate(workload workloadsv1alpha1.WorkloadSpec) *workloadsv1alpha1.RollingUpdate {
+	if workload.String() == workloadsv1alpha1.DeploymentWorkloadType {
+		return &workloadsv1alpha1.RollingUpdate{
+			MaxUnavailable: intstr.FromString("25%"),
+			MaxSurge:       intstr.FromString("25%"),
+		}
+	}
+	return &workloadsv1alpha1.RollingUpdate{
+		MaxUnavailable: intstr.FromInt32(1),
+		MaxSurge:       intstr.FromInt32(0),
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-workloads-x-k8s-io-v1alpha1-rolebasedgroup,mutating=false,failurePolicy=fail,sideEffects=None,groups=workloads.x-k8s.io,resources=rolebasedgroups,verbs=create;update,versions=v1alpha1,name=vrolebasedgroup-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// RoleBasedGroupCustomValidator rejects specs the rbg controller can not reconcile.
+type RoleBasedGroupCustomValidator struct {
+	allowExecProbes bool
+}
+
+var _ webhook.CustomValidator = &RoleBasedGroupCustomValidator{}
+
+func (v *RoleBasedGroupCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	rbg, ok := obj.(*workloadsv1alpha1.RoleBasedGroup)
+	if !ok {
+		return nil, fmt.Errorf("expected a RoleBasedGroup object but got %T", obj)
+	}
+	return nil, validateRoleBasedGroup(ctx, rbg, v.allowExecProbes)
+}
+
+func (v *RoleBasedGroupCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	rbg, ok := newObj.(*workloadsv1alpha1.RoleBasedGroup)
+	if !ok {
+		return nil, fmt.Errorf("expected a RoleBasedGroup object for the newObj but got %T", newObj)
+	}
+	return nil, validateRoleBasedGroup(ctx, rbg, v.allowExecProbes)
+}
+
+func (v *RoleBasedGroupCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateRoleBasedGroup(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, allowExecProbes bool) error {
+	allErrs := validateRoles(ctx, rbg, allowExecProbes)
+	allErrs = append(allErrs, validatePodGroupPolicy(rbg.Spec.PodGroupPolicy, field.NewPath("spec", "podGroupPolicy"))...)
+	allErrs = append(allErrs, validateRollbackTo(rbg, field.NewPath("spec", "rollbackTo"))...)
+	allErrs = append(allErrs, validateGroupRolloutStrategy(rbg, field.NewPath("spec", "rolloutStrategy"))...)
+	allErrs = append(allErrs, validateRestartStrategy(rbg.Spec.RestartStrategy, field.NewPath("spec", "restartStrategy"))...)
+	allErrs = append(allErrs, validateTopologyPolicy(rbg.Spec.TopologyPolicy, field.NewPath("spec", "topologyPolicy"))...)
+	allErrs = append(allErrs, validatePriorityClassName(rbg.Spec.PriorityClassName, field.NewPath("spec", "priorityClassName"))...)
+	switch rbg.Spec.PreemptionPolicy {
+	case "", workloadsv1alpha1.NonePreemptionPolicy, workloadsv1alpha1.EvictRBGOnPodPreemption:
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec", "preemptionPolicy"), rbg.Spec.PreemptionPolicy,
+			[]workloadsv1alpha1.PreemptionPolicyType{
+				workloadsv1alpha1.NonePreemptionPolicy,
+				workloadsv1alpha1.EvictRBGOnPodPreemption,
+			}))
+	}
+	if rbg.GetQueueName() != "" && len(rbg.Spec.Roles) > kueue.MaxPodSets {
+		allErrs = append(allErrs, field.TooMany(field.NewPath("spec", "roles"), len(rbg.Spec.Roles), kueue.MaxPodSets))
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroup").GroupKind(), rbg.Name, allErrs)
+}
+
+func validateRoles(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, allowExecProbes bool) field.ErrorList {
+	var allErrs field.ErrorList
+	rolesPath := field.NewPath("spec").Child("roles")
+
+	roleNames := make(map[string]bool, len(rbg.Spec.Roles))
+	for i, role := range rbg.Spec.Roles {
+		if roleNames[role.Name] {
+			allErrs = append(allErrs, field.Duplicate(rolesPath.Index(i).Child("name"), role.Name))
+		}
+		roleNames[role.Name] = true
+	}
+
+	for i := range rbg.Spec.Roles {
+		allErrs = append(allErrs, validateRole(&rbg.Spec.Roles[i], roleNames, allowExecProbes, rolesPath.Index(i))...)
+	}
+
+	// Only look for cycles once every dependency is known to exist.
+	if len(allErrs) == 0 {
+		if _, err := dependency.NewDefaultDependencyManager(nil, nil).SortRoles(ctx, rbg); err != nil {
+			allErrs = append(allErrs, field.Invalid(rolesPath, "dependencies", err.Error()))
+		}
+	}
+	return allErrs
+}
+
+func validatePodGroupPolicy(policy *workloadsv1alpha1.PodGroupPolicy, path *field.Path) field.ErrorList {
+	if policy == nil {
+		return nil
+	}
+	if policy.KubeScheduling != nil && policy.Volcano != nil {
+		return field.ErrorList{field.Forbidden(path, "only one of kubeScheduling and volcano may be specified")}
+	}
+	return nil
+}
+
+func validateRollbackTo(rbg *workloadsv1alpha1.RoleBasedGroup, path *field.Path) field.ErrorList {
+	rollbackTo := rbg.Spec.RollbackTo
+	if rollbackTo == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if rollbackTo.Revision < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("revision"), rollbackTo.Revision, "must be greater than or equal to 0"))
+	}
+	if rollbackTo.Revision != 0 && len(rollbackTo.Roles) == 0 {
+		// the revisions are numbered per role
+		allErrs = append(allErrs, field.Required(path.Child("roles"), "must be set when revision is set"))
+	}
+	for i, roleName := range rollbackTo.Roles {
+		if _, err := rbg.GetRole(roleName); err != nil {
+			allErrs = append(allErrs, field.NotFound(path.Child("roles").Index(i), roleName))
+		}
+	}
+	return allErrs
+}
+
+func validateGroupRolloutStrategy(rbg *workloadsv1alpha1.RoleBasedGroup, path *field.Path) field.ErrorList {
+	strategy := rbg.Spec.RolloutStrategy
+	if strategy == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	switch strategy.Type {
+	case workloadsv1alpha1.DependencyOrderRolloutStrategyType:
+		if len(strategy.Ratio) > 0 {
+			allErrs = append(allErrs, field.Forbidden(path.Child("ratio"), "only allowed for the Ratio strategy"))
+		}
+	case workloadsv1alpha1.RatioRolloutStrategyType:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), strategy.Type,
+			[]workloadsv1alpha1.GroupRolloutStrategyType{
+				workloadsv1alpha1.DependencyOrderRolloutStrategyType,
+				workloadsv1alpha1.RatioRolloutStrategyType,
+			}))
+	}
+
+	seen := make(map[string]bool, len(strategy.Ratio))
+	for i, ratio := range strategy.Ratio {
+		ratioPath := path.Child("ratio").Index(i)
+		if _, err := rbg.GetRole(ratio.Name); err != nil {
+			allErrs = append(allErrs, field.NotFound(ratioPath.Child("name"), ratio.Name))
+		} else if seen[ratio.Name] {
+			allErrs = append(allErrs, field.Duplicate(ratioPath.Child("name"), ratio.Name))
+		}
+		seen[ratio.Name] = true
+		if ratio.Replicas < 1 {
+			allErrs = append(allErrs, field.Invalid(ratioPath.Child("replicas"), ratio.Replicas, "must be greater than or equal to 1"))
+		}
+	}
+	return allErrs
+}
+
+func validateRestartStrategy(strategy *workloadsv1alpha1.RestartStrategy, path *field.Path) field.ErrorList {
+	if strategy == nil || strategy.MaxBackoffSeconds >= strategy.InitialBackoffSeconds {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(path.Child("maxBackoffSeconds"), strategy.MaxBackoffSeconds,
+		"must be greater than or equal to initialBackoffSeconds")}
+}
+
+func validateTopologyPolicy(policy *workloadsv1alpha1.TopologyPolicy, path *field.Path) field.ErrorList {
+	if policy == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	for _, msg := range validation.IsQualifiedName(policy.TopologyKey) {
+		allErrs = append(allErrs, field.Invalid(path.Child("topologyKey"), policy.TopologyKey, msg))
+	}
+	switch policy.Mode {
+	case workloadsv1alpha1.ColocateTopologyPolicyMode, workloadsv1alpha1.SpreadTopologyPolicyMode:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("mode"), policy.Mode,
+			[]workloadsv1alpha1.TopologyPolicyMode{
+				workloadsv1alpha1.ColocateTopologyPolicyMode,
+				workloadsv1alpha1.SpreadTopologyPolicyMode,
+			}))
+	}
+	return allErrs
+}
+
+func validatePriorityClassName(priorityClassName string, path *field.Path) field.ErrorList {
+	if priorityClassName == "" {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(priorityClassName) {
+		allErrs = append(allErrs, field.Invalid(path, priorityClassName, msg))
+	}
+	return allErrs
+}
+
+func validateDiscoveryConfig(config *workloadsv1alpha1.DiscoveryConfig, roleNames map[string]bool,
+	fldPath *field.Path) field.ErrorList {
+	if config == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	switch config.Format {
+	case "", workloadsv1alpha1.JSONDiscoveryConfigFormat, workloadsv1alpha1.YAMLDiscoveryConfigFormat,
+		workloadsv1alpha1.EnvDiscoveryConfigFormat:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("format"), config.Format,
+			[]workloadsv1alpha1.DiscoveryConfigFormat{
+				workloadsv1alpha1.JSONDiscoveryConfigFormat,
+				workloadsv1alpha1.YAMLDiscoveryConfigFormat,
+				workloadsv1alpha1.EnvDiscoveryConfigFormat,
+			}))
+	}
+	if config.MountPath != "" && !path.IsAbs(config.MountPath) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("mountPath"), config.MountPath, "must be an absolute path"))
+	}
+	if config.FileName != "" {
+		for _, msg := range validation.IsConfigMapKey(config.FileName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("fileName"), config.FileName, msg))
+		}
+	}
+	if config.Template != "" {
+		if config.FileName == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("fileName"), "required with a template"))
+		}
+		if _, err := discovery.ParseConfigTemplate(config.Template); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("template"), config.Template, err.Error()))
+		}
+	}
+	for i, name := range config.VisibleRoles {
+		if !roleNames[name] {
+			allErrs = append(allErrs, field.NotFound(fldPath.Child("visibleRoles").Index(i), name))
+		}
+	}
+	return allErrs
+}
+
+func validateRole(role *workloadsv1alpha1.RoleSpec, roleNames map[string]bool, allowExecProbes bool,
+	path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for j, dep := range role.Dependencies {
+		depPath := path.Child("dependencies").Index(j)
+		if dep == role.Name {
+			allErrs = append(allErrs, field.Invalid(depPath, dep, "role can not depend on itself"))
+		} else if !roleNames[dep] {
+			allErrs = append(allErrs, field.NotFound(depPath, dep))
+		}
+	}
+
+	allErrs = append(allErrs, validateDependencyReadiness(role, allowExecProbes, path.Child("dependencyReadiness"))...)
+	allErrs = append(allErrs, validatePriorityClassName(role.PriorityClassName, path.Child("priorityClassName"))...)
+	allErrs = append(allErrs, validateDiscoveryConfig(role.DiscoveryConfig, roleNames, path.Child("discoveryConfig"))...)
+
+	if !reconciler.IsSupportedWorkload(role.Workload) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("workload"), role.Workload.String(),
+			reconciler.SupportedWorkloads()))
+	}
+
+	if role.Replicas == nil {
+		allErrs = append(allErrs, field.Required(path.Child("replicas"), ""))
+		return allErrs
+	}
+
+	if role.MinAvailable != nil {
+		minAvailablePath := path.Child("minAvailable")
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(role.MinAvailable, int(*role.Replicas), true)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(minAvailablePath, role.MinAvailable.String(), err.Error()))
+		} else if minAvailable < 0 {
+			allErrs = append(allErrs, field.Invalid(minAvailablePath, role.MinAvailable.String(), "must be greater than or equal to 0"))
+		}
+	}
+
+	if role.RolloutStrategy != nil && role.RolloutStrategy.RollingUpdate != nil {
+		if _, err := reconciler.ValidateRolloutStrategy(role.RolloutStrategy, int(*role.Replicas)); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("rolloutStrategy", "rollingUpdate"),
+				role.RolloutStrategy.RollingUpdate, err.Error()))
+		}
+	}
+
+	if role.Workload.String() == workloadsv1alpha1.JobWorkloadType {
+		if role.Template.Spec.RestartPolicy == corev1.RestartPolicyAlways {
+			allErrs = append(allErrs, field.NotSupported(path.Child("template", "spec", "restartPolicy"),
+				role.Template.Spec.RestartPolicy, []corev1.RestartPolicy{corev1.RestartPolicyOnFailure, corev1.RestartPolicyNever}))
+		}
+		if role.ScalingAdapter != nil && role.ScalingAdapter.Enable {
+			allErrs = append(allErrs, field.Forbidden(path.Child("scalingAdapter"), "not supported by Job workload"))
+		}
+	}
+
+	if role.Workload.String() == workloadsv1alpha1.LeaderWorkerSetWorkloadType {
+		sizePath := path.Child("leaderWorkerSet", "size")
+		if role.LeaderWorkerSet.Size == nil {
+			allErrs = append(allErrs, field.Required(sizePath, "size is required for LeaderWorkerSet workload"))
+		} else if *role.LeaderWorkerSet.Size < 1 {
+			allErrs = append(allErrs, field.Invalid(sizePath, *role.LeaderWorkerSet.Size, "must be greater than or equal to 1"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateDependencyReadiness(role *workloadsv1alpha1.RoleSpec, allowExecProbes bool, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, readiness := range role.DependencyReadiness {
+		readinessPath := path.Index(i)
+		if !utils.ContainsString(role.Dependencies, readiness.Role) {
+			allErrs = append(allErrs, field.Invalid(readinessPath.Child("role"), readiness.Role, "must be a dependency of the role"))
+		}
+		if readiness.MinReady != nil {
+			// the replicas of the dependency only scale percentages, any of them validates the value
+			minReady, err := intstr.GetScaledValueFromIntOrPercent(readiness.MinReady, 100, true)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(readinessPath.Child("minReady"), readiness.MinReady.String(), err.Error()))
+			} else if minReady < 0 {
+				allErrs = append(allErrs, field.Invalid(readinessPath.Child("minReady"), readiness.MinReady.String(), "must be greater than or equal to 0"))
+			}
+		}
+		if readiness.Probe != nil {
+			allErrs = append(allErrs, validateDependencyProbe(readiness.Probe, allowExecProbes, readinessPath.Child("probe"))...)
+		}
+	}
+	return allErrs
+}
+
+func validateDependencyProbe(probe *workloadsv1alpha1.DependencyProbe, allowExecProbes bool, path *field.Path) field.ErrorList {
+	if probe.GRPC != nil {
+		return field.ErrorList{field.Forbidden(path.Child("grpc"), "not supported by dependency probes")}
+	}
+	handlers := 0
+	for _, set := range []bool{probe.HTTPGet != nil, probe.TCPSocket != nil, probe.Exec != nil} {
+		if set {
+			handlers++
+		}
+	}
+	if handlers != 1 {
+		return field.ErrorList{field.Invalid(path, handlers, "exactly one of httpGet, tcpSocket and exec must be specified")}
+	}
+
+	var allErrs field.ErrorList
+	// the probes are run by the controller, they must only target the pods of the dependency, or its Service
+	switch {
+	case probe.HTTPGet != nil && probe.HTTPGet.Host != "":
+		allErrs = append(allErrs, field.Forbidden(path.Child("httpGet", "host"), "dependency probes target the pod IP or the service"))
+	case probe.TCPSocket != nil && probe.TCPSocket.Host != "":
+		allErrs = append(allErrs, field.Forbidden(path.Child("tcpSocket", "host"), "dependency probes target the pod IP or the service"))
+	case probe.Exec != nil && !allowExecProbes:
+		allErrs = append(allErrs, field.Forbidden(path.Child("exec"), "exec dependency probes are not enabled in the controller"))
+	case probe.Exec != nil && probe.Service != "":
+		allErrs = append(allErrs, field.Invalid(path.Child("service"), probe.Service, "exec probes run in the pods of the dependency"))
+	}
+	if probe.Service != "" {
+		for _, msg := range validation.IsDNS1035Label(probe.Service) {
+			allErrs = append(allErrs, field.Invalid(path.Child("service"), probe.Service, msg))
+		}
+	}
+	return allErrs
+}
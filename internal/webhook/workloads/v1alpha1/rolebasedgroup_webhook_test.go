@@ -0,0 +1,545 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/kueue"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+func TestRoleBasedGroupCustomDefaulter_Default(t *testing.T) {
+	tests := []struct {
+		name              string
+		role              workloadsv1alpha1.RoleSpec
+		wantRestartPolicy workloadsv1alpha1.RestartPolicyType
+		wantMaxSurge      intstr.IntOrString
+		wantLwsSize       *int32
+	}{
+		{
+			name: "statefulset role",
+			role: workloadsv1alpha1.RoleSpec{
+				Name:     "sts",
+				Workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"},
+			},
+			wantRestartPolicy: workloadsv1alpha1.NoneRestartPolicy,
+			wantMaxSurge:      intstr.FromInt32(0),
+		},
+		{
+			name: "deployment role",
+			role: workloadsv1alpha1.RoleSpec{
+				Name:     "deploy",
+				Workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "Deployment"},
+			},
+			wantRestartPolicy: workloadsv1alpha1.NoneRestartPolicy,
+			wantMaxSurge:      intstr.FromString("25%"),
+		},
+		{
+			name: "leaderworkerset role",
+			role: workloadsv1alpha1.RoleSpec{
+				Name:     "lws",
+				Workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "leaderworkerset.x-k8s.io/v1", Kind: "LeaderWorkerSet"},
+			},
+			wantRestartPolicy: workloadsv1alpha1.RecreateRoleInstanceOnPodRestart,
+			wantMaxSurge:      intstr.FromInt32(0),
+			wantLwsSize:       ptr.To[int32](1),
+		},
+		{
+			name: "user values are kept",
+			role: workloadsv1alpha1.RoleSpec{
+				Name:          "lws",
+				Workload:      workloadsv1alpha1.WorkloadSpec{APIVersion: "leaderworkerset.x-k8s.io/v1", Kind: "LeaderWorkerSet"},
+				RestartPolicy: workloadsv1alpha1.RecreateRBGOnPodRestart,
+				RolloutStrategy: &workloadsv1alpha1.RolloutStrategy{
+					Type:          workloadsv1alpha1.RollingUpdateStrategyType,
+					RollingUpdate: &workloadsv1alpha1.RollingUpdate{MaxSurge: intstr.FromInt32(2)},
+				},
+				LeaderWorkerSet: workloadsv1alpha1.LeaderWorkerTemplate{Size: ptr.To[int32](4)},
+			},
+			wantRestartPolicy: workloadsv1alpha1.RecreateRBGOnPodRestart,
+			wantMaxSurge:      intstr.FromInt32(2),
+			wantLwsSize:       ptr.To[int32](4),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").
+				WithRoles([]workloadsv1alpha1.RoleSpec{tt.role}).Obj()
+			if err := (&RoleBasedGroupCustomDefaulter{}).Default(context.TODO(), rbg); err != nil {
+				t.Fatalf("Default() error = %v", err)
+			}
+
+			role := rbg.Spec.Roles[0]
+			if role.Replicas == nil || *role.Replicas != 1 {
+				t.Errorf("Default() replicas = %v, want 1", role.Replicas)
+			}
+			if role.RestartPolicy != tt.wantRestartPolicy {
+				t.Errorf("Default() restartPolicy = %s, want %s", role.RestartPolicy, tt.wantRestartPolicy)
+			}
+			if role.RolloutStrategy == nil || role.RolloutStrategy.RollingUpdate == nil {
+				t.Fatalf("Default() rolloutStrategy not set")
+			}
+			if role.RolloutStrategy.RollingUpdate.MaxSurge != tt.wantMaxSurge {
+				t.Errorf("Default() maxSurge = %v, want %v", role.RolloutStrategy.RollingUpdate.MaxSurge, tt.wantMaxSurge)
+			}
+			if tt.wantLwsSize != nil && *role.LeaderWorkerSet.Size != *tt.wantLwsSize {
+				t.Errorf("Default() lws size = %d, want %d", *role.LeaderWorkerSet.Size, *tt.wantLwsSize)
+			}
+		})
+	}
+}
+
+func TestRoleBasedGroupCustomValidator_ValidateCreate(t *testing.T) {
+	lwsRole := wrappers.BuildLwsRole("lws").Obj()
+	lwsRole.LeaderWorkerSet.Size = nil
+	discoveryRole := func(config workloadsv1alpha1.DiscoveryConfig) workloadsv1alpha1.RoleSpec {
+		role := wrappers.BuildBasicRole("worker").Obj()
+		role.DiscoveryConfig = &config
+		return role
+	}
+
+	tests := []struct {
+		name       string
+		roles      []workloadsv1alpha1.RoleSpec
+		policy     *workloadsv1alpha1.PodGroupPolicy
+		rollbackTo *workloadsv1alpha1.RollbackConfig
+		rollout    *workloadsv1alpha1.GroupRolloutStrategy
+		restart    *workloadsv1alpha1.RestartStrategy
+		topology   *workloadsv1alpha1.TopologyPolicy
+		queueName  string
+		preemption workloadsv1alpha1.PreemptionPolicyType
+		allowExec  bool
+		wantErr    bool
+		wantFields []string
+	}{
+		{
+			name: "valid roles",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("router").WithDependencies([]string{"worker"}).Obj(),
+				wrappers.BuildBasicRole("worker").Obj(),
+			},
+			wantErr: false,
+		},
+		{
+			name: "duplicate role name",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("worker").Obj(),
+				wrappers.BuildBasicRole("worker").Obj(),
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.roles[1].name"},
+		},
+		{
+			name: "unknown dependency",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("router").WithDependencies([]string{"worker"}).Obj(),
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.roles[0].dependencies[0]"},
+		},
+		{
+			name: "dependency cycle",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("a").WithDependencies([]string{"b"}).Obj(),
+				wrappers.BuildBasicRole("b").WithDependencies([]string{"a"}).Obj(),
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.roles"},
+		},
+		{
+			name: "unsupported workload",
+			roles: []workloadsv1alpha1.RoleSpec{
+				func() workloadsv1alpha1.RoleSpec {
+					role := wrappers.BuildBasicRole("worker").Obj()
+					role.Workload = workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "DaemonSet"}
+					return role
+				}(),
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.roles[0].workload"},
+		},
+		{
+			name: "invalid rollout strategy",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("worker").WithRollingUpdate(workloadsv1alpha1.RollingUpdate{
+					MaxUnavailable: intstr.FromInt32(0),
+					MaxSurge:       intstr.FromInt32(0),
+				}).Obj(),
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.roles[0].rolloutStrategy.rollingUpdate"},
+		},
+		{
+			name:       "missing lws size",
+			roles:      []workloadsv1alpha1.RoleSpec{lwsRole},
+			wantErr:    true,
+			wantFields: []string{"spec.roles[0].leaderWorkerSet.size"},
+		},
+		{
+			name: "job restarted always",
+			roles: []workloadsv1alpha1.RoleSpec{
+				func() workloadsv1alpha1.RoleSpec {
+					role := wrappers.BuildBasicRole("model-download").WithWorkload(workloadsv1alpha1.JobWorkloadType).Obj()
+					role.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+					return role
+				}(),
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.roles[0].template.spec.restartPolicy"},
+		},
+		{
+			name: "invalid min available",
+			roles: []workloadsv1alpha1.RoleSpec{
+				func() workloadsv1alpha1.RoleSpec {
+					role := wrappers.BuildBasicRole("worker").Obj()
+					role.MinAvailable = ptr.To(intstr.FromString("half"))
+					return role
+				}(),
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.roles[0].minAvailable"},
+		},
+		{
+			name: "dependency readiness",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("decode").WithDependencies([]string{"prefill"}).
+					WithDependencyReadiness(workloadsv1alpha1.DependencyReadiness{
+						Role:     "prefill",
+						MinReady: ptr.To(intstr.FromString("25%")),
+						Probe: &workloadsv1alpha1.DependencyProbe{ProbeHandler: corev1.ProbeHandler{
+							HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromInt32(8000)},
+						}},
+					}).Obj(),
+				wrappers.BuildBasicRole("prefill").Obj(),
+			},
+			wantErr: false,
+		},
+		{
+			name: "readiness of role not depended on",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("decode").
+					WithDependencyReadiness(workloadsv1alpha1.DependencyReadiness{Role: "prefill"}).Obj(),
+				wrappers.BuildBasicRole("prefill").Obj(),
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.roles[0].dependencyReadiness[0].role"},
+		},
+		{
+			name: "dependency probe with host",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("decode").WithDependencies([]string{"prefill", "router"}).
+					WithDependencyReadiness(workloadsv1alpha1.DependencyReadiness{
+						Role: "prefill",
+						Probe: &workloadsv1alpha1.DependencyProbe{ProbeHandler: corev1.ProbeHandler{
+							HTTPGet: &corev1.HTTPGetAction{Host: "169.254.169.254", Port: intstr.FromInt32(80)},
+						}},
+					}, workloadsv1alpha1.DependencyReadiness{
+						Role: "router",
+						Probe: &workloadsv1alpha1.DependencyProbe{ProbeHandler: corev1.ProbeHandler{
+							Exec: &corev1.ExecAction{Command: []string{"true"}},
+						}},
+					}).Obj(),
+				wrappers.BuildBasicRole("prefill").Obj(),
+				wrappers.BuildBasicRole("router").Obj(),
+			},
+			wantErr: true,
+			wantFields: []string{
+				"spec.roles[0].dependencyReadiness[0].probe.httpGet.host",
+				"spec.roles[0].dependencyReadiness[1].probe.exec",
+			},
+		},
+		{
+			name: "exec and service dependency probes",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("decode").WithDependencies([]string{"prefill", "router"}).
+					WithDependencyReadiness(workloadsv1alpha1.DependencyReadiness{
+						Role: "prefill",
+						Probe: &workloadsv1alpha1.DependencyProbe{ProbeHandler: corev1.ProbeHandler{
+							Exec: &corev1.ExecAction{Command: []string{"true"}},
+						}},
+					}, workloadsv1alpha1.DependencyReadiness{
+						Role: "router",
+						Probe: &workloadsv1alpha1.DependencyProbe{ProbeHandler: corev1.ProbeHandler{
+							HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromString("http")},
+						}, Service: "router"},
+					}).Obj(),
+				wrappers.BuildBasicRole("prefill").Obj(),
+				wrappers.BuildBasicRole("router").Obj(),
+			},
+			allowExec: true,
+			wantErr:   false,
+		},
+		{
+			name: "exec dependency probe against a service",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("decode").WithDependencies([]string{"prefill", "router"}).
+					WithDependencyReadiness(workloadsv1alpha1.DependencyReadiness{
+						Role: "prefill",
+						Probe: &workloadsv1alpha1.DependencyProbe{ProbeHandler: corev1.ProbeHandler{
+							Exec: &corev1.ExecAction{Command: []string{"true"}},
+						}, Service: "prefill"},
+					}, workloadsv1alpha1.DependencyReadiness{
+						Role: "router",
+						Probe: &workloadsv1alpha1.DependencyProbe{ProbeHandler: corev1.ProbeHandler{
+							TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(80)},
+						}, Service: "Router.default"},
+					}).Obj(),
+				wrappers.BuildBasicRole("prefill").Obj(),
+				wrappers.BuildBasicRole("router").Obj(),
+			},
+			allowExec: true,
+			wantErr:   true,
+			wantFields: []string{
+				"spec.roles[0].dependencyReadiness[0].probe.service",
+				"spec.roles[0].dependencyReadiness[1].probe.service",
+			},
+		},
+		{
+			name: "dependency probe without handler",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("decode").WithDependencies([]string{"prefill"}).
+					WithDependencyReadiness(workloadsv1alpha1.DependencyReadiness{
+						Role:     "prefill",
+						MinReady: ptr.To(intstr.FromString("one")),
+						Probe:    &workloadsv1alpha1.DependencyProbe{},
+					}).Obj(),
+				wrappers.BuildBasicRole("prefill").Obj(),
+			},
+			wantErr: true,
+			wantFields: []string{
+				"spec.roles[0].dependencyReadiness[0].minReady",
+				"spec.roles[0].dependencyReadiness[0].probe",
+			},
+		},
+		{
+			name:  "volcano gang-scheduling",
+			roles: []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("worker").Obj()},
+			policy: &workloadsv1alpha1.PodGroupPolicy{
+				PodGroupPolicySource: workloadsv1alpha1.PodGroupPolicySource{
+					Volcano: &workloadsv1alpha1.VolcanoPodGroupPolicySource{Queue: "default"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "multiple gang-schedulers",
+			roles: []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("worker").Obj()},
+			policy: &workloadsv1alpha1.PodGroupPolicy{
+				PodGroupPolicySource: workloadsv1alpha1.PodGroupPolicySource{
+					KubeScheduling: &workloadsv1alpha1.KubeSchedulingPodGroupPolicySource{},
+					Volcano:        &workloadsv1alpha1.VolcanoPodGroupPolicySource{},
+				},
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.podGroupPolicy"},
+		},
+		{
+			name:       "rollback to previous revision",
+			roles:      []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("worker").Obj()},
+			rollbackTo: &workloadsv1alpha1.RollbackConfig{Roles: []string{"worker"}},
+			wantErr:    false,
+		},
+		{
+			name:       "rollback unknown role",
+			roles:      []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("worker").Obj()},
+			rollbackTo: &workloadsv1alpha1.RollbackConfig{Revision: 2, Roles: []string{"router"}},
+			wantErr:    true,
+			wantFields: []string{"spec.rollbackTo.roles[0]"},
+		},
+		{
+			name:       "rollback to a revision of all roles",
+			roles:      []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("worker").Obj()},
+			rollbackTo: &workloadsv1alpha1.RollbackConfig{Revision: 2},
+			wantErr:    true,
+			wantFields: []string{"spec.rollbackTo.roles"},
+		},
+		{
+			name: "ratio rollout",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("prefill").Obj(),
+				wrappers.BuildBasicRole("decode").Obj(),
+			},
+			rollout: &workloadsv1alpha1.GroupRolloutStrategy{
+				Type: workloadsv1alpha1.RatioRolloutStrategyType,
+				Ratio: []workloadsv1alpha1.RoleRolloutRatio{
+					{Name: "prefill", Replicas: 1},
+					{Name: "decode", Replicas: 2},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "ratio of unknown role",
+			roles: []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("prefill").Obj()},
+			rollout: &workloadsv1alpha1.GroupRolloutStrategy{
+				Type:  workloadsv1alpha1.RatioRolloutStrategyType,
+				Ratio: []workloadsv1alpha1.RoleRolloutRatio{{Name: "decode", Replicas: 2}},
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.rolloutStrategy.ratio[0].name"},
+		},
+		{
+			name:  "ratio with dependency order",
+			roles: []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("prefill").Obj()},
+			rollout: &workloadsv1alpha1.GroupRolloutStrategy{
+				Type:  workloadsv1alpha1.DependencyOrderRolloutStrategyType,
+				Ratio: []workloadsv1alpha1.RoleRolloutRatio{{Name: "prefill", Replicas: 1}},
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.rolloutStrategy.ratio"},
+		},
+		{
+			name:  "restart strategy",
+			roles: []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("worker").Obj()},
+			restart: &workloadsv1alpha1.RestartStrategy{
+				MaxRestarts: 5, WindowSeconds: 600, InitialBackoffSeconds: 10, MaxBackoffSeconds: 60,
+			},
+			wantErr: false,
+		},
+		{
+			name:  "max backoff below initial backoff",
+			roles: []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("worker").Obj()},
+			restart: &workloadsv1alpha1.RestartStrategy{
+				WindowSeconds: 600, InitialBackoffSeconds: 60, MaxBackoffSeconds: 10,
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.restartStrategy.maxBackoffSeconds"},
+		},
+		{
+			name:  "colocate topology policy",
+			roles: []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("worker").Obj()},
+			topology: &workloadsv1alpha1.TopologyPolicy{
+				TopologyKey: "topology.kubernetes.io/zone", Mode: workloadsv1alpha1.ColocateTopologyPolicyMode,
+			},
+			wantErr: false,
+		},
+		{
+			name:  "invalid topology policy",
+			roles: []workloadsv1alpha1.RoleSpec{wrappers.BuildBasicRole("worker").Obj()},
+			topology: &workloadsv1alpha1.TopologyPolicy{
+				TopologyKey: "rack/zone/", Mode: "Pack",
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.topologyPolicy.topologyKey", "spec.topologyPolicy.mode"},
+		},
+		{
+			name: "role priority class",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("worker").WithPriorityClassName("high-priority").Obj(),
+			},
+			preemption: workloadsv1alpha1.NonePreemptionPolicy,
+			wantErr:    false,
+		},
+		{
+			name: "invalid priority class and preemption policy",
+			roles: []workloadsv1alpha1.RoleSpec{
+				wrappers.BuildBasicRole("worker").WithPriorityClassName("High_Priority").Obj(),
+			},
+			preemption: "EvictPod",
+			wantErr:    true,
+			wantFields: []string{"spec.roles[0].priorityClassName", "spec.preemptionPolicy"},
+		},
+		{
+			name: "discovery config template",
+			roles: []workloadsv1alpha1.RoleSpec{discoveryRole(workloadsv1alpha1.DiscoveryConfig{
+				MountPath: "/etc/mooncake",
+				FileName:  "mooncake.json",
+				Template:  `{"workers": {{ toJson (index .Roles "worker").Instances }}}`,
+			})},
+			wantErr: false,
+		},
+		{
+			name: "invalid discovery config",
+			roles: []workloadsv1alpha1.RoleSpec{discoveryRole(workloadsv1alpha1.DiscoveryConfig{
+				Format:    "toml",
+				MountPath: "etc/rbg",
+				Template:  `{{ .Group.Name `,
+			})},
+			wantErr: true,
+			wantFields: []string{
+				"spec.roles[0].discoveryConfig.format",
+				"spec.roles[0].discoveryConfig.mountPath",
+				"spec.roles[0].discoveryConfig.fileName",
+				"spec.roles[0].discoveryConfig.template",
+			},
+		},
+		{
+			name: "discovery config visible roles",
+			roles: []workloadsv1alpha1.RoleSpec{
+				discoveryRole(workloadsv1alpha1.DiscoveryConfig{VisibleRoles: []string{"worker", "router"}}),
+				wrappers.BuildBasicRole("router").Obj(),
+			},
+			wantErr: false,
+		},
+		{
+			name: "discovery config visible role not found",
+			roles: []workloadsv1alpha1.RoleSpec{
+				discoveryRole(workloadsv1alpha1.DiscoveryConfig{VisibleRoles: []string{"worker", "scheduler"}}),
+			},
+			wantErr:    true,
+			wantFields: []string{"spec.roles[0].discoveryConfig.visibleRoles[1]"},
+		},
+		{
+			name:       "too many roles for kueue",
+			roles:      manyRoles(kueue.MaxPodSets + 1),
+			queueName:  "gpu",
+			wantErr:    true,
+			wantFields: []string{"spec.roles"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles(tt.roles).Obj()
+			rbg.Spec.PodGroupPolicy = tt.policy
+			rbg.Spec.RollbackTo = tt.rollbackTo
+			rbg.Spec.RolloutStrategy = tt.rollout
+			rbg.Spec.RestartStrategy = tt.restart
+			rbg.Spec.TopologyPolicy = tt.topology
+			rbg.Spec.PreemptionPolicy = tt.preemption
+			if tt.queueName != "" {
+				rbg.Labels = map[string]string{workloadsv1alpha1.QueueNameLabelKey: tt.queueName}
+			}
+			_, err := (&RoleBasedGroupCustomValidator{allowExecProbes: tt.allowExec}).ValidateCreate(context.TODO(), rbg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, f := range tt.wantFields {
+				if !strings.Contains(err.Error(), f) {
+					t.Errorf("ValidateCreate() error = %v, want field %s", err, f)
+				}
+			}
+		})
+	}
+}
+
+func manyRoles(n int) []workloadsv1alpha1.RoleSpec {
+	roles := make([]workloadsv1alpha1.RoleSpec, 0, n)
+	for i := range n {
+		roles = append(roles, wrappers.BuildBasicRole(fmt.Sprintf("role-%d", i)).Obj())
+	}
+	return roles
+}
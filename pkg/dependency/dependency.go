@@ -4,18 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	lwsv1 "sigs.k8s.io/lws/api/leaderworkerset/v1"
 	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 	"sigs.k8s.io/rbgs/pkg/reconciler"
 	"sigs.k8s.io/rbgs/pkg/utils"
 	"sort"
+	"sync/atomic"
+	"time"
 )
 
+// maxConcurrentProbes is the number of pods of a dependency probed concurrently.
+const maxConcurrentProbes = 16
+
 type DefaultDependencyManager struct {
 	scheme *runtime.Scheme
 	client client.Client
+	prober Prober
 }
 
 var _ DependencyManager = &DefaultDependencyManager{}
@@ -24,6 +37,11 @@ func NewDefaultDependencyManager(scheme *runtime.Scheme, client client.Client) *
 	return &DefaultDependencyManager{scheme: scheme, client: client}
 }
 
+// SetProber sets the prober running the probes of the dependency readiness.
+func (m *DefaultDependencyManager) SetProber(prober Prober) {
+	m.prober = prober
+}
+
 func (m *DefaultDependencyManager) SortRoles(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) ([]*workloadsv1alpha.RoleSpec, error) {
 	logger := log.FromContext(ctx)
 	if len(rbg.Spec.Roles) == 0 {
@@ -70,6 +88,33 @@ func (m *DefaultDependencyManager) SortRoles(ctx context.Context, rbg *workloads
 
 }
 
+func (m *DefaultDependencyManager) LevelRoles(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) ([][]*workloadsv1alpha.RoleSpec, error) {
+	sortedRoles, err := m.SortRoles(ctx, rbg)
+	if err != nil {
+		return nil, err
+	}
+	return levelRoles(sortedRoles), nil
+}
+
+// levelRoles groups roles sorted in dependency order by their level, which is
+// one more than the highest level of their dependencies.
+func levelRoles(sortedRoles []*workloadsv1alpha.RoleSpec) [][]*workloadsv1alpha.RoleSpec {
+	levels := make(map[string]int, len(sortedRoles))
+	var ret [][]*workloadsv1alpha.RoleSpec
+	for _, role := range sortedRoles {
+		level := 0
+		for _, dep := range role.Dependencies {
+			level = max(level, levels[dep]+1)
+		}
+		levels[role.Name] = level
+		if level == len(ret) {
+			ret = append(ret, nil)
+		}
+		ret[level] = append(ret[level], role)
+	}
+	return ret
+}
+
 func (m *DefaultDependencyManager) CheckDependencyReady(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup, role *workloadsv1alpha.RoleSpec) (bool, error) {
 
 	for _, dep := range role.Dependencies {
@@ -77,15 +122,26 @@ func (m *DefaultDependencyManager) CheckDependencyReady(ctx context.Context, rbg
 		if err != nil {
 			return false, err
 		}
-		r, err := reconciler.NewWorkloadReconciler(depRole.Workload, m.scheme, m.client)
-		if err != nil {
-			return false, err
+		var ready bool
+		if readiness := role.GetDependencyReadiness(dep); readiness != nil {
+			ready, err = m.checkDependencyReadiness(ctx, rbg, depRole, readiness)
+		} else {
+			var r reconciler.WorkloadReconciler
+			r, err = reconciler.NewWorkloadReconciler(depRole.Workload, m.scheme, m.client)
+			if err != nil {
+				return false, err
+			}
+			ready, err = r.CheckWorkloadReady(ctx, rbg, depRole)
+		}
+		if apierrors.IsNotFound(err) {
+			// the workload of the dependency has not been created yet
+			ready, err = false, nil
 		}
-		ready, err := r.CheckWorkloadReady(ctx, rbg, depRole)
 		if err != nil {
 			return false, err
 		}
 		if !ready {
+			log.FromContext(ctx).V(1).Info("dependency not ready", "dependency", dep)
 			return false, nil
 		}
 	}
@@ -93,6 +149,166 @@ func (m *DefaultDependencyManager) CheckDependencyReady(ctx context.Context, rbg
 	return true, nil
 }
 
+// checkDependencyReadiness checks whether enough pods of depRole meet the readiness condition.
+func (m *DefaultDependencyManager) checkDependencyReadiness(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup,
+	depRole *workloadsv1alpha.RoleSpec, readiness *workloadsv1alpha.DependencyReadiness) (bool, error) {
+	minReady, err := minReadyReplicas(depRole, readiness)
+	if err != nil {
+		return false, err
+	}
+	if minReady == 0 {
+		return true, nil
+	}
+
+	labels := client.MatchingLabels{
+		workloadsv1alpha.SetNameLabelKey: rbg.Name,
+		workloadsv1alpha.SetRoleLabelKey: depRole.Name,
+	}
+	if depRole.Workload.String() == workloadsv1alpha.LeaderWorkerSetWorkloadType {
+		// the replicas of a LeaderWorkerSet are its groups, each one is counted by its leader pod
+		labels[lwsv1.WorkerIndexLabelKey] = "0"
+	}
+	podList := &corev1.PodList{}
+	if err := m.client.List(ctx, podList, client.InNamespace(rbg.Namespace), labels); err != nil {
+		return false, err
+	}
+
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if utils.PodDeleted(pod) || !podConditionReady(pod, readiness.PodConditionType) {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	if int32(len(pods)) < minReady {
+		return false, nil
+	}
+
+	switch {
+	case readiness.Probe == nil:
+		return true, nil
+	case readiness.Probe.Service != "":
+		return m.probeService(ctx, rbg, readiness.Probe)
+	default:
+		return m.probePods(ctx, pods, minReady, readiness.Probe)
+	}
+}
+
+// probePods runs probe against pods concurrently, within the timeout of the probe overall,
+// and checks whether minReady of them pass.
+func (m *DefaultDependencyManager) probePods(ctx context.Context, pods []*corev1.Pod, minReady int32,
+	probe *workloadsv1alpha.DependencyProbe) (bool, error) {
+	if m.prober == nil {
+		return false, fmt.Errorf("no prober to run the readiness probe of pod %s", pods[0].Name)
+	}
+	timeout := probeTimeout(probe)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var passed atomic.Int32
+	errs := make([]error, len(pods))
+	workqueue.ParallelizeUntil(ctx, maxConcurrentProbes, len(pods), func(i int) {
+		ok, err := m.prober.Probe(ctx, pods[i], &probe.ProbeHandler, timeout)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		if ok && passed.Add(1) >= minReady {
+			// enough pods passed, the remaining probes are not needed
+			cancel()
+		}
+	})
+	if passed.Load() >= minReady {
+		return true, nil
+	}
+	return false, errors.Join(errs...)
+}
+
+// probeService runs probe once against the Service it names.
+func (m *DefaultDependencyManager) probeService(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup,
+	probe *workloadsv1alpha.DependencyProbe) (bool, error) {
+	if m.prober == nil {
+		return false, fmt.Errorf("no prober to run the readiness probe of service %s", probe.Service)
+	}
+	svc := &corev1.Service{}
+	if err := m.client.Get(ctx, types.NamespacedName{Namespace: rbg.Namespace, Name: probe.Service}, svc); err != nil {
+		return false, err
+	}
+	return m.prober.ProbeService(ctx, svc, &probe.ProbeHandler, probeTimeout(probe))
+}
+
+func probeTimeout(probe *workloadsv1alpha.DependencyProbe) time.Duration {
+	if probe.TimeoutSeconds > 0 {
+		return time.Duration(probe.TimeoutSeconds) * time.Second
+	}
+	return time.Second
+}
+
+// podConditionReady checks the pod condition of conditionType, or whether the pod is ready if it is empty.
+func podConditionReady(pod *corev1.Pod, conditionType corev1.PodConditionType) bool {
+	if conditionType == "" {
+		return utils.PodRunningAndReady(*pod)
+	}
+	return utils.PodConditionTrue(*pod, conditionType)
+}
+
+// minReadyReplicas returns the number of ready replicas of depRole the readiness requires,
+// rounding percentages up. All the replicas are required by default.
+func minReadyReplicas(depRole *workloadsv1alpha.RoleSpec, readiness *workloadsv1alpha.DependencyReadiness) (int32, error) {
+	replicas := int32(1)
+	if depRole.Replicas != nil {
+		replicas = *depRole.Replicas
+	}
+	if readiness.MinReady == nil {
+		return replicas, nil
+	}
+	minReady, err := intstr.GetScaledValueFromIntOrPercent(readiness.MinReady, int(replicas), true)
+	if err != nil {
+		return 0, err
+	}
+	if int32(minReady) > replicas {
+		return replicas, nil
+	}
+	return int32(minReady), nil
+}
+
+// DependentRoles returns roleName and the roles of rbg depending on it, directly or transitively.
+func DependentRoles(rbg *workloadsv1alpha.RoleBasedGroup, roleName string) sets.Set[string] {
+	dependents := sets.New(roleName)
+	for changed := true; changed; {
+		changed = false
+		for _, role := range rbg.Spec.Roles {
+			if dependents.Has(role.Name) {
+				continue
+			}
+			for _, dep := range role.Dependencies {
+				if dependents.Has(dep) {
+					dependents.Insert(role.Name)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return dependents
+}
+
+// SortRoleNames sorts the roles of dependencies, which maps the roles to their dependencies, in dependency order.
+// The dependencies on roles missing from dependencies are ignored.
+func SortRoleNames(ctx context.Context, dependencies map[string][]string) ([]string, error) {
+	known := make(map[string][]string, len(dependencies))
+	for role, deps := range dependencies {
+		known[role] = []string{}
+		for _, dep := range deps {
+			if _, ok := dependencies[dep]; ok {
+				known[role] = append(known[role], dep)
+			}
+		}
+	}
+	return dependencyOrder(ctx, known)
+}
+
 // 基于DFS构建拓扑关系，判断是否存在环
 func dependencyOrder(ctx context.Context, dependencies map[string][]string) ([]string, error) {
 	logger := log.FromContext(ctx)
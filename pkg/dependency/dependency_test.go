@@ -2,10 +2,24 @@ package dependency
 
 import (
 	"context"
-	"k8s.io/klog/v2"
+	"fmt"
 	"reflect"
-	"sigs.k8s.io/controller-runtime/pkg/log"
 	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	lwsv1 "sigs.k8s.io/lws/api/leaderworkerset/v1"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
 )
 
 // TestDependencyOrder tests the DependencyOrder function with various dependency scenarios
@@ -82,3 +96,302 @@ func TestDependencyOrder(t *testing.T) {
 		})
 	}
 }
+
+// fakeProber passes the probe of the pods and services in passed.
+type fakeProber struct {
+	passed map[string]bool
+}
+
+func (p *fakeProber) Probe(_ context.Context, pod *corev1.Pod, _ *corev1.ProbeHandler, _ time.Duration) (bool, error) {
+	return p.passed[pod.Name], nil
+}
+
+func (p *fakeProber) ProbeService(_ context.Context, svc *corev1.Service, _ *corev1.ProbeHandler, _ time.Duration) (bool, error) {
+	return p.passed[svc.Name], nil
+}
+
+func buildLeaderPod(name, workerIndex string) *corev1.Pod {
+	pod := buildDependencyPod(name, true)
+	pod.Labels[lwsv1.WorkerIndexLabelKey] = workerIndex
+	return pod
+}
+
+func buildDependencyPod(name string, ready bool, conditions ...corev1.PodConditionType) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				workloadsv1alpha.SetNameLabelKey: "test-rbg",
+				workloadsv1alpha.SetRoleLabelKey: "prefill",
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if ready {
+		conditions = append(conditions, corev1.PodReady)
+	}
+	for _, condition := range conditions {
+		pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{Type: condition, Status: corev1.ConditionTrue})
+	}
+	return pod
+}
+
+func TestCheckDependencyReadiness(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = workloadsv1alpha.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	tests := []struct {
+		name      string
+		readiness workloadsv1alpha.DependencyReadiness
+		lws       bool
+		pods      []*corev1.Pod
+		services  []*corev1.Service
+		passed    map[string]bool
+		want      bool
+	}{
+		{
+			name:      "min ready count met",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill", MinReady: ptr.To(intstr.FromInt32(1))},
+			pods:      []*corev1.Pod{buildDependencyPod("prefill-0", true), buildDependencyPod("prefill-1", false)},
+			want:      true,
+		},
+		{
+			name:      "min ready percentage not met",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill", MinReady: ptr.To(intstr.FromString("50%"))},
+			pods:      []*corev1.Pod{buildDependencyPod("prefill-0", true), buildDependencyPod("prefill-1", false)},
+			want:      false,
+		},
+		{
+			name:      "all replicas by default",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill"},
+			pods: []*corev1.Pod{
+				buildDependencyPod("prefill-0", true), buildDependencyPod("prefill-1", true),
+				buildDependencyPod("prefill-2", true), buildDependencyPod("prefill-3", false),
+			},
+			want: false,
+		},
+		{
+			name:      "pod condition type",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill", MinReady: ptr.To(intstr.FromInt32(1)), PodConditionType: "ModelLoaded"},
+			pods:      []*corev1.Pod{buildDependencyPod("prefill-0", true), buildDependencyPod("prefill-1", false, "ModelLoaded")},
+			want:      true,
+		},
+		{
+			name: "probe failed",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill", MinReady: ptr.To(intstr.FromInt32(1)),
+				Probe: &workloadsv1alpha.DependencyProbe{}},
+			pods:   []*corev1.Pod{buildDependencyPod("prefill-0", true), buildDependencyPod("prefill-1", true)},
+			passed: map[string]bool{},
+			want:   false,
+		},
+		{
+			name: "probe passed",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill", MinReady: ptr.To(intstr.FromInt32(1)),
+				Probe: &workloadsv1alpha.DependencyProbe{}},
+			pods:   []*corev1.Pod{buildDependencyPod("prefill-0", true), buildDependencyPod("prefill-1", true)},
+			passed: map[string]bool{"prefill-1": true},
+			want:   true,
+		},
+		{
+			name: "probes of all the pods",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill", MinReady: ptr.To(intstr.FromInt32(3)),
+				Probe: &workloadsv1alpha.DependencyProbe{}},
+			pods: []*corev1.Pod{
+				buildDependencyPod("prefill-0", true), buildDependencyPod("prefill-1", true),
+				buildDependencyPod("prefill-2", true), buildDependencyPod("prefill-3", true),
+			},
+			passed: map[string]bool{"prefill-0": true, "prefill-2": true, "prefill-3": true},
+			want:   true,
+		},
+		{
+			name:      "lws workers not counted",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill"},
+			lws:       true,
+			pods: []*corev1.Pod{
+				buildLeaderPod("prefill-0", "0"), buildLeaderPod("prefill-0-1", "1"), buildLeaderPod("prefill-0-2", "2"),
+			},
+			want: false,
+		},
+		{
+			name:      "lws leaders counted",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill"},
+			lws:       true,
+			pods: []*corev1.Pod{
+				buildLeaderPod("prefill-0", "0"), buildLeaderPod("prefill-0-1", "1"), buildLeaderPod("prefill-1", "0"),
+			},
+			want: true,
+		},
+		{
+			name: "service probe passed",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill", MinReady: ptr.To(intstr.FromInt32(1)),
+				Probe: &workloadsv1alpha.DependencyProbe{Service: "prefill"}},
+			pods:     []*corev1.Pod{buildDependencyPod("prefill-0", true)},
+			services: []*corev1.Service{{ObjectMeta: metav1.ObjectMeta{Name: "prefill", Namespace: "default"}}},
+			passed:   map[string]bool{"prefill": true},
+			want:     true,
+		},
+		{
+			name: "service probe before min ready",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill", MinReady: ptr.To(intstr.FromInt32(2)),
+				Probe: &workloadsv1alpha.DependencyProbe{Service: "prefill"}},
+			pods:     []*corev1.Pod{buildDependencyPod("prefill-0", true), buildDependencyPod("prefill-1", false)},
+			services: []*corev1.Service{{ObjectMeta: metav1.ObjectMeta{Name: "prefill", Namespace: "default"}}},
+			passed:   map[string]bool{"prefill": true},
+			want:     false,
+		},
+		{
+			name: "service not found",
+			readiness: workloadsv1alpha.DependencyReadiness{Role: "prefill", MinReady: ptr.To(intstr.FromInt32(1)),
+				Probe: &workloadsv1alpha.DependencyProbe{Service: "prefill"}},
+			pods:   []*corev1.Pod{buildDependencyPod("prefill-0", true)},
+			passed: map[string]bool{"prefill": true},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			depRole := wrappers.BuildBasicRole("prefill").WithReplicas(4).Obj()
+			if tt.lws {
+				depRole = wrappers.BuildLwsRole("prefill").WithReplicas(2).Obj()
+			}
+			rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha.RoleSpec{
+				wrappers.BuildBasicRole("decode").WithDependencies([]string{"prefill"}).
+					WithDependencyReadiness(tt.readiness).Obj(),
+				depRole,
+			}).Obj()
+			objs := make([]client.Object, 0, len(tt.pods)+len(tt.services))
+			for _, pod := range tt.pods {
+				objs = append(objs, pod)
+			}
+			for _, svc := range tt.services {
+				objs = append(objs, svc)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			manager := NewDefaultDependencyManager(scheme, fakeClient)
+			manager.SetProber(&fakeProber{passed: tt.passed})
+
+			got, err := manager.CheckDependencyReady(context.TODO(), rbg, &rbg.Spec.Roles[0])
+			if err != nil {
+				t.Fatalf("CheckDependencyReady() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckDependencyReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_minReadyReplicas(t *testing.T) {
+	tests := []struct {
+		replicas int32
+		minReady *intstr.IntOrString
+		want     int32
+	}{
+		{replicas: 4, minReady: nil, want: 4},
+		{replicas: 4, minReady: ptr.To(intstr.FromInt32(1)), want: 1},
+		{replicas: 4, minReady: ptr.To(intstr.FromInt32(8)), want: 4},
+		{replicas: 4, minReady: ptr.To(intstr.FromString("30%")), want: 2},
+		{replicas: 0, minReady: ptr.To(intstr.FromString("30%")), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d replicas %v", tt.replicas, tt.minReady), func(t *testing.T) {
+			role := wrappers.BuildBasicRole("prefill").WithReplicas(tt.replicas).Obj()
+			got, err := minReadyReplicas(&role, &workloadsv1alpha.DependencyReadiness{Role: "prefill", MinReady: tt.minReady})
+			if err != nil {
+				t.Fatalf("minReadyReplicas() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("minReadyReplicas() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelRoles(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []workloadsv1alpha.RoleSpec
+		want  [][]string
+	}{
+		{
+			name: "independent roles",
+			roles: []workloadsv1alpha.RoleSpec{
+				wrappers.BuildBasicRole("b").Obj(),
+				wrappers.BuildBasicRole("a").Obj(),
+			},
+			want: [][]string{{"a", "b"}},
+		},
+		{
+			name: "chain and unrelated role",
+			roles: []workloadsv1alpha.RoleSpec{
+				wrappers.BuildBasicRole("router").WithDependencies([]string{"decode"}).Obj(),
+				wrappers.BuildBasicRole("decode").WithDependencies([]string{"prefill"}).Obj(),
+				wrappers.BuildBasicRole("prefill").Obj(),
+				wrappers.BuildBasicRole("monitor").Obj(),
+			},
+			want: [][]string{{"prefill", "monitor"}, {"decode"}, {"router"}},
+		},
+		{
+			name: "diamond",
+			roles: []workloadsv1alpha.RoleSpec{
+				wrappers.BuildBasicRole("d").WithDependencies([]string{"b", "c"}).Obj(),
+				wrappers.BuildBasicRole("c").WithDependencies([]string{"a"}).Obj(),
+				wrappers.BuildBasicRole("b").WithDependencies([]string{"a"}).Obj(),
+				wrappers.BuildBasicRole("a").Obj(),
+			},
+			want: [][]string{{"a"}, {"b", "c"}, {"d"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles(tt.roles).Obj()
+			levels, err := NewDefaultDependencyManager(nil, nil).LevelRoles(context.TODO(), rbg)
+			if err != nil {
+				t.Fatalf("LevelRoles() error = %v", err)
+			}
+			got := make([][]string, 0, len(levels))
+			for _, level := range levels {
+				names := make([]string, 0, len(level))
+				for _, role := range level {
+					names = append(names, role.Name)
+				}
+				got = append(got, names)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LevelRoles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependentRoles(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha.RoleSpec{
+		wrappers.BuildBasicRole("router").WithDependencies([]string{"decode"}).Obj(),
+		wrappers.BuildBasicRole("decode").WithDependencies([]string{"kv-cache"}).Obj(),
+		wrappers.BuildBasicRole("prefill").WithDependencies([]string{"kv-cache"}).Obj(),
+		wrappers.BuildBasicRole("kv-cache").Obj(),
+		wrappers.BuildBasicRole("monitor").Obj(),
+	}).Obj()
+
+	tests := []struct {
+		role string
+		want []string
+	}{
+		{role: "kv-cache", want: []string{"decode", "kv-cache", "prefill", "router"}},
+		{role: "decode", want: []string{"decode", "router"}},
+		{role: "monitor", want: []string{"monitor"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			if got := sets.List(DependentRoles(rbg, tt.role)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DependentRoles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
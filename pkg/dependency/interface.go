@@ -7,5 +7,8 @@ import (
 
 type DependencyManager interface {
 	SortRoles(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) ([]*workloadsv1alpha1.RoleSpec, error)
+	// LevelRoles groups the roles by their level in the dependency DAG. The roles of a level only depend
+	// on roles of lower levels, the roles of the first level have no dependencies.
+	LevelRoles(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) ([][]*workloadsv1alpha1.RoleSpec, error)
 	CheckDependencyReady(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (bool, error)
 }
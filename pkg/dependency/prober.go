@@ -0,0 +1,216 @@
+package dependency
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// Prober runs the probe of a dependency readiness against a pod, or the Service, of the dependency.
+type Prober interface {
+	Probe(ctx context.Context, pod *corev1.Pod, handler *corev1.ProbeHandler, timeout time.Duration) (bool, error)
+	ProbeService(ctx context.Context, svc *corev1.Service, handler *corev1.ProbeHandler, timeout time.Duration) (bool, error)
+}
+
+// DefaultProber probes the pods of a dependency over HTTP and TCP from the controller,
+// and runs exec probes through the exec subresource of the pods when it has a rest config.
+// The probes always target the pod IP, or the cluster DNS name of the Service, the host of
+// the probe handler is ignored.
+type DefaultProber struct {
+	httpClient *http.Client
+
+	config    *rest.Config
+	once      sync.Once
+	clientset kubernetes.Interface
+	err       error
+}
+
+var _ Prober = &DefaultProber{}
+
+// NewDefaultProber returns a prober which runs exec probes with config.
+// Exec probes fail if config is nil.
+func NewDefaultProber(config *rest.Config) *DefaultProber {
+	return &DefaultProber{
+		// like the kubelet, the certificates of the pods are not verified,
+		// and redirects are not followed, a redirect response passes the probe
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+				DisableKeepAlives: true,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		config: config,
+	}
+}
+
+func (p *DefaultProber) Probe(ctx context.Context, pod *corev1.Pod, handler *corev1.ProbeHandler, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch {
+	case handler.HTTPGet != nil:
+		port, err := resolvePort(pod, handler.HTTPGet.Port)
+		if err != nil {
+			return false, err
+		}
+		return p.probeHTTP(ctx, pod.Status.PodIP, port, handler.HTTPGet)
+	case handler.TCPSocket != nil:
+		port, err := resolvePort(pod, handler.TCPSocket.Port)
+		if err != nil {
+			return false, err
+		}
+		return p.probeTCP(ctx, pod.Status.PodIP, port)
+	case handler.Exec != nil:
+		return p.probeExec(ctx, pod, handler.Exec)
+	default:
+		return false, fmt.Errorf("unsupported probe handler for pod %s", pod.Name)
+	}
+}
+
+func (p *DefaultProber) ProbeService(ctx context.Context, svc *corev1.Service, handler *corev1.ProbeHandler, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	host := fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace)
+	switch {
+	case handler.HTTPGet != nil:
+		port, err := resolveServicePort(svc, handler.HTTPGet.Port)
+		if err != nil {
+			return false, err
+		}
+		return p.probeHTTP(ctx, host, port, handler.HTTPGet)
+	case handler.TCPSocket != nil:
+		port, err := resolveServicePort(svc, handler.TCPSocket.Port)
+		if err != nil {
+			return false, err
+		}
+		return p.probeTCP(ctx, host, port)
+	default:
+		return false, fmt.Errorf("unsupported probe handler for service %s", svc.Name)
+	}
+}
+
+func (p *DefaultProber) probeHTTP(ctx context.Context, host string, port int, action *corev1.HTTPGetAction) (bool, error) {
+	if host == "" {
+		return false, nil
+	}
+	uriScheme := "http"
+	if action.Scheme == corev1.URISchemeHTTPS {
+		uriScheme = "https"
+	}
+	u := url.URL{Scheme: uriScheme, Host: net.JoinHostPort(host, strconv.Itoa(port)), Path: action.Path}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	for _, header := range action.HTTPHeaders {
+		if header.Name == "Host" {
+			req.Host = header.Value
+			continue
+		}
+		req.Header.Add(header.Name, header.Value)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		// the dependency is not serving yet
+		return false, nil
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusBadRequest, nil
+}
+
+func (p *DefaultProber) probeTCP(ctx context.Context, host string, port int) (bool, error) {
+	if host == "" {
+		return false, nil
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return false, nil
+	}
+	_ = conn.Close()
+	return true, nil
+}
+
+func (p *DefaultProber) probeExec(ctx context.Context, pod *corev1.Pod, action *corev1.ExecAction) (bool, error) {
+	if p.config == nil {
+		return false, fmt.Errorf("exec probe of pod %s is not enabled in the controller", pod.Name)
+	}
+	p.once.Do(func() {
+		p.clientset, p.err = kubernetes.NewForConfig(p.config)
+	})
+	if p.err != nil {
+		return false, p.err
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return false, nil
+	}
+	req := p.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: pod.Spec.Containers[0].Name,
+			Command:   action.Command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(p.config, http.MethodPost, req.URL())
+	if err != nil {
+		return false, err
+	}
+	var stdout, stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		// a non-zero exit code of the command fails the probe
+		return false, nil
+	}
+	return true, nil
+}
+
+// resolvePort returns the number of port, looking named ports up in the containers of pod.
+func resolvePort(pod *corev1.Pod, port intstr.IntOrString) (int, error) {
+	if port.Type == intstr.Int {
+		return port.IntValue(), nil
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, p := range container.Ports {
+			if p.Name == port.StrVal {
+				return int(p.ContainerPort), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("port %q not found in pod %s", port.StrVal, pod.Name)
+}
+
+// resolveServicePort returns the number of port, looking named ports up in the ports of svc.
+func resolveServicePort(svc *corev1.Service, port intstr.IntOrString) (int, error) {
+	if port.Type == intstr.Int {
+		return port.IntValue(), nil
+	}
+	for _, p := range svc.Spec.Ports {
+		if p.Name == port.StrVal {
+			return int(p.Port), nil
+		}
+	}
+	return 0, fmt.Errorf("port %q not found in service %s", port.StrVal, svc.Name)
+}
@@ -0,0 +1,117 @@
+package dependency
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestDefaultProber_ProbeHTTP(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+		case "/redirect":
+			http.Redirect(w, r, "http://169.254.169.254/", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	// the certificate of the TLS server is self-signed
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+
+	pod := func(server *httptest.Server) *corev1.Pod {
+		host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+		containerPort, _ := strconv.Atoi(port)
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "prefill",
+				Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: int32(containerPort)}},
+			}}},
+			Status: corev1.PodStatus{PodIP: host},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		server *httptest.Server
+		action corev1.HTTPGetAction
+		want   bool
+	}{
+		{name: "healthy", server: server, action: corev1.HTTPGetAction{Path: "/health"}, want: true},
+		{name: "unhealthy", server: server, action: corev1.HTTPGetAction{Path: "/"}, want: false},
+		{name: "redirect not followed", server: server, action: corev1.HTTPGetAction{Path: "/redirect"}, want: true},
+		{
+			name:   "host ignored",
+			server: server,
+			action: corev1.HTTPGetAction{Path: "/health", Host: "169.254.169.254"},
+			want:   true,
+		},
+		{
+			name:   "https self-signed",
+			server: tlsServer,
+			action: corev1.HTTPGetAction{Path: "/health", Scheme: corev1.URISchemeHTTPS},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := tt.action
+			action.Port = intstr.FromString("http")
+			handler := &corev1.ProbeHandler{HTTPGet: &action}
+			got, err := NewDefaultProber(nil).Probe(context.TODO(), pod(tt.server), handler, time.Second)
+			if err != nil {
+				t.Fatalf("Probe() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Probe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultProber_ProbeExecNotEnabled(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "prefill"}}}}
+	handler := &corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"true"}}}
+	if _, err := NewDefaultProber(nil).Probe(context.TODO(), pod, handler, time.Second); err == nil {
+		t.Errorf("Probe() expected error without a rest config")
+	}
+}
+
+func Test_resolveServicePort(t *testing.T) {
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+		{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080)},
+	}}}
+
+	tests := []struct {
+		port    intstr.IntOrString
+		want    int
+		wantErr bool
+	}{
+		{port: intstr.FromInt32(8000), want: 8000},
+		{port: intstr.FromString("http"), want: 80},
+		{port: intstr.FromString("grpc"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.port.String(), func(t *testing.T) {
+			got, err := resolveServicePort(svc, tt.port)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveServicePort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveServicePort() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,22 +1,41 @@
 package discovery
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"text/template"
+
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/rbgs/pkg/utils"
-	"strings"
 
 	"sigs.k8s.io/yaml"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 )
 
+const (
+	// DefaultConfigMountPath is the directory the topology file is mounted at by default.
+	DefaultConfigMountPath = "/etc/rbg"
+	// defaultConfigFileName is the name of the topology file without extension by default.
+	defaultConfigFileName = "config"
+	// lwsTemplateRevisionHashLabelKey is the label of the pods of a LeaderWorkerSet recording their revision.
+	lwsTemplateRevisionHashLabelKey = "leaderworkerset.sigs.k8s.io/template-revision-hash"
+)
+
 type ConfigBuilder struct {
 	rbg  *workloadsv1alpha1.RoleBasedGroup
 	role *workloadsv1alpha1.RoleSpec
+	// pods are the live pods of the roles of rbg, by role name
+	pods map[string][]corev1.Pod
 }
 
 type ClusterConfig struct {
@@ -33,15 +52,33 @@ type GroupInfo struct {
 type RolesInfo map[string]RoleInstances
 
 type RoleInstances struct {
-	Size      int        `json:"size"`
+	// Size is the number of replicas the role declares
+	Size int `json:"size"`
+	// ReadySize is the number of instances ready to serve
+	ReadySize int        `json:"readySize"`
 	Instances []Instance `json:"instances"`
 }
 
+// Instance is a live pod of a role.
 type Instance struct {
-	Address string           `json:"address"`
-	Ports   map[string]int32 `json:"ports,omitempty"` // Key: port name, Value: port number
+	Name string `json:"name"`
+	// Address is the stable DNS name of the pod if it has one, or else its IP
+	Address  string           `json:"address,omitempty"`
+	IP       string           `json:"ip,omitempty"`
+	Node     string           `json:"node,omitempty"`
+	Ready    bool             `json:"ready"`
+	Revision string           `json:"revision,omitempty"`
+	Ports    map[string]int32 `json:"ports,omitempty"` // Key: port name, Value: port number
+}
+
+// TemplateData is what the template of a DiscoveryConfig is executed with.
+type TemplateData struct {
+	ClusterConfig
+	// RoleName is the role the rendered file is mounted into.
+	RoleName string
 }
 
+// Build renders the topology of the group as the discoveryConfig of the role asks for.
 func (b *ConfigBuilder) Build() ([]byte, error) {
 	config := ClusterConfig{
 		Group: GroupInfo{
@@ -51,7 +88,160 @@ func (b *ConfigBuilder) Build() ([]byte, error) {
 		},
 		Roles: b.buildRolesInfo(),
 	}
-	return yaml.Marshal(config)
+
+	discoveryConfig := b.discoveryConfig()
+	if discoveryConfig.Template != "" {
+		tmpl, err := ParseConfigTemplate(discoveryConfig.Template)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, TemplateData{ClusterConfig: config, RoleName: b.role.Name}); err != nil {
+			return nil, fmt.Errorf("failed to render discovery config template: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	switch discoveryConfig.Format {
+	case workloadsv1alpha1.JSONDiscoveryConfigFormat:
+		return json.MarshalIndent(config, "", "  ")
+	case workloadsv1alpha1.EnvDiscoveryConfigFormat:
+		return buildEnvFile(config), nil
+	default:
+		return yaml.Marshal(config)
+	}
+}
+
+// FileName returns the name of the topology file of the role.
+func (b *ConfigBuilder) FileName() string {
+	if fileName := b.discoveryConfig().FileName; fileName != "" {
+		return fileName
+	}
+	return fmt.Sprintf("%s.%s", defaultConfigFileName, b.format())
+}
+
+// ConfigKey returns the key of the topology of the role in the discovery ConfigMap of the group.
+// The roles rendering the same topology share the same key.
+func (b *ConfigBuilder) ConfigKey() string {
+	discoveryConfig := b.discoveryConfig()
+	shape := []string{string(b.format()), b.FileName(), strings.Join(sets.List(b.visibleRoles()), ",")}
+	if discoveryConfig.Template != "" {
+		// a template may render the name of the role
+		shape = append(shape, discoveryConfig.Template, b.role.Name)
+	}
+	hf := fnv.New32a()
+	_, _ = hf.Write([]byte(strings.Join(shape, "\n")))
+	return fmt.Sprintf("%08x-%s", hf.Sum32(), b.FileName())
+}
+
+func (b *ConfigBuilder) format() workloadsv1alpha1.DiscoveryConfigFormat {
+	if format := b.discoveryConfig().Format; format != "" {
+		return format
+	}
+	return workloadsv1alpha1.YAMLDiscoveryConfigFormat
+}
+
+// visibleRoles returns the roles listed in the topology of the role,
+// the dependencies of the role and the role itself by default.
+func (b *ConfigBuilder) visibleRoles() sets.Set[string] {
+	if visibleRoles := b.discoveryConfig().VisibleRoles; len(visibleRoles) > 0 {
+		return sets.New(visibleRoles...)
+	}
+	return sets.New(b.role.Dependencies...).Insert(b.role.Name)
+}
+
+// MountPath returns the directory the topology file of the role is mounted at.
+func (b *ConfigBuilder) MountPath() string {
+	if mountPath := b.discoveryConfig().MountPath; mountPath != "" {
+		return mountPath
+	}
+	return DefaultConfigMountPath
+}
+
+func (b *ConfigBuilder) discoveryConfig() workloadsv1alpha1.DiscoveryConfig {
+	if b.role.DiscoveryConfig == nil {
+		return workloadsv1alpha1.DiscoveryConfig{}
+	}
+	return *b.role.DiscoveryConfig
+}
+
+// ParseConfigTemplate parses the template of a DiscoveryConfig.
+func ParseConfigTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("discoveryConfig").Option("missingkey=error").Funcs(template.FuncMap{
+		"toJson": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"toYaml": func(v interface{}) (string, error) {
+			data, err := yaml.Marshal(v)
+			return strings.TrimSuffix(string(data), "\n"), err
+		},
+		"join": func(sep string, elems []string) string {
+			return strings.Join(elems, sep)
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"replace": func(old, new, s string) string {
+			return strings.ReplaceAll(s, old, new)
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse discovery config template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// buildEnvFile renders config as KEY=value lines, the visible roles in the order of the spec.
+func buildEnvFile(config ClusterConfig) []byte {
+	var buf bytes.Buffer
+	writeEnv := func(key string, value interface{}) {
+		fmt.Fprintf(&buf, "%s=%v\n", key, value)
+	}
+
+	writeEnv("GROUP_NAME", config.Group.Name)
+	writeEnv("GROUP_SIZE", config.Group.Size)
+	writeEnv("GROUP_ROLES", strings.Join(config.Group.Roles, ","))
+	for _, roleName := range config.Group.Roles {
+		role, visible := config.Roles[roleName]
+		if !visible {
+			continue
+		}
+		prefix := "ROLE_" + envKey(roleName)
+		var addresses, readyAddresses []string
+		for _, instance := range role.Instances {
+			addresses = append(addresses, instance.Address)
+			if instance.Ready {
+				readyAddresses = append(readyAddresses, instance.Address)
+			}
+		}
+		writeEnv(prefix+"_SIZE", role.Size)
+		writeEnv(prefix+"_READY_SIZE", role.ReadySize)
+		writeEnv(prefix+"_INSTANCES", strings.Join(addresses, ","))
+		writeEnv(prefix+"_READY_INSTANCES", strings.Join(readyAddresses, ","))
+		for i, instance := range role.Instances {
+			instancePrefix := fmt.Sprintf("%s_INSTANCE_%d", prefix, i)
+			writeEnv(instancePrefix+"_NAME", instance.Name)
+			writeEnv(instancePrefix+"_ADDRESS", instance.Address)
+			writeEnv(instancePrefix+"_IP", instance.IP)
+			writeEnv(instancePrefix+"_NODE", instance.Node)
+			writeEnv(instancePrefix+"_READY", instance.Ready)
+			writeEnv(instancePrefix+"_REVISION", instance.Revision)
+			portNames := make([]string, 0, len(instance.Ports))
+			for portName := range instance.Ports {
+				portNames = append(portNames, portName)
+			}
+			sort.Strings(portNames)
+			for _, portName := range portNames {
+				writeEnv(instancePrefix+"_PORT_"+envKey(portName), instance.Ports[portName])
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// envKey turns name into the upper case part of an environment variable name.
+func envKey(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
 }
 
 func (b *ConfigBuilder) getRoleNames() []string {
@@ -62,25 +252,46 @@ func (b *ConfigBuilder) getRoleNames() []string {
 	return names
 }
 
+// buildRolesInfo lists the instances of the roles visible to the role.
 func (b *ConfigBuilder) buildRolesInfo() RolesInfo {
 	roles := make(RolesInfo)
+	visibleRoles := b.visibleRoles()
 	for _, role := range b.rbg.Spec.Roles {
+		if !visibleRoles.Has(role.Name) {
+			continue
+		}
+		instances := b.buildInstances(&role)
+		readySize := 0
+		for _, instance := range instances {
+			if instance.Ready {
+				readySize++
+			}
+		}
 		roles[role.Name] = RoleInstances{
 			Size:      int(*role.Replicas),
-			Instances: b.buildInstances(&role),
+			ReadySize: readySize,
+			Instances: instances,
 		}
 	}
 	return roles
 }
 
+// buildInstances lists the live pods of role sorted by name, the terminating pods are left out.
 func (b *ConfigBuilder) buildInstances(role *workloadsv1alpha1.RoleSpec) []Instance {
-	instances := make([]Instance, 0, *role.Replicas)
-	serviceName := b.rbg.GetWorkloadName(role)
-
-	for i := 0; i < int(*role.Replicas); i++ {
+	pods := b.pods[role.Name]
+	instances := make([]Instance, 0, len(pods))
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
 		instance := Instance{
-			Address: fmt.Sprintf("%s-%d.%s", role.Name, i, serviceName),
-			Ports:   make(map[string]int32),
+			Name:     pod.Name,
+			Address:  podAddress(pod),
+			IP:       pod.Status.PodIP,
+			Node:     pod.Spec.NodeName,
+			Ready:    utils.PodRunningAndReady(pod),
+			Revision: podRevision(pod),
+			Ports:    make(map[string]int32),
 		}
 
 		for _, port := range role.ServicePorts {
@@ -90,9 +301,31 @@ func (b *ConfigBuilder) buildInstances(role *workloadsv1alpha1.RoleSpec) []Insta
 
 		instances = append(instances, instance)
 	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Name < instances[j].Name
+	})
 	return instances
 }
 
+// podAddress returns the stable DNS name of pod within its namespace, like the ones of the pods of
+// a StatefulSet, or else its IP. Pods of a Deployment have no stable DNS name.
+func podAddress(pod corev1.Pod) string {
+	if pod.Spec.Hostname != "" && pod.Spec.Subdomain != "" {
+		return fmt.Sprintf("%s.%s", pod.Spec.Hostname, pod.Spec.Subdomain)
+	}
+	return pod.Status.PodIP
+}
+
+// podRevision returns the revision of the template pod was created from, as recorded by its workload.
+func podRevision(pod corev1.Pod) string {
+	for _, key := range []string{appsv1.ControllerRevisionHashLabelKey, appsv1.DefaultDeploymentUniqueLabelKey, lwsTemplateRevisionHashLabelKey} {
+		if revision := pod.Labels[key]; revision != "" {
+			return revision
+		}
+	}
+	return pod.Annotations[workloadsv1alpha1.PodTemplateHashAnnotationKey]
+}
+
 func generatePortKey(port corev1.ServicePort) string {
 	if port.Name != "" {
 		return strings.ToLower(strings.ReplaceAll(port.Name, "-", "_"))
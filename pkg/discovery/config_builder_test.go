@@ -0,0 +1,335 @@
+package discovery
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+// discoveryPods returns the live pods of the prefill StatefulSet role and of the mooncake-master Deployment role.
+func discoveryPods() map[string][]corev1.Pod {
+	prefill0 := wrappers.BuildBasicPod().WithName("test-rbg-prefill-0").WithReadyCondition(true).
+		WithLabels(map[string]string{appsv1.ControllerRevisionHashLabelKey: "test-rbg-prefill-5d8f"}).Obj()
+	prefill0.Spec.Hostname, prefill0.Spec.Subdomain = "test-rbg-prefill-0", "test-rbg-prefill"
+	prefill0.Spec.NodeName = "node-a"
+	prefill0.Status.PodIP = "10.0.0.10"
+
+	prefill1 := wrappers.BuildBasicPod().WithName("test-rbg-prefill-1").
+		WithLabels(map[string]string{appsv1.ControllerRevisionHashLabelKey: "test-rbg-prefill-5d8f"}).Obj()
+	prefill1.Spec.Hostname, prefill1.Spec.Subdomain = "test-rbg-prefill-1", "test-rbg-prefill"
+	prefill1.Status.Phase = corev1.PodPending
+
+	terminating := wrappers.BuildDeletingPod().WithName("test-rbg-prefill-2").Obj()
+
+	master := wrappers.BuildBasicPod().WithName("test-rbg-mooncake-master-7c9b-x2k4p").WithReadyCondition(true).
+		WithLabels(map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "7c9b"}).Obj()
+	master.Spec.NodeName = "node-b"
+	master.Status.PodIP = "10.0.0.20"
+
+	return map[string][]corev1.Pod{
+		"prefill":         {prefill1, terminating, prefill0},
+		"mooncake-master": {master},
+	}
+}
+
+func TestConfigBuilder_Build(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha.RoleSpec{
+		wrappers.BuildBasicRole("prefill").WithReplicas(2).WithDependencies([]string{"mooncake-master"}).Obj(),
+		wrappers.BuildBasicRole("mooncake-master").WithWorkload(workloadsv1alpha.DeploymentWorkloadType).Obj(),
+	}).Obj()
+	rbg.Spec.Roles[1].ServicePorts = []corev1.ServicePort{{Name: "rpc-port", Port: 50051}}
+
+	tests := []struct {
+		name          string
+		config        *workloadsv1alpha.DiscoveryConfig
+		wantFileName  string
+		wantMountPath string
+		want          string
+	}{
+		{
+			name:          "default yaml",
+			wantFileName:  "config.yaml",
+			wantMountPath: "/etc/rbg",
+			want: `group:
+  name: test-rbg
+  roles:
+  - prefill
+  - mooncake-master
+  size: 2
+roles:
+  mooncake-master:
+    instances:
+    - address: 10.0.0.20
+      ip: 10.0.0.20
+      name: test-rbg-mooncake-master-7c9b-x2k4p
+      node: node-b
+      ports:
+        rpc_port: 50051
+      ready: true
+      revision: 7c9b
+    readySize: 1
+    size: 1
+  prefill:
+    instances:
+    - address: test-rbg-prefill-0.test-rbg-prefill
+      ip: 10.0.0.10
+      name: test-rbg-prefill-0
+      node: node-a
+      ready: true
+      revision: test-rbg-prefill-5d8f
+    - address: test-rbg-prefill-1.test-rbg-prefill
+      name: test-rbg-prefill-1
+      ready: false
+      revision: test-rbg-prefill-5d8f
+    readySize: 1
+    size: 2
+`,
+		},
+		{
+			name:          "env",
+			config:        &workloadsv1alpha.DiscoveryConfig{Format: workloadsv1alpha.EnvDiscoveryConfigFormat, FileName: "topology.env"},
+			wantFileName:  "topology.env",
+			wantMountPath: "/etc/rbg",
+			want: `GROUP_NAME=test-rbg
+GROUP_SIZE=2
+GROUP_ROLES=prefill,mooncake-master
+ROLE_PREFILL_SIZE=2
+ROLE_PREFILL_READY_SIZE=1
+ROLE_PREFILL_INSTANCES=test-rbg-prefill-0.test-rbg-prefill,test-rbg-prefill-1.test-rbg-prefill
+ROLE_PREFILL_READY_INSTANCES=test-rbg-prefill-0.test-rbg-prefill
+ROLE_PREFILL_INSTANCE_0_NAME=test-rbg-prefill-0
+ROLE_PREFILL_INSTANCE_0_ADDRESS=test-rbg-prefill-0.test-rbg-prefill
+ROLE_PREFILL_INSTANCE_0_IP=10.0.0.10
+ROLE_PREFILL_INSTANCE_0_NODE=node-a
+ROLE_PREFILL_INSTANCE_0_READY=true
+ROLE_PREFILL_INSTANCE_0_REVISION=test-rbg-prefill-5d8f
+ROLE_PREFILL_INSTANCE_1_NAME=test-rbg-prefill-1
+ROLE_PREFILL_INSTANCE_1_ADDRESS=test-rbg-prefill-1.test-rbg-prefill
+ROLE_PREFILL_INSTANCE_1_IP=
+ROLE_PREFILL_INSTANCE_1_NODE=
+ROLE_PREFILL_INSTANCE_1_READY=false
+ROLE_PREFILL_INSTANCE_1_REVISION=test-rbg-prefill-5d8f
+ROLE_MOONCAKE_MASTER_SIZE=1
+ROLE_MOONCAKE_MASTER_READY_SIZE=1
+ROLE_MOONCAKE_MASTER_INSTANCES=10.0.0.20
+ROLE_MOONCAKE_MASTER_READY_INSTANCES=10.0.0.20
+ROLE_MOONCAKE_MASTER_INSTANCE_0_NAME=test-rbg-mooncake-master-7c9b-x2k4p
+ROLE_MOONCAKE_MASTER_INSTANCE_0_ADDRESS=10.0.0.20
+ROLE_MOONCAKE_MASTER_INSTANCE_0_IP=10.0.0.20
+ROLE_MOONCAKE_MASTER_INSTANCE_0_NODE=node-b
+ROLE_MOONCAKE_MASTER_INSTANCE_0_READY=true
+ROLE_MOONCAKE_MASTER_INSTANCE_0_REVISION=7c9b
+ROLE_MOONCAKE_MASTER_INSTANCE_0_PORT_RPC_PORT=50051
+`,
+		},
+		{
+			name: "template",
+			config: &workloadsv1alpha.DiscoveryConfig{
+				MountPath: "/etc/mooncake",
+				FileName:  "mooncake.json",
+				Template: `{{- $master := index (index .Roles "mooncake-master").Instances 0 -}}
+{"role": "{{ .RoleName | upper }}", "master_server_address": "{{ $master.Address }}:{{ index $master.Ports "rpc_port" }}", "roles": "{{ join "," .Group.Roles }}"}`,
+			},
+			wantFileName:  "mooncake.json",
+			wantMountPath: "/etc/mooncake",
+			want:          `{"role": "PREFILL", "master_server_address": "10.0.0.20:50051", "roles": "prefill,mooncake-master"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := rbg.Spec.Roles[0].DeepCopy()
+			role.DiscoveryConfig = tt.config
+			builder := &ConfigBuilder{rbg: rbg, role: role, pods: discoveryPods()}
+
+			got, err := builder.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Build() = %s, want %s", got, tt.want)
+			}
+			if fileName := builder.FileName(); fileName != tt.wantFileName {
+				t.Errorf("FileName() = %s, want %s", fileName, tt.wantFileName)
+			}
+			if mountPath := builder.MountPath(); mountPath != tt.wantMountPath {
+				t.Errorf("MountPath() = %s, want %s", mountPath, tt.wantMountPath)
+			}
+		})
+	}
+}
+
+func TestConfigBuilder_BuildJSON(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha.RoleSpec{
+		wrappers.BuildBasicRole("prefill").WithReplicas(2).Obj(),
+	}).Obj()
+	role := rbg.Spec.Roles[0].DeepCopy()
+	role.DiscoveryConfig = &workloadsv1alpha.DiscoveryConfig{Format: workloadsv1alpha.JSONDiscoveryConfigFormat}
+	pods := discoveryPods()
+	delete(pods, "mooncake-master")
+	builder := &ConfigBuilder{rbg: rbg, role: role, pods: pods}
+
+	got, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	var config ClusterConfig
+	if err := json.Unmarshal(got, &config); err != nil {
+		t.Fatalf("Build() is not JSON: %v", err)
+	}
+	want := ClusterConfig{
+		Group: GroupInfo{Name: "test-rbg", Size: 1, Roles: []string{"prefill"}},
+		Roles: RolesInfo{"prefill": builder.buildRolesInfo()["prefill"]},
+	}
+	if !apiequality.Semantic.DeepEqual(config, want) {
+		t.Errorf("Build() = %+v, want %+v", config, want)
+	}
+	if builder.FileName() != "config.json" {
+		t.Errorf("FileName() = %s, want config.json", builder.FileName())
+	}
+}
+
+func TestConfigBuilder_BuildTemplateError(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha.RoleSpec{
+		wrappers.BuildBasicRole("prefill").Obj(),
+	}).Obj()
+	role := rbg.Spec.Roles[0].DeepCopy()
+	role.DiscoveryConfig = &workloadsv1alpha.DiscoveryConfig{FileName: "config.txt", Template: `{{ .Group.Zone }}`}
+
+	_, err := (&ConfigBuilder{rbg: rbg, role: role}).Build()
+	if err == nil || !strings.Contains(err.Error(), "render") {
+		t.Errorf("Build() error = %v, want a render error", err)
+	}
+}
+
+func TestConfigBuilder_VisibleRoles(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha.RoleSpec{
+		wrappers.BuildBasicRole("prefill").WithDependencies([]string{"mooncake-master"}).Obj(),
+		wrappers.BuildBasicRole("mooncake-master").WithWorkload(workloadsv1alpha.DeploymentWorkloadType).Obj(),
+		wrappers.BuildBasicRole("router").Obj(),
+	}).Obj()
+
+	tests := []struct {
+		name   string
+		role   workloadsv1alpha.RoleSpec
+		config *workloadsv1alpha.DiscoveryConfig
+		want   []string
+	}{
+		{
+			name: "dependencies and itself by default",
+			role: rbg.Spec.Roles[0],
+			want: []string{"mooncake-master", "prefill"},
+		},
+		{
+			name: "itself without dependencies",
+			role: rbg.Spec.Roles[1],
+			want: []string{"mooncake-master"},
+		},
+		{
+			name:   "visible roles",
+			role:   rbg.Spec.Roles[2],
+			config: &workloadsv1alpha.DiscoveryConfig{VisibleRoles: []string{"prefill", "router"}},
+			want:   []string{"prefill", "router"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := tt.role.DeepCopy()
+			role.DiscoveryConfig = tt.config
+			builder := &ConfigBuilder{rbg: rbg, role: role, pods: discoveryPods()}
+
+			var got []string
+			for name := range builder.buildRolesInfo() {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildRolesInfo() roles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigBuilder_ConfigKey(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha.RoleSpec{
+		wrappers.BuildBasicRole("prefill").WithDependencies([]string{"mooncake-master"}).Obj(),
+		wrappers.BuildBasicRole("decode").Obj(),
+		wrappers.BuildBasicRole("mooncake-master").Obj(),
+	}).Obj()
+	rbg.Spec.Roles[1].DiscoveryConfig = &workloadsv1alpha.DiscoveryConfig{
+		VisibleRoles: []string{"prefill", "mooncake-master"},
+		MountPath:    "/etc/decode",
+	}
+	key := func(role workloadsv1alpha.RoleSpec) string {
+		return (&ConfigBuilder{rbg: rbg, role: &role}).ConfigKey()
+	}
+
+	prefillKey, decodeKey, masterKey := key(rbg.Spec.Roles[0]), key(rbg.Spec.Roles[1]), key(rbg.Spec.Roles[2])
+	if prefillKey != decodeKey {
+		t.Errorf("ConfigKey() = %s and %s, want the roles seeing the same topology to share it", prefillKey, decodeKey)
+	}
+	if prefillKey == masterKey {
+		t.Errorf("ConfigKey() = %s for both, want the roles seeing other roles not to share it", prefillKey)
+	}
+	if !strings.HasSuffix(prefillKey, "-config.yaml") {
+		t.Errorf("ConfigKey() = %s, want the file name suffix", prefillKey)
+	}
+
+	templateRole := func(name string) workloadsv1alpha.RoleSpec {
+		role := wrappers.BuildBasicRole(name).Obj()
+		role.DiscoveryConfig = &workloadsv1alpha.DiscoveryConfig{
+			FileName: "role.txt", Template: "{{ .RoleName }}", VisibleRoles: []string{"prefill"},
+		}
+		return role
+	}
+	if key(templateRole("prefill")) == key(templateRole("decode")) {
+		t.Errorf("ConfigKey() is shared by templates rendered for different roles")
+	}
+}
+
+func Test_buildConfigData(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha.RoleSpec{
+		wrappers.BuildBasicRole("prefill").WithDependencies([]string{"mooncake-master"}).Obj(),
+		wrappers.BuildBasicRole("decode").Obj(),
+		wrappers.BuildBasicRole("mooncake-master").Obj(),
+	}).Obj()
+	rbg.Spec.Roles[1].DiscoveryConfig = &workloadsv1alpha.DiscoveryConfig{
+		VisibleRoles: []string{"prefill", "mooncake-master"},
+	}
+	rbg.Spec.Roles[2].DiscoveryConfig = &workloadsv1alpha.DiscoveryConfig{
+		FileName: "master.txt", Template: `{{ .Group.Zone }}`,
+	}
+	masterKey := (&ConfigBuilder{rbg: rbg, role: &rbg.Spec.Roles[2]}).ConfigKey()
+
+	data, errs := buildConfigData(rbg, discoveryPods(), map[string]string{masterKey: "old"})
+	if len(data) != 2 {
+		t.Errorf("buildConfigData() = %d keys, want the prefill and decode roles to share one", len(data))
+	}
+	if data[masterKey] != "old" {
+		t.Errorf("buildConfigData() = %q for the failed role, want the old content", data[masterKey])
+	}
+	if len(errs) != 1 || errs["mooncake-master"] == nil {
+		t.Errorf("buildConfigData() errors = %v, want an error of the mooncake-master role", errs)
+	}
+}
+
+func Test_podAddress(t *testing.T) {
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "worker"}, Status: corev1.PodStatus{PodIP: "10.0.0.1"}}
+	if got := podAddress(pod); got != "10.0.0.1" {
+		t.Errorf("podAddress() = %s, want the pod IP", got)
+	}
+	pod.Spec.Hostname, pod.Spec.Subdomain = "worker-0", "workers"
+	if got := podAddress(pod); got != "worker-0.workers" {
+		t.Errorf("podAddress() = %s, want the pod DNS name", got)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"sort"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	coreapplyv1 "k8s.io/client-go/applyconfigurations/core/v1"
 	metaapplyv1 "k8s.io/client-go/applyconfigurations/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -38,63 +40,30 @@ func NewDefaultInjector(scheme *runtime.Scheme, client client.Client) *DefaultIn
 	}
 }
 
-func (i *DefaultInjector) InjectConfig(ctx context.Context, podSpec *corev1.PodTemplateSpec, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
-	logger := log.FromContext(ctx)
-
-	builder := &ConfigBuilder{
-		rbg:  rbg,
-		role: role,
-	}
-
-	const (
-		volumeName = "rbg-cluster-config"
-		mountPath  = "/etc/rbg"
-		configKey  = "config.yaml"
-	)
+// ConfigMapName returns the name of the discovery ConfigMap shared by the roles of rbg.
+func ConfigMapName(rbg *workloadsv1alpha1.RoleBasedGroup) string {
+	return fmt.Sprintf("%s-discovery", rbg.Name)
+}
 
-	configData, err := builder.Build()
+func (i *DefaultInjector) InjectConfig(ctx context.Context, podSpec *corev1.PodTemplateSpec, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
+	pods, err := i.listPods(ctx, rbg)
 	if err != nil {
 		return err
 	}
-	cmApplyConfig := coreapplyv1.ConfigMap(rbg.GetWorkloadName(role), rbg.Namespace).
-		WithData(map[string]string{
-			configKey: string(configData),
-		}).
-		WithOwnerReferences(metaapplyv1.OwnerReference().
-			WithAPIVersion(rbg.APIVersion).
-			WithKind(rbg.Kind).
-			WithName(rbg.Name).
-			WithUID(rbg.GetUID()).
-			WithBlockOwnerDeletion(true).
-			WithController(true),
-		)
-
-	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cmApplyConfig)
-	if err != nil {
-		logger.Error(err, "Converting obj apply configuration to json.")
-		return err
-	}
-	newConfigmap := &corev1.ConfigMap{}
-	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj, newConfigmap); err != nil {
-		return fmt.Errorf("convert ConfigmapApplyConfig to deploy error: %s", err.Error())
-	}
 
 	oldConfigmap := &corev1.ConfigMap{}
-	err = i.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, oldConfigmap)
+	err = i.client.Get(ctx, types.NamespacedName{Name: ConfigMapName(rbg), Namespace: rbg.Namespace}, oldConfigmap)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
-	equal, diff := semanticallyEqualConfigmap(oldConfigmap, newConfigmap)
-	if equal {
-		logger.V(1).Info("configmap equal, skip reconcile")
-	} else {
-		logger.V(1).Info(fmt.Sprintf("confgmap not equal, diff: %s", diff))
-		if err := utils.PatchObjectApplyConfiguration(ctx, i.client, cmApplyConfig, utils.PatchSpec); err != nil {
-			logger.Error(err, "Failed to patch ConfigMap")
-			return err
-		}
+	if errs := i.applyConfigmap(ctx, rbg, pods, oldConfigmap); errs[role.Name] != nil {
+		return errs[role.Name]
 	}
 
+	builder := &ConfigBuilder{rbg: rbg, role: role, pods: pods}
+	const volumeName = "rbg-cluster-config"
+	mountPath := builder.MountPath()
+
 	volumeExists := false
 	for _, vol := range podSpec.Spec.Volumes {
 		if vol.Name == volumeName {
@@ -108,10 +77,10 @@ func (i *DefaultInjector) InjectConfig(ctx context.Context, podSpec *corev1.PodT
 			VolumeSource: corev1.VolumeSource{
 				ConfigMap: &corev1.ConfigMapVolumeSource{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: rbg.GetWorkloadName(role),
+						Name: ConfigMapName(rbg),
 					},
 					Items: []corev1.KeyToPath{
-						{Key: configKey, Path: configKey},
+						{Key: builder.ConfigKey(), Path: builder.FileName()},
 					},
 				},
 			},
@@ -138,6 +107,164 @@ func (i *DefaultInjector) InjectConfig(ctx context.Context, podSpec *corev1.PodT
 	return nil
 }
 
+// RefreshConfig rebuilds the topologies in the discovery ConfigMap of rbg from its live pods.
+// The ConfigMap is only created by InjectConfig, when the workload of a role is reconciled.
+func (i *DefaultInjector) RefreshConfig(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	oldConfigmap := &corev1.ConfigMap{}
+	if err := i.client.Get(ctx, types.NamespacedName{Name: ConfigMapName(rbg), Namespace: rbg.Namespace}, oldConfigmap); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	pods, err := i.listPods(ctx, rbg)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, err := range i.applyConfigmap(ctx, rbg, pods, oldConfigmap) {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// DeleteLegacyConfigs deletes the discovery ConfigMaps of the roles of rbg, which were created per role
+// before the roles shared the ConfigMap of rbg. A ConfigMap is kept while a pod of rbg still mounts it,
+// until the workload of its role rolled out the shared ConfigMap.
+func (i *DefaultInjector) DeleteLegacyConfigs(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	pods, err := i.listPods(ctx, rbg)
+	if err != nil {
+		return err
+	}
+	mounted := make(map[string]bool)
+	for _, rolePods := range pods {
+		for _, pod := range rolePods {
+			for _, volume := range pod.Spec.Volumes {
+				if volume.ConfigMap != nil {
+					mounted[volume.ConfigMap.Name] = true
+				}
+			}
+		}
+	}
+
+	var errs []error
+	for _, role := range rbg.Spec.Roles {
+		name := rbg.GetWorkloadName(&role)
+		if name == ConfigMapName(rbg) || mounted[name] {
+			continue
+		}
+		configmap := &corev1.ConfigMap{}
+		if err := i.client.Get(ctx, types.NamespacedName{Name: name, Namespace: rbg.Namespace}, configmap); err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if !metav1.IsControlledBy(configmap, rbg) || configmap.DeletionTimestamp != nil {
+			continue
+		}
+		log.FromContext(ctx).Info("Delete legacy discovery configmap", "configmap", name)
+		if err := i.client.Delete(ctx, configmap); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// listPods lists the live pods of the roles of rbg, by role name.
+func (i *DefaultInjector) listPods(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (map[string][]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := i.client.List(ctx, podList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels{workloadsv1alpha1.SetNameLabelKey: rbg.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list the pods of rbg: %w", err)
+	}
+	pods := make(map[string][]corev1.Pod)
+	for _, pod := range podList.Items {
+		roleName := pod.Labels[workloadsv1alpha1.SetRoleLabelKey]
+		pods[roleName] = append(pods[roleName], pod)
+	}
+	return pods, nil
+}
+
+// buildConfigData renders the topologies of all the roles of rbg from pods, by ConfigMap key. The roles
+// rendering the same topology share a key, which is rendered once. A topology failing to render keeps
+// its content in oldData, the errors are returned by role name.
+func buildConfigData(rbg *workloadsv1alpha1.RoleBasedGroup, pods map[string][]corev1.Pod,
+	oldData map[string]string) (map[string]string, map[string]error) {
+	data := make(map[string]string)
+	errs := make(map[string]error)
+	failedKeys := make(map[string]error)
+	for i := range rbg.Spec.Roles {
+		role := &rbg.Spec.Roles[i]
+		builder := &ConfigBuilder{rbg: rbg, role: role, pods: pods}
+		key := builder.ConfigKey()
+		if err, failed := failedKeys[key]; failed {
+			errs[role.Name] = err
+			continue
+		}
+		if _, built := data[key]; built {
+			continue
+		}
+		content, err := builder.Build()
+		if err != nil {
+			err = fmt.Errorf("failed to build the discovery config of role %s: %w", role.Name, err)
+			failedKeys[key], errs[role.Name] = err, err
+			if oldContent, found := oldData[key]; found {
+				data[key] = oldContent
+			}
+			continue
+		}
+		data[key] = string(content)
+	}
+	return data, errs
+}
+
+// applyConfigmap applies the topologies of all the roles of rbg built from pods to the discovery ConfigMap
+// of rbg, unless oldConfigmap, the current ConfigMap if any, already holds them. It returns the errors
+// by role name, the errors of applying the ConfigMap are returned for every role.
+func (i *DefaultInjector) applyConfigmap(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	pods map[string][]corev1.Pod, oldConfigmap *corev1.ConfigMap) map[string]error {
+	logger := log.FromContext(ctx)
+
+	data, errs := buildConfigData(rbg, pods, oldConfigmap.Data)
+	failAll := func(err error) map[string]error {
+		for _, role := range rbg.Spec.Roles {
+			errs[role.Name] = err
+		}
+		return errs
+	}
+	cmApplyConfig := coreapplyv1.ConfigMap(ConfigMapName(rbg), rbg.Namespace).
+		WithData(data).
+		WithOwnerReferences(metaapplyv1.OwnerReference().
+			WithAPIVersion(rbg.APIVersion).
+			WithKind(rbg.Kind).
+			WithName(rbg.Name).
+			WithUID(rbg.GetUID()).
+			WithBlockOwnerDeletion(true).
+			WithController(true),
+		)
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cmApplyConfig)
+	if err != nil {
+		logger.Error(err, "Converting obj apply configuration to json.")
+		return failAll(err)
+	}
+	newConfigmap := &corev1.ConfigMap{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj, newConfigmap); err != nil {
+		return failAll(fmt.Errorf("convert ConfigmapApplyConfig to deploy error: %s", err.Error()))
+	}
+
+	equal, diff := semanticallyEqualConfigmap(oldConfigmap, newConfigmap)
+	if equal {
+		logger.V(1).Info("configmap equal, skip reconcile")
+		return errs
+	}
+	logger.V(1).Info(fmt.Sprintf("confgmap not equal, diff: %s", diff))
+	if err := utils.PatchObjectApplyConfiguration(ctx, i.client, cmApplyConfig, utils.PatchSpec); err != nil {
+		logger.Error(err, "Failed to patch ConfigMap")
+		return failAll(err)
+	}
+	return errs
+}
+
 func (i *DefaultInjector) InjectEnv(ctx context.Context, podSpec *corev1.PodTemplateSpec, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
 	builder := &EnvBuilder{
 		rbg:  rbg,
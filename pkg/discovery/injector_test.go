@@ -8,6 +8,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 )
@@ -381,3 +382,63 @@ func TestInjectSidecar(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteLegacyConfigs(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(testScheme)
+	_ = workloadsv1alpha.AddToScheme(testScheme)
+
+	rbg := &workloadsv1alpha.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default", UID: "rbg-uid"},
+		Spec: workloadsv1alpha.RoleBasedGroupSpec{
+			Roles: []workloadsv1alpha.RoleSpec{{Name: "prefill"}, {Name: "decode"}, {Name: "router"}},
+		},
+	}
+	controllerRef := []metav1.OwnerReference{*metav1.NewControllerRef(rbg,
+		workloadsv1alpha.GroupVersion.WithKind("RoleBasedGroup"))}
+	configmap := func(name string, ownerRefs []metav1.OwnerReference) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "default", OwnerReferences: ownerRefs,
+		}}
+	}
+	// a pod of decode still runs the template mounting the legacy ConfigMap
+	decodePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "decode-0", Namespace: "default", Labels: map[string]string{
+			workloadsv1alpha.SetNameLabelKey: "test-rbg",
+			workloadsv1alpha.SetRoleLabelKey: "decode",
+		}},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name: "rbg-cluster-config",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "test-rbg-decode"},
+			}},
+		}}},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+		configmap(ConfigMapName(rbg), controllerRef),
+		configmap("test-rbg-prefill", controllerRef),
+		configmap("test-rbg-decode", controllerRef),
+		// not created by the controller
+		configmap("test-rbg-router", nil),
+		decodePod,
+	).Build()
+
+	injector := NewDefaultInjector(testScheme, fakeClient)
+	if err := injector.DeleteLegacyConfigs(context.TODO(), rbg); err != nil {
+		t.Fatalf("DeleteLegacyConfigs() error = %v", err)
+	}
+
+	want := map[string]bool{
+		ConfigMapName(rbg): true,
+		"test-rbg-prefill": false,
+		"test-rbg-decode":  true,
+		"test-rbg-router":  true,
+	}
+	for name, wantExists := range want {
+		err := fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: name}, &corev1.ConfigMap{})
+		if exists := err == nil; exists != wantExists {
+			t.Errorf("configmap %s exists = %v, want %v", name, exists, wantExists)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package history
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+type RevisionManager interface {
+	// SyncRoleRevisions records the spec of each role as a ControllerRevision, truncates the
+	// history to rbg.spec.revisionHistoryLimit and returns the update revision of each role.
+	SyncRoleRevisions(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (map[string]*appsv1.ControllerRevision, error)
+	// ListRoleRevisions returns the revisions of a role sorted by revision number.
+	ListRoleRevisions(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleName string) ([]*appsv1.ControllerRevision, error)
+}
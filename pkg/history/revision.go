@@ -0,0 +1,292 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// DefaultRevisionHistoryLimit is the number of revisions kept per role when rbg does not set one.
+const DefaultRevisionHistoryLimit = 10
+
+type DefaultRevisionManager struct {
+	client client.Client
+}
+
+var _ RevisionManager = &DefaultRevisionManager{}
+
+func NewDefaultRevisionManager(client client.Client) *DefaultRevisionManager {
+	return &DefaultRevisionManager{client: client}
+}
+
+func (m *DefaultRevisionManager) SyncRoleRevisions(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (map[string]*appsv1.ControllerRevision, error) {
+	revisions, err := m.listRevisions(ctx, rbg, labels.Set{workloadsv1alpha1.SetNameLabelKey: rbg.Name})
+	if err != nil {
+		return nil, err
+	}
+	roleRevisions := make(map[string][]*appsv1.ControllerRevision)
+	for _, revision := range revisions {
+		roleName := revision.Labels[workloadsv1alpha1.SetRoleLabelKey]
+		roleRevisions[roleName] = append(roleRevisions[roleName], revision)
+	}
+
+	updateRevisions := make(map[string]*appsv1.ControllerRevision, len(rbg.Spec.Roles))
+	for i := range rbg.Spec.Roles {
+		role := &rbg.Spec.Roles[i]
+		updateRevision, err := m.syncRoleRevision(ctx, rbg, role, roleRevisions[role.Name])
+		if err != nil {
+			return nil, err
+		}
+		updateRevisions[role.Name] = updateRevision
+	}
+
+	if err := m.truncateHistory(ctx, rbg, roleRevisions, updateRevisions); err != nil {
+		return nil, err
+	}
+	return updateRevisions, nil
+}
+
+func (m *DefaultRevisionManager) ListRoleRevisions(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleName string) ([]*appsv1.ControllerRevision, error) {
+	return m.listRevisions(ctx, rbg, labels.Set{
+		workloadsv1alpha1.SetNameLabelKey: rbg.Name,
+		workloadsv1alpha1.SetRoleLabelKey: roleName,
+	})
+}
+
+// listRevisions returns the revisions owned by rbg sorted by revision number.
+// The ControllerRevisions of the statefulsets of rbg carry the same labels but are owned by the statefulsets.
+func (m *DefaultRevisionManager) listRevisions(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, selector labels.Set) ([]*appsv1.ControllerRevision, error) {
+	revisions, err := utils.ListRevisions(ctx, m.client, rbg, labels.SelectorFromSet(selector))
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]*appsv1.ControllerRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		if metav1.IsControlledBy(revision, rbg) {
+			owned = append(owned, revision)
+		}
+	}
+	sort.SliceStable(owned, func(i, j int) bool {
+		return owned[i].Revision < owned[j].Revision
+	})
+	return owned, nil
+}
+
+// syncRoleRevision returns the revision matching the current role spec, creating it if it
+// does not exist and moving it to the head of the history if the role spec went back to it.
+func (m *DefaultRevisionManager) syncRoleRevision(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec, revisions []*appsv1.ControllerRevision) (*appsv1.ControllerRevision, error) {
+	logger := log.FromContext(ctx)
+
+	nextRevision := int64(1)
+	if highest := utils.GetHighestRevision(revisions); highest != nil {
+		nextRevision = highest.Revision + 1
+	}
+	updateRevision, err := NewRoleRevision(rbg, role, nextRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, revision := range revisions {
+		if !RevisionEqual(revision, updateRevision) {
+			continue
+		}
+		// the latest revision is unchanged
+		if revision.Revision == nextRevision-1 {
+			return revision, nil
+		}
+		revision = revision.DeepCopy()
+		revision.Revision = nextRevision
+		if err := m.client.Update(ctx, revision); err != nil {
+			return nil, err
+		}
+		logger.Info("reuse role revision", "revision", revision.Name, "number", revision.Revision)
+		return revision, nil
+	}
+
+	if err := m.client.Create(ctx, updateRevision); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		// revision may be created by a previous reconcile which is not in the cache yet
+		existing := &appsv1.ControllerRevision{}
+		if err := m.client.Get(ctx, client.ObjectKeyFromObject(updateRevision), existing); err != nil {
+			return nil, err
+		}
+		if !RevisionEqual(existing, updateRevision) {
+			return nil, fmt.Errorf("controller revision %s already exists with different data", updateRevision.Name)
+		}
+		return existing, nil
+	}
+	logger.Info("create role revision", "revision", updateRevision.Name, "number", updateRevision.Revision)
+	return updateRevision, nil
+}
+
+// truncateHistory deletes the revisions of removed roles and the oldest revisions beyond
+// the history limit. The current and update revisions of a role are never deleted.
+func (m *DefaultRevisionManager) truncateHistory(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	roleRevisions map[string][]*appsv1.ControllerRevision, updateRevisions map[string]*appsv1.ControllerRevision) error {
+	limit := DefaultRevisionHistoryLimit
+	if rbg.Spec.RevisionHistoryLimit != nil {
+		limit = int(*rbg.Spec.RevisionHistoryLimit)
+	}
+
+	for roleName, revisions := range roleRevisions {
+		live := map[string]bool{}
+		if updateRevision, ok := updateRevisions[roleName]; ok {
+			live[updateRevision.Name] = true
+		} else {
+			// the role was removed from rbg
+			if err := m.deleteRevisions(ctx, revisions, live, 0); err != nil {
+				return err
+			}
+			continue
+		}
+		if status, found := rbg.GetRoleStatus(roleName); found {
+			live[status.CurrentRevision] = true
+			live[status.UpdateRevision] = true
+		}
+		if err := m.deleteRevisions(ctx, revisions, live, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteRevisions keeps the newest limit revisions which are not live, revisions must be sorted.
+func (m *DefaultRevisionManager) deleteRevisions(ctx context.Context, revisions []*appsv1.ControllerRevision, live map[string]bool, limit int) error {
+	history := make([]*appsv1.ControllerRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		if !live[revision.Name] {
+			history = append(history, revision)
+		}
+	}
+	if len(history) <= limit {
+		return nil
+	}
+	for _, revision := range history[:len(history)-limit] {
+		if err := m.client.Delete(ctx, revision); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewRoleRevision snapshots the spec of role into a ControllerRevision owned by rbg.
+// Replicas and the rollout strategy are not part of the revision, so that scaling a role or
+// pausing its rollout does not create new revisions.
+func NewRoleRevision(rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec, revision int64) (*appsv1.ControllerRevision, error) {
+	roleSpec := role.DeepCopy()
+	roleSpec.Replicas = nil
+	roleSpec.RolloutStrategy = nil
+	data, err := json.Marshal(roleSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := HashRevisionData(data)
+	return &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", rbg.GetWorkloadName(role), hash),
+			Namespace: rbg.Namespace,
+			Labels: map[string]string{
+				workloadsv1alpha1.SetNameLabelKey:     rbg.Name,
+				workloadsv1alpha1.SetRoleLabelKey:     role.Name,
+				appsv1.ControllerRevisionHashLabelKey: hash,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(rbg, workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroup")),
+			},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: revision,
+	}, nil
+}
+
+// RoleSpecFromRevision restores the role spec recorded in revision, keeping the replicas and
+// the rollout strategy of role.
+func RoleSpecFromRevision(revision *appsv1.ControllerRevision, role *workloadsv1alpha1.RoleSpec) (*workloadsv1alpha1.RoleSpec, error) {
+	roleSpec := &workloadsv1alpha1.RoleSpec{}
+	if err := json.Unmarshal(revision.Data.Raw, roleSpec); err != nil {
+		return nil, err
+	}
+	roleSpec.Replicas = role.Replicas
+	roleSpec.RolloutStrategy = role.RolloutStrategy
+	return roleSpec, nil
+}
+
+// RestoreRoleTemplate replaces the templates of role with the ones recorded in revision.
+// Replicas, dependencies and the rollout strategy of role are kept.
+func RestoreRoleTemplate(role *workloadsv1alpha1.RoleSpec, revision *appsv1.ControllerRevision) error {
+	roleSpec, err := RoleSpecFromRevision(revision, role)
+	if err != nil {
+		return err
+	}
+	role.Template = roleSpec.Template
+	role.LeaderWorkerSet = roleSpec.LeaderWorkerSet
+	return nil
+}
+
+// FindRollbackRevision returns the revision numbered revision, or the revision preceding
+// updateRevision if revision is 0. Revisions must be sorted by revision number.
+func FindRollbackRevision(revisions []*appsv1.ControllerRevision, updateRevision string, revision int64) *appsv1.ControllerRevision {
+	if revision > 0 {
+		for _, r := range revisions {
+			if r.Revision == revision {
+				return r
+			}
+		}
+		return nil
+	}
+
+	updateIndex := len(revisions) - 1
+	for i, r := range revisions {
+		if r.Name == updateRevision {
+			updateIndex = i
+			break
+		}
+	}
+	if updateIndex < 1 {
+		return nil
+	}
+	return revisions[updateIndex-1]
+}
+
+// HashRevisionData returns a safe encoded fnv hash of the revision data.
+func HashRevisionData(data []byte) string {
+	hf := fnv.New32a()
+	_, _ = hf.Write(data)
+	return rand.SafeEncodeString(strconv.FormatUint(uint64(hf.Sum32()), 10))
+}
+
+// RevisionEqual reports whether the two revisions record the same role spec.
+func RevisionEqual(a, b *appsv1.ControllerRevision) bool {
+	if bytes.Equal(a.Data.Raw, b.Data.Raw) {
+		return true
+	}
+	// the apiserver may re-encode the data
+	var dataA, dataB interface{}
+	if err := json.Unmarshal(a.Data.Raw, &dataA); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b.Data.Raw, &dataB); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(dataA, dataB)
+}
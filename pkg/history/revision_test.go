@@ -0,0 +1,200 @@
+package history
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+func TestDefaultRevisionManager_SyncRoleRevisions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	setImage := func(image string) func(*workloadsv1alpha1.RoleBasedGroup) {
+		return func(rbg *workloadsv1alpha1.RoleBasedGroup) {
+			rbg.Spec.Roles[0].Template.Spec.Containers[0].Image = image
+		}
+	}
+	setReplicas := func(replicas int32) func(*workloadsv1alpha1.RoleBasedGroup) {
+		return func(rbg *workloadsv1alpha1.RoleBasedGroup) {
+			rbg.Spec.Roles[0].Replicas = ptr.To(replicas)
+		}
+	}
+	setPaused := func(paused bool) func(*workloadsv1alpha1.RoleBasedGroup) {
+		return func(rbg *workloadsv1alpha1.RoleBasedGroup) {
+			rbg.Spec.Roles[0].RolloutStrategy.Paused = paused
+		}
+	}
+
+	tests := []struct {
+		name          string
+		historyLimit  *int32
+		updates       []func(*workloadsv1alpha1.RoleBasedGroup)
+		wantRevisions int
+		wantNumber    int64
+	}{
+		{
+			name:          "first revision",
+			wantRevisions: 1,
+			wantNumber:    1,
+		},
+		{
+			name:          "scaling does not create revision",
+			updates:       []func(*workloadsv1alpha1.RoleBasedGroup){setReplicas(3)},
+			wantRevisions: 1,
+			wantNumber:    1,
+		},
+		{
+			name:          "pausing does not create revision",
+			updates:       []func(*workloadsv1alpha1.RoleBasedGroup){setPaused(true)},
+			wantRevisions: 1,
+			wantNumber:    1,
+		},
+		{
+			name:          "template change creates revision",
+			updates:       []func(*workloadsv1alpha1.RoleBasedGroup){setImage("nginx:2")},
+			wantRevisions: 2,
+			wantNumber:    2,
+		},
+		{
+			name:          "revert reuses the old revision",
+			updates:       []func(*workloadsv1alpha1.RoleBasedGroup){setImage("nginx:2"), setImage("nginx:latest")},
+			wantRevisions: 2,
+			wantNumber:    3,
+		},
+		{
+			name:          "history is truncated",
+			historyLimit:  ptr.To[int32](1),
+			updates:       []func(*workloadsv1alpha1.RoleBasedGroup){setImage("nginx:2"), setImage("nginx:3"), setImage("nginx:4")},
+			wantRevisions: 2,
+			wantNumber:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").Obj()
+			rbg.UID = types.UID("rbg-uid")
+			rbg.Spec.Roles[0].Template.Spec.Containers[0].Image = "nginx:latest"
+			rbg.Spec.RevisionHistoryLimit = tt.historyLimit
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+			manager := NewDefaultRevisionManager(fakeClient)
+
+			updateRevisions, err := manager.SyncRoleRevisions(context.TODO(), rbg)
+			if err != nil {
+				t.Fatalf("SyncRoleRevisions() error = %v", err)
+			}
+			for _, update := range tt.updates {
+				update(rbg)
+				if updateRevisions, err = manager.SyncRoleRevisions(context.TODO(), rbg); err != nil {
+					t.Fatalf("SyncRoleRevisions() error = %v", err)
+				}
+			}
+
+			roleName := rbg.Spec.Roles[0].Name
+			revisions, err := manager.ListRoleRevisions(context.TODO(), rbg, roleName)
+			if err != nil {
+				t.Fatalf("ListRoleRevisions() error = %v", err)
+			}
+			if len(revisions) != tt.wantRevisions {
+				t.Errorf("revisions = %d, want %d", len(revisions), tt.wantRevisions)
+			}
+			if got := updateRevisions[roleName].Revision; got != tt.wantNumber {
+				t.Errorf("update revision number = %d, want %d", got, tt.wantNumber)
+			}
+
+			roleSpec, err := RoleSpecFromRevision(updateRevisions[roleName], &rbg.Spec.Roles[0])
+			if err != nil {
+				t.Fatalf("RoleSpecFromRevision() error = %v", err)
+			}
+			if roleSpec.Template.Spec.Containers[0].Image != rbg.Spec.Roles[0].Template.Spec.Containers[0].Image {
+				t.Errorf("revision image = %s, want %s", roleSpec.Template.Spec.Containers[0].Image,
+					rbg.Spec.Roles[0].Template.Spec.Containers[0].Image)
+			}
+		})
+	}
+}
+
+func TestFindRollbackRevision(t *testing.T) {
+	revisions := []*appsv1.ControllerRevision{
+		{ObjectMeta: metav1.ObjectMeta{Name: "rev-a"}, Revision: 1},
+		{ObjectMeta: metav1.ObjectMeta{Name: "rev-b"}, Revision: 2},
+		{ObjectMeta: metav1.ObjectMeta{Name: "rev-c"}, Revision: 3},
+	}
+
+	tests := []struct {
+		name           string
+		updateRevision string
+		revision       int64
+		want           string
+	}{
+		{
+			name:           "previous revision of update revision",
+			updateRevision: "rev-c",
+			want:           "rev-b",
+		},
+		{
+			name: "previous revision without status",
+			want: "rev-b",
+		},
+		{
+			name:           "no revision before the first one",
+			updateRevision: "rev-a",
+			want:           "",
+		},
+		{
+			name:     "revision number",
+			revision: 1,
+			want:     "rev-a",
+		},
+		{
+			name:     "unknown revision number",
+			revision: 5,
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindRollbackRevision(revisions, tt.updateRevision, tt.revision)
+			gotName := ""
+			if got != nil {
+				gotName = got.Name
+			}
+			if gotName != tt.want {
+				t.Errorf("FindRollbackRevision() = %s, want %s", gotName, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestoreRoleTemplate(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").Obj()
+	rbg.Spec.Roles[0].Template.Spec.Containers[0].Image = "nginx:1"
+	revision, err := NewRoleRevision(rbg, &rbg.Spec.Roles[0], 1)
+	if err != nil {
+		t.Fatalf("NewRoleRevision() error = %v", err)
+	}
+
+	role := rbg.Spec.Roles[0].DeepCopy()
+	role.Template.Spec.Containers[0].Image = "nginx:2"
+	role.Replicas = ptr.To[int32](5)
+	if err := RestoreRoleTemplate(role, revision); err != nil {
+		t.Fatalf("RestoreRoleTemplate() error = %v", err)
+	}
+	if role.Template.Spec.Containers[0].Image != "nginx:1" {
+		t.Errorf("image = %s, want nginx:1", role.Template.Spec.Containers[0].Image)
+	}
+	if *role.Replicas != 5 {
+		t.Errorf("replicas = %d, want 5", *role.Replicas)
+	}
+}
@@ -0,0 +1,19 @@
+package kueue
+
+import (
+	"context"
+
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// WorkloadManager admits RoleBasedGroups as a whole through Kueue Workloads.
+type WorkloadManager interface {
+	// Reconcile creates the Workload of rbg, or recreates it when the PodSets of rbg changed,
+	// and returns the admission state of rbg.
+	Reconcile(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (State, error)
+	// ReleaseQuota gives back the quota reserved for the evicted Workload of rbg, once its roles are stopped,
+	// so that Kueue queues it again.
+	ReleaseQuota(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error
+	// Delete removes the Workload of rbg if it exists.
+	Delete(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error
+}
@@ -0,0 +1,383 @@
+package kueue
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/reconciler"
+)
+
+const (
+	// CrdName is the name of the CRD of the Kueue Workloads.
+	CrdName = "workloads.kueue.x-k8s.io"
+	// MaxPodSets is the maximum number of PodSets of a Kueue Workload, and so of roles of a rbg admitted by Kueue.
+	MaxPodSets = 8
+
+	conditionQuotaReserved = "QuotaReserved"
+	conditionAdmitted      = "Admitted"
+	conditionEvicted       = "Evicted"
+)
+
+// WorkloadGVK is the kind of the Kueue Workloads. The Kueue API is handled as unstructured objects
+// to avoid depending on Kueue.
+var WorkloadGVK = schema.GroupVersionKind{Group: "kueue.x-k8s.io", Version: "v1beta1", Kind: "Workload"}
+
+// ResourceFlavorGVK is the kind of the Kueue ResourceFlavors, the kinds of nodes the quota is reserved on.
+var ResourceFlavorGVK = schema.GroupVersionKind{Group: "kueue.x-k8s.io", Version: "v1beta1", Kind: "ResourceFlavor"}
+
+// PodSet mirrors a PodSet of a Kueue Workload, the pods of a role.
+type PodSet struct {
+	Name     string                 `json:"name"`
+	Count    int32                  `json:"count"`
+	Template corev1.PodTemplateSpec `json:"template"`
+}
+
+// workloadSpec mirrors the spec of a Kueue Workload.
+type workloadSpec struct {
+	QueueName string   `json:"queueName,omitempty"`
+	PodSets   []PodSet `json:"podSets"`
+}
+
+// workloadStatus mirrors the status of a Kueue Workload.
+type workloadStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	Admission  *admission         `json:"admission,omitempty"`
+}
+
+// admission mirrors the admission of a Kueue Workload.
+type admission struct {
+	PodSetAssignments []podSetAssignment `json:"podSetAssignments,omitempty"`
+}
+
+// podSetAssignment mirrors the resource flavors a PodSet of a Kueue Workload is admitted to,
+// by resource name.
+type podSetAssignment struct {
+	Name    string            `json:"name"`
+	Flavors map[string]string `json:"flavors,omitempty"`
+}
+
+// resourceFlavorSpec mirrors the spec of a Kueue ResourceFlavor.
+type resourceFlavorSpec struct {
+	NodeLabels  map[string]string   `json:"nodeLabels,omitempty"`
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// State is the admission state of a rbg.
+type State struct {
+	// Admitted is true once Kueue admitted the Workload of rbg, the roles of rbg can run.
+	Admitted bool
+	// Evicted is true once Kueue evicted the admitted Workload of rbg, like for a preemption.
+	// The roles of rbg have to be stopped before the quota is released.
+	Evicted bool
+	// Resizing is true once the admitted Workload of rbg is recreated because the pods of its roles changed,
+	// like when a role is scaled. The roles of rbg keep running until Kueue admits the new Workload.
+	Resizing bool
+	// Message explains the state.
+	Message string
+	// Placements constrain the pods of the admitted roles, by role name, to the nodes of the
+	// resource flavors Kueue admitted them to.
+	Placements map[string]*reconciler.Placement
+}
+
+type DefaultWorkloadManager struct {
+	scheme *runtime.Scheme
+	client client.Client
+}
+
+var _ WorkloadManager = &DefaultWorkloadManager{}
+
+func NewDefaultWorkloadManager(scheme *runtime.Scheme, client client.Client) *DefaultWorkloadManager {
+	return &DefaultWorkloadManager{scheme: scheme, client: client}
+}
+
+// NewWorkload returns an empty Kueue Workload, used to watch the Workloads owned by rbg.
+func NewWorkload() *unstructured.Unstructured {
+	wl := &unstructured.Unstructured{}
+	wl.SetGroupVersionKind(WorkloadGVK)
+	return wl
+}
+
+// WorkloadName returns the name of the Kueue Workload of rbg.
+func WorkloadName(rbg *workloadsv1alpha1.RoleBasedGroup) string {
+	return "rolebasedgroup-" + rbg.Name
+}
+
+// PodSets returns a PodSet per role of rbg, counting the pods of all the replicas of the role.
+// The template of a PodSet is the pod template of the role once the sidecars and the engine runtime
+// are injected, so that Kueue reserves the quota the pods actually request.
+func (m *DefaultWorkloadManager) PodSets(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) ([]PodSet, error) {
+	podSets := make([]PodSet, 0, len(rbg.Spec.Roles))
+	for i := range rbg.Spec.Roles {
+		role := &rbg.Spec.Roles[i]
+		var replicas int32
+		if role.Replicas != nil {
+			replicas = *role.Replicas
+		}
+		podReconciler := reconciler.NewPodReconciler(m.scheme, m.client)
+		// only the sidecars change the resources the pods request
+		podReconciler.SetInjectors([]string{"sidecar"})
+		templateApplyCfg, err := podReconciler.ConstructPodTemplateSpecApplyConfiguration(ctx, rbg, role, rbg.GetCommonLabelsFromRole(role))
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct the pod template of role %s: %w", role.Name, err)
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(templateApplyCfg)
+		if err != nil {
+			return nil, err
+		}
+		var template corev1.PodTemplateSpec
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &template); err != nil {
+			return nil, err
+		}
+		podSets = append(podSets, PodSet{
+			Name:     role.Name,
+			Count:    replicas * int32(role.GetPodsPerInstance()),
+			Template: template,
+		})
+	}
+	return podSets, nil
+}
+
+// ConstructWorkload builds the Kueue Workload admitting all the roles of rbg at once.
+func (m *DefaultWorkloadManager) ConstructWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (*unstructured.Unstructured, error) {
+	podSets, err := m.PodSets(ctx, rbg)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&workloadSpec{
+		QueueName: rbg.GetQueueName(),
+		PodSets:   podSets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wl := NewWorkload()
+	wl.SetName(WorkloadName(rbg))
+	wl.SetNamespace(rbg.Namespace)
+	wl.SetLabels(map[string]string{workloadsv1alpha1.SetNameLabelKey: rbg.Name})
+	wl.Object["spec"] = spec
+	if err := controllerutil.SetControllerReference(rbg, wl, m.scheme); err != nil {
+		return nil, err
+	}
+	return wl, nil
+}
+
+func (m *DefaultWorkloadManager) Reconcile(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) (State, error) {
+	logger := log.FromContext(ctx)
+	desired, err := m.ConstructWorkload(ctx, rbg)
+	if err != nil {
+		return State{}, err
+	}
+
+	current := NewWorkload()
+	err = m.client.Get(ctx, client.ObjectKeyFromObject(desired), current)
+	if apierrors.IsNotFound(err) {
+		logger.Info("create kueue workload", "workload", desired.GetName(), "queue", rbg.GetQueueName())
+		if err := m.client.Create(ctx, desired); err != nil {
+			return State{}, err
+		}
+		return State{Message: fmt.Sprintf("Waiting for admission by queue %s", rbg.GetQueueName())}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	if current.GetDeletionTimestamp() != nil {
+		return State{Message: "Waiting for the previous workload to be deleted"}, nil
+	}
+
+	var currentSpec, desiredSpec workloadSpec
+	if err := fromUnstructuredField(current, &currentSpec, "spec"); err != nil {
+		return State{}, err
+	}
+	if err := fromUnstructuredField(desired, &desiredSpec, "spec"); err != nil {
+		return State{}, err
+	}
+	var status workloadStatus
+	if err := fromUnstructuredField(current, &status, "status"); err != nil {
+		return State{}, err
+	}
+	evicted := apimeta.FindStatusCondition(status.Conditions, conditionEvicted)
+	isEvicted := evicted != nil && evicted.Status == metav1.ConditionTrue &&
+		apimeta.IsStatusConditionTrue(status.Conditions, conditionQuotaReserved)
+	admitted := apimeta.FindStatusCondition(status.Conditions, conditionAdmitted)
+	isAdmitted := admitted != nil && admitted.Status == metav1.ConditionTrue
+
+	if currentSpec.QueueName != desiredSpec.QueueName || !podSetsEqual(currentSpec.PodSets, desiredSpec.PodSets) {
+		// The PodSets of a Workload cannot change once admitted, Kueue admits the new Workload again.
+		logger.Info("recreate kueue workload, the pods of the roles changed", "workload", current.GetName())
+		if err := m.client.Delete(ctx, current); client.IgnoreNotFound(err) != nil {
+			return State{}, err
+		}
+		return State{Resizing: isAdmitted && !isEvicted, Message: "Waiting for the admission of the updated roles"}, nil
+	}
+
+	if isEvicted {
+		return State{Evicted: true, Message: fmt.Sprintf("Evicted by Kueue: %s", evicted.Message)}, nil
+	}
+	if isAdmitted {
+		placements, err := m.placements(ctx, status.Admission)
+		if err != nil {
+			return State{}, err
+		}
+		return State{Admitted: true, Message: admitted.Message, Placements: placements}, nil
+	}
+	return State{Message: fmt.Sprintf("Waiting for admission by queue %s", rbg.GetQueueName())}, nil
+}
+
+// placements returns the nodes the PodSets of an admission run on, the node labels and the tolerations
+// of the resource flavors the PodSets are admitted to.
+func (m *DefaultWorkloadManager) placements(ctx context.Context, admission *admission) (map[string]*reconciler.Placement, error) {
+	if admission == nil || len(admission.PodSetAssignments) == 0 {
+		return nil, nil
+	}
+	flavors := map[string]*resourceFlavorSpec{}
+	placements := make(map[string]*reconciler.Placement, len(admission.PodSetAssignments))
+	for _, assignment := range admission.PodSetAssignments {
+		placement := &reconciler.Placement{}
+		flavorNames := sets.New[string]()
+		for _, flavorName := range assignment.Flavors {
+			flavorNames.Insert(flavorName)
+		}
+		// the flavors are visited in a stable order to keep the pod templates unchanged
+		for _, flavorName := range sets.List(flavorNames) {
+			flavor, ok := flavors[flavorName]
+			if !ok {
+				var err error
+				if flavor, err = m.getResourceFlavor(ctx, flavorName); err != nil {
+					return nil, err
+				}
+				flavors[flavorName] = flavor
+			}
+			for key, value := range flavor.NodeLabels {
+				if placement.NodeSelector == nil {
+					placement.NodeSelector = map[string]string{}
+				}
+				placement.NodeSelector[key] = value
+			}
+			placement.Tolerations = append(placement.Tolerations, flavor.Tolerations...)
+		}
+		placements[assignment.Name] = placement
+	}
+	return placements, nil
+}
+
+// getResourceFlavor returns the spec of the Kueue ResourceFlavor named name.
+func (m *DefaultWorkloadManager) getResourceFlavor(ctx context.Context, name string) (*resourceFlavorSpec, error) {
+	flavor := &unstructured.Unstructured{}
+	flavor.SetGroupVersionKind(ResourceFlavorGVK)
+	if err := m.client.Get(ctx, client.ObjectKey{Name: name}, flavor); err != nil {
+		return nil, fmt.Errorf("failed to get resource flavor %s: %w", name, err)
+	}
+	spec := &resourceFlavorSpec{}
+	if err := fromUnstructuredField(flavor, spec, "spec"); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func (m *DefaultWorkloadManager) ReleaseQuota(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	wl := NewWorkload()
+	if err := m.client.Get(ctx, client.ObjectKey{Namespace: rbg.Namespace, Name: WorkloadName(rbg)}, wl); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	var status workloadStatus
+	if err := fromUnstructuredField(wl, &status, "status"); err != nil {
+		return err
+	}
+	evicted := apimeta.FindStatusCondition(status.Conditions, conditionEvicted)
+	if evicted == nil || evicted.Status != metav1.ConditionTrue || !apimeta.IsStatusConditionTrue(status.Conditions, conditionQuotaReserved) {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("release the quota of the evicted kueue workload", "workload", wl.GetName())
+	apimeta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionQuotaReserved,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Pending",
+		Message:            evicted.Message,
+		ObservedGeneration: wl.GetGeneration(),
+	})
+	if apimeta.IsStatusConditionTrue(status.Conditions, conditionAdmitted) {
+		apimeta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionAdmitted,
+			Status:             metav1.ConditionFalse,
+			Reason:             "NoReservation",
+			Message:            "The workload has no reservation",
+			ObservedGeneration: wl.GetGeneration(),
+		})
+	}
+	conditions := make([]interface{}, 0, len(status.Conditions))
+	for i := range status.Conditions {
+		condition, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status.Conditions[i])
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, condition)
+	}
+	if err := unstructured.SetNestedSlice(wl.Object, conditions, "status", "conditions"); err != nil {
+		return err
+	}
+	unstructured.RemoveNestedField(wl.Object, "status", "admission")
+	return m.client.Status().Update(ctx, wl)
+}
+
+func (m *DefaultWorkloadManager) Delete(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	wl := NewWorkload()
+	if err := m.client.Get(ctx, client.ObjectKey{Namespace: rbg.Namespace, Name: WorkloadName(rbg)}, wl); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(wl, rbg) || wl.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	return client.IgnoreNotFound(m.client.Delete(ctx, wl))
+}
+
+// fromUnstructuredField converts the field of obj at fields into out.
+func fromUnstructuredField(obj *unstructured.Unstructured, out interface{}, fields ...string) error {
+	field, found, err := unstructured.NestedMap(obj.Object, fields...)
+	if err != nil || !found {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(field, out)
+}
+
+// podSetsEqual reports whether two lists of PodSets ask for the same quota, the other changes
+// of the pod templates do not need a new admission.
+func podSetsEqual(podSets1, podSets2 []PodSet) bool {
+	if len(podSets1) != len(podSets2) {
+		return false
+	}
+	for i := range podSets1 {
+		if podSets1[i].Name != podSets2[i].Name || podSets1[i].Count != podSets2[i].Count ||
+			!apiequality.Semantic.DeepEqual(podRequests(podSets1[i].Template.Spec), podRequests(podSets2[i].Template.Spec)) {
+			return false
+		}
+	}
+	return true
+}
+
+// podRequests sums the resource requests of the containers of a pod.
+func podRequests(spec corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			total := requests[name]
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+	return requests
+}
@@ -0,0 +1,277 @@
+package kueue
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/reconciler"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+func buildKueueRbg(decodeReplicas int32) *workloadsv1alpha1.RoleBasedGroup {
+	gpuRole := func(name string, replicas int32) workloadsv1alpha1.RoleSpec {
+		role := wrappers.BuildBasicRole(name).WithReplicas(replicas).Obj()
+		role.Template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+			"nvidia.com/gpu": resource.MustParse("1"),
+		}
+		return role
+	}
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		gpuRole("prefill", 1),
+		gpuRole("decode", decodeReplicas),
+	}).Obj()
+	rbg.UID = "rbg-uid"
+	rbg.Labels = map[string]string{workloadsv1alpha1.QueueNameLabelKey: "gpu"}
+	return rbg
+}
+
+// setWorkloadConditions sets the conditions Kueue reports on the Workload of rbg.
+func setWorkloadConditions(t *testing.T, c client.Client, rbg *workloadsv1alpha1.RoleBasedGroup, conditions ...metav1.Condition) {
+	wl := NewWorkload()
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: rbg.Namespace, Name: WorkloadName(rbg)}, wl); err != nil {
+		t.Fatalf("get workload: %v", err)
+	}
+	var values []interface{}
+	for i := range conditions {
+		value, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(&conditions[i])
+		values = append(values, value)
+	}
+	_ = unstructured.SetNestedSlice(wl.Object, values, "status", "conditions")
+	_ = unstructured.SetNestedField(wl.Object, "cluster-queue", "status", "admission", "clusterQueue")
+	if err := c.Status().Update(context.TODO(), wl); err != nil {
+		t.Fatalf("update workload: %v", err)
+	}
+}
+
+func TestDefaultWorkloadManager_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+	reserved := metav1.Condition{Type: conditionQuotaReserved, Status: metav1.ConditionTrue, Reason: "QuotaReserved"}
+	admitted := metav1.Condition{Type: conditionAdmitted, Status: metav1.ConditionTrue, Reason: "Admitted"}
+	evicted := metav1.Condition{Type: conditionEvicted, Status: metav1.ConditionTrue, Reason: "Preempted", Message: "Preempted to accommodate a workload"}
+
+	tests := []struct {
+		name       string
+		conditions []metav1.Condition
+		rbg        *workloadsv1alpha1.RoleBasedGroup
+		want       State
+		wantExists bool
+	}{
+		{
+			name:       "waiting for admission",
+			rbg:        buildKueueRbg(2),
+			want:       State{Message: "Waiting for admission by queue gpu"},
+			wantExists: true,
+		},
+		{
+			name:       "admitted",
+			conditions: []metav1.Condition{reserved, admitted},
+			rbg:        buildKueueRbg(2),
+			want:       State{Admitted: true},
+			wantExists: true,
+		},
+		{
+			name:       "evicted",
+			conditions: []metav1.Condition{reserved, admitted, evicted},
+			rbg:        buildKueueRbg(2),
+			want:       State{Evicted: true, Message: "Evicted by Kueue: Preempted to accommodate a workload"},
+			wantExists: true,
+		},
+		{
+			name:       "roles scaled",
+			conditions: []metav1.Condition{reserved, admitted},
+			rbg:        buildKueueRbg(4),
+			want:       State{Resizing: true, Message: "Waiting for the admission of the updated roles"},
+			wantExists: false,
+		},
+		{
+			name:       "roles scaled before admission",
+			conditions: []metav1.Condition{reserved},
+			rbg:        buildKueueRbg(4),
+			want:       State{Message: "Waiting for the admission of the updated roles"},
+			wantExists: false,
+		},
+		{
+			name:       "evicted roles scaled",
+			conditions: []metav1.Condition{reserved, admitted, evicted},
+			rbg:        buildKueueRbg(4),
+			want:       State{Message: "Waiting for the admission of the updated roles"},
+			wantExists: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(NewWorkload()).Build()
+			m := NewDefaultWorkloadManager(scheme, fakeClient)
+
+			// the workload is first created for 2 decode replicas
+			if _, err := m.Reconcile(context.TODO(), buildKueueRbg(2)); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if len(tt.conditions) > 0 {
+				setWorkloadConditions(t, fakeClient, tt.rbg, tt.conditions...)
+			}
+
+			got, err := m.Reconcile(context.TODO(), tt.rbg)
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reconcile() = %+v, want %+v", got, tt.want)
+			}
+			err = fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: WorkloadName(tt.rbg)}, NewWorkload())
+			if exists := err == nil; exists != tt.wantExists {
+				t.Errorf("workload exists = %v, want %v", exists, tt.wantExists)
+			}
+		})
+	}
+}
+
+func TestDefaultWorkloadManager_ReleaseQuota(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	rbg := buildKueueRbg(2)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(NewWorkload()).Build()
+	m := NewDefaultWorkloadManager(scheme, fakeClient)
+	if _, err := m.Reconcile(context.TODO(), rbg); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	setWorkloadConditions(t, fakeClient, rbg,
+		metav1.Condition{Type: conditionQuotaReserved, Status: metav1.ConditionTrue, Reason: "QuotaReserved"},
+		metav1.Condition{Type: conditionAdmitted, Status: metav1.ConditionTrue, Reason: "Admitted"},
+		metav1.Condition{Type: conditionEvicted, Status: metav1.ConditionTrue, Reason: "Preempted"},
+	)
+
+	if err := m.ReleaseQuota(context.TODO(), rbg); err != nil {
+		t.Fatalf("ReleaseQuota() error = %v", err)
+	}
+	wl := NewWorkload()
+	if err := fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: WorkloadName(rbg)}, wl); err != nil {
+		t.Fatalf("get workload: %v", err)
+	}
+	var status workloadStatus
+	if err := fromUnstructuredField(wl, &status, "status"); err != nil {
+		t.Fatalf("read workload status: %v", err)
+	}
+	if apimeta.IsStatusConditionTrue(status.Conditions, conditionQuotaReserved) ||
+		apimeta.IsStatusConditionTrue(status.Conditions, conditionAdmitted) {
+		t.Errorf("ReleaseQuota() kept the reservation, conditions = %+v", status.Conditions)
+	}
+	if _, found, _ := unstructured.NestedMap(wl.Object, "status", "admission"); found {
+		t.Errorf("ReleaseQuota() kept the admission")
+	}
+}
+
+func TestPodSets(t *testing.T) {
+	lwsRole := wrappers.BuildLwsRole("worker").WithReplicas(2).Obj()
+	lwsRole.LeaderWorkerSet.Size = ptr.To[int32](4)
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("router").WithReplicas(1).Obj(),
+		lwsRole,
+	}).Obj()
+
+	rbg.Spec.Roles[0].EngineRuntimes = []workloadsv1alpha1.EngineRuntime{{ProfileName: "router-runtime"}}
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&workloadsv1alpha1.ClusterEngineRuntimeProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "router-runtime"},
+		Spec: workloadsv1alpha1.ClusterEngineRuntimeProfileSpec{
+			Containers: []corev1.Container{{
+				Name:  "runtime",
+				Image: "runtime-image",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			}},
+		},
+	}).Build()
+
+	podSets, err := NewDefaultWorkloadManager(scheme, fakeClient).PodSets(context.TODO(), rbg)
+	if err != nil {
+		t.Fatalf("PodSets() error = %v", err)
+	}
+	if len(podSets) != 2 || podSets[0].Name != "router" || podSets[0].Count != 1 ||
+		podSets[1].Name != "worker" || podSets[1].Count != 8 {
+		t.Fatalf("PodSets() = %+v, want router x1 and worker x8", podSets)
+	}
+	// the quota of the injected sidecar is requested too
+	requests := podRequests(podSets[0].Template.Spec)
+	if cpu := requests[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("1")) != 0 {
+		t.Errorf("PodSets() requests %v cpu for router, want the cpu of the runtime sidecar", cpu.String())
+	}
+}
+
+func TestDefaultWorkloadManager_Placements(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+	gpuToleration := corev1.Toleration{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+
+	flavor := &unstructured.Unstructured{}
+	flavor.SetGroupVersionKind(ResourceFlavorGVK)
+	flavor.SetName("a100")
+	flavorSpec, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(&resourceFlavorSpec{
+		NodeLabels:  map[string]string{"instance-type": "a100"},
+		Tolerations: []corev1.Toleration{gpuToleration},
+	})
+	flavor.Object["spec"] = flavorSpec
+
+	rbg := buildKueueRbg(2)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(NewWorkload()).WithObjects(flavor).Build()
+	m := NewDefaultWorkloadManager(scheme, fakeClient)
+	if _, err := m.Reconcile(context.TODO(), rbg); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	setWorkloadConditions(t, fakeClient, rbg,
+		metav1.Condition{Type: conditionQuotaReserved, Status: metav1.ConditionTrue, Reason: "QuotaReserved"},
+		metav1.Condition{Type: conditionAdmitted, Status: metav1.ConditionTrue, Reason: "Admitted"},
+	)
+	wl := NewWorkload()
+	if err := fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: WorkloadName(rbg)}, wl); err != nil {
+		t.Fatalf("get workload: %v", err)
+	}
+	var assignments []interface{}
+	for _, role := range []string{"prefill", "decode"} {
+		assignments = append(assignments, map[string]interface{}{
+			"name":    role,
+			"flavors": map[string]interface{}{"nvidia.com/gpu": "a100"},
+		})
+	}
+	_ = unstructured.SetNestedSlice(wl.Object, assignments, "status", "admission", "podSetAssignments")
+	if err := fakeClient.Status().Update(context.TODO(), wl); err != nil {
+		t.Fatalf("update workload: %v", err)
+	}
+
+	got, err := m.Reconcile(context.TODO(), rbg)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	want := map[string]*reconciler.Placement{}
+	for _, role := range []string{"prefill", "decode"} {
+		want[role] = &reconciler.Placement{
+			NodeSelector: map[string]string{"instance-type": "a100"},
+			Tolerations:  []corev1.Toleration{gpuToleration},
+		}
+	}
+	if !got.Admitted || !reflect.DeepEqual(got.Placements, want) {
+		t.Errorf("Reconcile() = %+v, want admitted with the placements %+v", got, want)
+	}
+}
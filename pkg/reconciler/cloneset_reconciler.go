@@ -0,0 +1,310 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	metaapplyv1 "k8s.io/client-go/applyconfigurations/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+var cloneSetGVK = kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet")
+
+func init() {
+	RegisterWorkload(WorkloadPlugin{
+		GVK:       cloneSetGVK,
+		CrdName:   utils.CloneSetCrdName,
+		NewObject: func() client.Object { return &kruiseappsv1alpha1.CloneSet{} },
+		NewReconciler: func(scheme *runtime.Scheme, client client.Client) WorkloadReconciler {
+			return NewCloneSetReconciler(scheme, client)
+		},
+		Equal: cloneSetEqual,
+	})
+}
+
+// CloneSetReconciler reconciles the roles running as OpenKruise CloneSets,
+// whose pods are updated in place when possible.
+type CloneSetReconciler struct {
+	scheme *runtime.Scheme
+	client client.Client
+}
+
+var _ WorkloadReconciler = &CloneSetReconciler{}
+
+func NewCloneSetReconciler(scheme *runtime.Scheme, client client.Client) *CloneSetReconciler {
+	return &CloneSetReconciler{scheme: scheme, client: client}
+}
+
+func (r *CloneSetReconciler) Reconciler(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
+	logger := log.FromContext(ctx)
+	logger.V(1).Info("start to reconciling cloneset workload")
+
+	oldCloneSet := &kruiseappsv1alpha1.CloneSet{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, oldCloneSet)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	cloneSetApplyConfig, err := r.constructCloneSetApplyConfiguration(ctx, rbg, role, oldCloneSet)
+	if err != nil {
+		logger.Error(err, "Failed to construct cloneset apply configuration")
+		return err
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cloneSetApplyConfig)
+	if err != nil {
+		logger.Error(err, "Converting obj apply configuration to json.")
+		return err
+	}
+	newCloneSet := &kruiseappsv1alpha1.CloneSet{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj, newCloneSet); err != nil {
+		return fmt.Errorf("convert cloneSetApplyConfig to cloneset error: %s", err.Error())
+	}
+
+	equal, err := semanticallyEqualCloneSet(oldCloneSet, newCloneSet)
+	if equal && !revisionChanged(ctx, oldCloneSet.Annotations) {
+		logger.Info("cloneset equal, skip reconcile")
+		return nil
+	}
+	if err != nil {
+		logger.Info(fmt.Sprintf("cloneset not equal, diff: %s", err.Error()))
+	}
+
+	if err := utils.PatchObjectApplyConfiguration(ctx, r.client, cloneSetApplyConfig, utils.PatchSpec); err != nil {
+		logger.Error(err, "Failed to patch cloneset apply configuration")
+		return err
+	}
+	return nil
+}
+
+func (r *CloneSetReconciler) constructCloneSetApplyConfiguration(
+	ctx context.Context,
+	rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec,
+	oldCloneSet *kruiseappsv1alpha1.CloneSet,
+) (*cloneSetApplyConfiguration, error) {
+	rollingStrategy, err := ValidateRolloutStrategy(role.RolloutStrategy, int(*role.Replicas))
+	if err != nil {
+		return nil, err
+	}
+
+	matchLabels := rbg.GetCommonLabelsFromRole(role)
+	if oldCloneSet.UID != "" {
+		// do not update selector when workload exists
+		matchLabels = oldCloneSet.Spec.Selector.MatchLabels
+	}
+
+	podReconciler := NewPodReconciler(r.scheme, r.client)
+	podTemplateApplyConfiguration, err := podReconciler.ConstructPodTemplateSpecApplyConfiguration(ctx, rbg, role, maps.Clone(matchLabels))
+	if err != nil {
+		return nil, err
+	}
+
+	// The partition of a CloneSet is the number of pods kept at the old revision, whatever their ordinals.
+	return &cloneSetApplyConfiguration{
+		TypeMetaApplyConfiguration: metaapplyv1.TypeMetaApplyConfiguration{
+			APIVersion: ptr.To(cloneSetGVK.GroupVersion().String()),
+			Kind:       ptr.To(cloneSetGVK.Kind),
+		},
+		ObjectMetaApplyConfiguration: kruiseObjectMeta(rbg, role, matchLabels, revisionAnnotations(ctx)),
+		Spec: &cloneSetSpecApplyConfiguration{
+			Replicas: role.Replicas,
+			Selector: metaapplyv1.LabelSelector().WithMatchLabels(matchLabels),
+			Template: podTemplateApplyConfiguration,
+			UpdateStrategy: &cloneSetUpdateStrategyApplyConfiguration{
+				Type:                  ptr.To(kruiseappsv1alpha1.CloneSetUpdateStrategyType(kruisePodUpdatePolicy(rollingStrategy.RollingUpdate))),
+				Partition:             ptr.To(intstr.FromInt32(heldReplicas(ctx, role))),
+				MaxUnavailable:        ptr.To(rollingStrategy.RollingUpdate.MaxUnavailable),
+				MaxSurge:              ptr.To(rollingStrategy.RollingUpdate.MaxSurge),
+				InPlaceUpdateStrategy: kruiseInPlaceUpdateStrategy(rollingStrategy.RollingUpdate),
+			},
+		},
+	}, nil
+}
+
+func (r *CloneSetReconciler) ConstructRoleStatus(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (workloadsv1alpha1.RoleStatus, bool, error) {
+	updateStatus := false
+	cloneSet := &kruiseappsv1alpha1.CloneSet{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, cloneSet); err != nil {
+		return workloadsv1alpha1.RoleStatus{}, updateStatus, err
+	}
+
+	status, found := rbg.GetRoleStatus(role.Name)
+	newStatus := workloadsv1alpha1.RoleStatus{
+		Name:                 role.Name,
+		Replicas:             ptr.Deref(cloneSet.Spec.Replicas, 1),
+		ReadyReplicas:        cloneSet.Status.ReadyReplicas,
+		UpdatedReplicas:      cloneSet.Status.UpdatedReplicas,
+		UpdatedReadyReplicas: cloneSet.Status.UpdatedReadyReplicas,
+		CurrentRevision:      status.CurrentRevision,
+		UpdateRevision:       status.UpdateRevision,
+		ObservedGeneration:   status.ObservedGeneration,
+		Conditions:           status.Conditions,
+	}
+	if !found || !apiequality.Semantic.DeepEqual(status, newStatus) {
+		status = newStatus
+		updateStatus = true
+	}
+	return status, updateStatus, nil
+}
+
+func (r *CloneSetReconciler) RolloutState(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (*RolloutState, error) {
+	cloneSet := &kruiseappsv1alpha1.CloneSet{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, cloneSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// surge pods are created at the update revision, do not count them as updated replicas of the role
+	surge := utils.NonZeroValue(cloneSet.Status.Replicas - *role.Replicas)
+	return &RolloutState{
+		Revision:        cloneSet.Annotations[workloadsv1alpha1.RevisionAnnotationKey],
+		UpdatedReplicas: utils.NonZeroValue(cloneSet.Status.UpdatedReplicas - surge),
+		ReadyReplicas:   cloneSet.Status.ReadyReplicas,
+		Observed:        cloneSet.Status.ObservedGeneration >= cloneSet.Generation,
+	}, nil
+}
+
+func (r *CloneSetReconciler) CheckWorkloadReady(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (bool, error) {
+	cloneSet := &kruiseappsv1alpha1.CloneSet{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, cloneSet); err != nil {
+		return false, err
+	}
+	return cloneSet.Status.ReadyReplicas == ptr.Deref(cloneSet.Spec.Replicas, 1), nil
+}
+
+func (r *CloneSetReconciler) CleanupOrphanedWorkloads(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	logger := log.FromContext(ctx)
+	if err := utils.CheckCrdExists(r.client, utils.CloneSetCrdName); err != nil {
+		logger.Info(fmt.Sprintf("CloneSetReconciler CleanupOrphanedWorkloads check crd failed: %s", err.Error()))
+		return nil
+	}
+	// list cloneset managed by rbg
+	cloneSetList := &kruiseappsv1alpha1.CloneSetList{}
+	if err := r.client.List(ctx, cloneSetList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels(map[string]string{
+			workloadsv1alpha1.SetNameLabelKey: rbg.Name,
+		}),
+	); err != nil {
+		return err
+	}
+
+	for _, cloneSet := range cloneSetList.Items {
+		if !metav1.IsControlledBy(&cloneSet, rbg) {
+			continue
+		}
+		found := false
+		for _, role := range rbg.Spec.Roles {
+			if role.Workload.String() == workloadsv1alpha1.CloneSetWorkloadType && rbg.GetWorkloadName(&role) == cloneSet.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			logger.Info("delete cloneset", "cloneset", cloneSet.Name)
+			if err := r.client.Delete(ctx, &cloneSet); err != nil {
+				return fmt.Errorf("delete cloneset %s error: %s", cloneSet.Name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// RecreateWorkload deletes the cloneset of role with its pods in the foreground, it is created again
+// by the next reconciliation of rbg.
+func (r *CloneSetReconciler) RecreateWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
+	logger := log.FromContext(ctx)
+	if rbg == nil || role == nil {
+		return nil
+	}
+
+	cloneSetName := rbg.GetWorkloadName(role)
+	var cloneSet kruiseappsv1alpha1.CloneSet
+	err := r.client.Get(ctx, types.NamespacedName{Name: cloneSetName, Namespace: rbg.Namespace}, &cloneSet)
+	// if cloneset is not found, skip delete cloneset
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("Recreate cloneset workload, delete cloneset %s", cloneSetName))
+	if err := r.client.Delete(ctx, &cloneSet, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// cloneSetEqual determines whether the update of a CloneSet needs no reconciliation.
+func cloneSetEqual(oldObj, newObj client.Object) (bool, error) {
+	o1, o2 := oldObj.(*kruiseappsv1alpha1.CloneSet), newObj.(*kruiseappsv1alpha1.CloneSet)
+	// check spec
+	if equal, err := semanticallyEqualCloneSet(o1, o2); !equal {
+		return false, fmt.Errorf("cloneset not equal, error: %s", err.Error())
+	}
+	// check status
+	if o1.Status.ReadyReplicas != o2.Status.ReadyReplicas {
+		return false, fmt.Errorf("ReadyReplicas not equal, old: %d, new: %d", o1.Status.ReadyReplicas, o2.Status.ReadyReplicas)
+	}
+	return true, nil
+}
+
+func semanticallyEqualCloneSet(oldCloneSet, newCloneSet *kruiseappsv1alpha1.CloneSet) (bool, error) {
+	if oldCloneSet == nil || oldCloneSet.UID == "" {
+		return false, errors.New("old cloneset not exist")
+	}
+	if newCloneSet == nil {
+		return false, fmt.Errorf("new cloneset is nil")
+	}
+
+	if equal, err := objectMetaEqual(oldCloneSet.ObjectMeta, newCloneSet.ObjectMeta); !equal {
+		return false, fmt.Errorf("objectMeta not equal: %s", err.Error())
+	}
+
+	if equal, err := cloneSetSpecEqual(oldCloneSet.Spec, newCloneSet.Spec); !equal {
+		return false, fmt.Errorf("spec not equal: %s", err.Error())
+	}
+	return true, nil
+}
+
+func cloneSetSpecEqual(spec1, spec2 kruiseappsv1alpha1.CloneSetSpec) (bool, error) {
+	if ptr.Deref(spec1.Replicas, 1) != ptr.Deref(spec2.Replicas, 1) {
+		return false, fmt.Errorf("replicas not equal, old: %d, new: %d", ptr.Deref(spec1.Replicas, 1), ptr.Deref(spec2.Replicas, 1))
+	}
+
+	if !reflect.DeepEqual(spec1.Selector, spec2.Selector) {
+		return false, fmt.Errorf("selector not equal, old: %v, new: %v", spec1.Selector, spec2.Selector)
+	}
+
+	strategy1, strategy2 := spec1.UpdateStrategy, spec2.UpdateStrategy
+	if strategy1.Type != strategy2.Type ||
+		!reflect.DeepEqual(strategy1.Partition, strategy2.Partition) ||
+		!reflect.DeepEqual(strategy1.MaxUnavailable, strategy2.MaxUnavailable) ||
+		!reflect.DeepEqual(strategy1.MaxSurge, strategy2.MaxSurge) ||
+		!reflect.DeepEqual(strategy1.InPlaceUpdateStrategy, strategy2.InPlaceUpdateStrategy) {
+		return false, fmt.Errorf("updateStrategy not equal, old: %v, new: %v", strategy1, strategy2)
+	}
+
+	if equal, err := podTemplateSpecEqual(spec1.Template, spec2.Template); !equal {
+		return false, fmt.Errorf("podTemplateSpec not equal, %s", err.Error())
+	}
+
+	return true, nil
+}
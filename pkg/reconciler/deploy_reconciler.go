@@ -5,12 +5,11 @@ import (
 	"errors"
 	"fmt"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"maps"
 	"reflect"
-	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -22,6 +21,20 @@ import (
 	"sigs.k8s.io/rbgs/pkg/utils"
 )
 
+// deploymentRevisionAnnotation is the revision the deployment controller records on a deployment and its ReplicaSets.
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+func init() {
+	RegisterWorkload(WorkloadPlugin{
+		GVK:       appsv1.SchemeGroupVersion.WithKind("Deployment"),
+		NewObject: func() client.Object { return &appsv1.Deployment{} },
+		NewReconciler: func(scheme *runtime.Scheme, client client.Client) WorkloadReconciler {
+			return NewDeploymentReconciler(scheme, client)
+		},
+		Equal: deploymentEqual,
+	})
+}
+
 type DeploymentReconciler struct {
 	scheme *runtime.Scheme
 	client client.Client
@@ -59,12 +72,14 @@ func (r *DeploymentReconciler) Reconciler(ctx context.Context, rbg *workloadsv1a
 	}
 
 	equal, err := SemanticallyEqualDeployment(oldDeploy, newDeploy)
-	if equal {
+	if equal && !revisionChanged(ctx, oldDeploy.Annotations) {
 		logger.Info("deployment equal, skip reconcile")
 		return nil
 	}
 
-	logger.Info(fmt.Sprintf("deployment not equal, diff: %s", err.Error()))
+	if err != nil {
+		logger.Info(fmt.Sprintf("deployment not equal, diff: %s", err.Error()))
+	}
 
 	if err := utils.PatchObjectApplyConfiguration(ctx, r.client, deployApplyConfig, utils.PatchSpec); err != nil {
 		logger.Error(err, "Failed to patch deployment apply configuration")
@@ -99,6 +114,7 @@ func (r *DeploymentReconciler) constructDeployApplyConfiguration(
 			WithSelector(metaapplyv1.LabelSelector().
 				WithMatchLabels(matchLabels))).
 		WithAnnotations(rbg.GetCommonAnnotationsFromRole(role)).
+		WithAnnotations(revisionAnnotations(ctx)).
 		WithLabels(matchLabels).
 		WithOwnerReferences(metaapplyv1.OwnerReference().
 			WithAPIVersion(rbg.APIVersion).
@@ -118,6 +134,10 @@ func (r *DeploymentReconciler) constructDeployApplyConfiguration(
 				),
 			))
 	}
+	// A deployment can not hold back part of its replicas, it is paused until the whole role is released.
+	if held := heldReplicas(ctx, role); held > 0 && held >= *role.Replicas {
+		deployConfig.Spec.WithPaused(true)
+	}
 	return deployConfig, nil
 
 }
@@ -133,21 +153,68 @@ func (r *DeploymentReconciler) ConstructRoleStatus(
 		return workloadsv1alpha1.RoleStatus{}, false, err
 	}
 
-	currentReplicas := *deploy.Spec.Replicas
-	currentReady := deploy.Status.ReadyReplicas
+	updatedReady, err := r.updatedReadyReplicas(ctx, deploy)
+	if err != nil {
+		return workloadsv1alpha1.RoleStatus{}, false, err
+	}
+
 	status, found := rbg.GetRoleStatus(role.Name)
-	if !found || status.Replicas != currentReplicas || status.ReadyReplicas != currentReady {
-		status = workloadsv1alpha1.RoleStatus{
-			Name:          role.Name,
-			Replicas:      currentReplicas,
-			ReadyReplicas: currentReady,
-		}
+	newStatus := workloadsv1alpha1.RoleStatus{
+		Name:                 role.Name,
+		Replicas:             *deploy.Spec.Replicas,
+		ReadyReplicas:        deploy.Status.ReadyReplicas,
+		UpdatedReplicas:      deploy.Status.UpdatedReplicas,
+		UpdatedReadyReplicas: updatedReady,
+		CurrentRevision:      status.CurrentRevision,
+		UpdateRevision:       status.UpdateRevision,
+		ObservedGeneration:   status.ObservedGeneration,
+		Conditions:           status.Conditions,
+	}
+	if !found || !apiequality.Semantic.DeepEqual(status, newStatus) {
+		status = newStatus
 		updateStatus = true
 	}
 
 	return status, updateStatus, nil
 }
 
+// updatedReadyReplicas returns the ready replicas of the newest ReplicaSet of deploy.
+func (r *DeploymentReconciler) updatedReadyReplicas(ctx context.Context, deploy *appsv1.Deployment) (int32, error) {
+	revision := deploy.Annotations[deploymentRevisionAnnotation]
+	if deploy.Status.UpdatedReplicas == 0 || revision == "" || deploy.Spec.Selector == nil {
+		return 0, nil
+	}
+
+	rsList := &appsv1.ReplicaSetList{}
+	if err := r.client.List(ctx, rsList, client.InNamespace(deploy.Namespace),
+		client.MatchingLabels(deploy.Spec.Selector.MatchLabels)); err != nil {
+		return 0, err
+	}
+	for _, rs := range rsList.Items {
+		if metav1.IsControlledBy(&rs, deploy) && rs.Annotations[deploymentRevisionAnnotation] == revision {
+			return rs.Status.ReadyReplicas, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *DeploymentReconciler) RolloutState(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (*RolloutState, error) {
+	deploy := &appsv1.Deployment{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, deploy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &RolloutState{
+		Revision:        deploy.Annotations[workloadsv1alpha1.RevisionAnnotationKey],
+		UpdatedReplicas: deploy.Status.UpdatedReplicas,
+		ReadyReplicas:   deploy.Status.ReadyReplicas,
+		Observed:        deploy.Status.ObservedGeneration >= deploy.Generation,
+	}, nil
+}
+
 func (r *DeploymentReconciler) CheckWorkloadReady(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (bool, error) {
 	deploy := &appsv1.Deployment{}
 	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, deploy); err != nil {
@@ -189,6 +256,8 @@ func (r *DeploymentReconciler) CleanupOrphanedWorkloads(ctx context.Context, rbg
 	return nil
 }
 
+// RecreateWorkload deletes the deployment of role with its pods in the foreground, it is created again
+// by the next reconciliation of rbg.
 func (r *DeploymentReconciler) RecreateWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
 	logger := log.FromContext(ctx)
 	if rbg == nil || role == nil {
@@ -207,30 +276,25 @@ func (r *DeploymentReconciler) RecreateWorkload(ctx context.Context, rbg *worklo
 	}
 
 	logger.Info(fmt.Sprintf("Recreate deployment workload, delete deployment %s", deployName))
-	if err := r.client.Delete(ctx, &deploy); err != nil && !apierrors.IsNotFound(err) {
+	if err := r.client.Delete(ctx, &deploy, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 
-	// wait new deployment create
-	var retErr error
-	err = wait.PollUntilContextTimeout(ctx, 5*time.Second, 5*time.Minute, true, func(ctx context.Context) (bool, error) {
-		var newDeploy appsv1.Deployment
-		retErr = r.client.Get(ctx, types.NamespacedName{Name: deployName, Namespace: rbg.Namespace}, &newDeploy)
-		if retErr != nil {
-			if apierrors.IsNotFound(retErr) {
-				return false, nil
-			}
-			return false, retErr
-		}
-		return true, nil
-	})
+	return nil
+}
 
-	if err != nil {
-		logger.Error(retErr, "wait new deployment creating error")
-		return retErr
+// deploymentEqual determines whether the update of a Deployment needs no reconciliation.
+func deploymentEqual(oldObj, newObj client.Object) (bool, error) {
+	o1, o2 := oldObj.(*appsv1.Deployment), newObj.(*appsv1.Deployment)
+	// check spec
+	if equal, err := SemanticallyEqualDeployment(o1, o2); !equal {
+		return false, fmt.Errorf("deploy not equal, error: %s", err.Error())
 	}
-
-	return nil
+	// check status
+	if o1.Status.ReadyReplicas != o2.Status.ReadyReplicas {
+		return false, fmt.Errorf("ReadyReplicas not equal, old: %d, new: %d", o1.Status.ReadyReplicas, o2.Status.ReadyReplicas)
+	}
+	return true, nil
 }
 
 func SemanticallyEqualDeployment(oldDeploy, newDeploy *appsv1.Deployment) (bool, error) {
@@ -259,6 +323,10 @@ func deploymentSpecEqual(spec1, spec2 appsv1.DeploymentSpec) (bool, error) {
 		}
 	}
 
+	if spec1.Paused != spec2.Paused {
+		return false, fmt.Errorf("paused not equal, old: %t, new: %t", spec1.Paused, spec2.Paused)
+	}
+
 	if !reflect.DeepEqual(spec1.Selector, spec2.Selector) {
 		return false, fmt.Errorf("selector not equal, old: %v, new: %v", spec1.Selector, spec2.Selector)
 	}
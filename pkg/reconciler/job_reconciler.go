@@ -0,0 +1,334 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	batchapplyv1 "k8s.io/client-go/applyconfigurations/batch/v1"
+	metaapplyv1 "k8s.io/client-go/applyconfigurations/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// jobControllerLabels are the labels the job controller adds to the pod template of a job.
+var jobControllerLabels = []string{
+	batchv1.ControllerUidLabel,
+	batchv1.JobNameLabel,
+	"controller-uid",
+	"job-name",
+}
+
+func init() {
+	RegisterWorkload(WorkloadPlugin{
+		GVK:       batchv1.SchemeGroupVersion.WithKind("Job"),
+		NewObject: func() client.Object { return &batchv1.Job{} },
+		NewReconciler: func(scheme *runtime.Scheme, client client.Client) WorkloadReconciler {
+			return NewJobReconciler(scheme, client)
+		},
+		Equal: jobEqual,
+	})
+}
+
+// JobReconciler reconciles the one-shot roles running as Jobs, like model downloads or warm-ups.
+// A role of Job is ready once its Job is complete, the replicas of the role are the completions of the Job.
+type JobReconciler struct {
+	scheme *runtime.Scheme
+	client client.Client
+}
+
+var _ WorkloadReconciler = &JobReconciler{}
+var _ WorkloadFailureReader = &JobReconciler{}
+
+func NewJobReconciler(scheme *runtime.Scheme, client client.Client) *JobReconciler {
+	return &JobReconciler{scheme: scheme, client: client}
+}
+
+func (r *JobReconciler) Reconciler(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
+	logger := log.FromContext(ctx)
+	logger.V(1).Info("start to reconciling job workload")
+
+	oldJob := &batchv1.Job{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, oldJob)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if oldJob.DeletionTimestamp != nil {
+		logger.Info("job is being deleted, wait for it to be recreated")
+		return nil
+	}
+
+	jobApplyConfig, err := r.constructJobApplyConfiguration(ctx, rbg, role)
+	if err != nil {
+		logger.Error(err, "Failed to construct job apply configuration")
+		return err
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(jobApplyConfig)
+	if err != nil {
+		logger.Error(err, "Converting obj apply configuration to json.")
+		return err
+	}
+	newJob := &batchv1.Job{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj, newJob); err != nil {
+		return fmt.Errorf("convert jobApplyConfig to job error: %s", err.Error())
+	}
+
+	equal, err := SemanticallyEqualJob(oldJob, newJob)
+	if equal && !revisionChanged(ctx, oldJob.Annotations) {
+		logger.Info("job equal, skip reconcile")
+		return nil
+	}
+	if err != nil {
+		logger.Info(fmt.Sprintf("job not equal, diff: %s", err.Error()))
+	}
+
+	// The pod template and completions of a job are immutable, run the job again with the new spec.
+	if oldJob.UID != "" && !jobImmutableFieldsEqual(oldJob.Spec, newJob.Spec) {
+		logger.Info(fmt.Sprintf("Recreate job workload, delete job %s", oldJob.Name))
+		if err := r.client.Delete(ctx, oldJob, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := utils.PatchObjectApplyConfiguration(ctx, r.client, jobApplyConfig, utils.PatchSpec); err != nil {
+		logger.Error(err, "Failed to patch job apply configuration")
+		return err
+	}
+	return nil
+}
+
+func (r *JobReconciler) constructJobApplyConfiguration(
+	ctx context.Context,
+	rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec,
+) (*batchapplyv1.JobApplyConfiguration, error) {
+	labels := rbg.GetCommonLabelsFromRole(role)
+
+	// pods of a job can not be restarted always, the webhook rejects it.
+	// Restart the failed containers unless told otherwise.
+	template := *role.Template.DeepCopy()
+	if template.Spec.RestartPolicy == "" {
+		template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+	}
+
+	podReconciler := NewPodReconciler(r.scheme, r.client)
+	podTemplateApplyConfiguration, err := podReconciler.ConstructPodTemplateSpecApplyConfiguration(ctx, rbg, role, maps.Clone(labels), template)
+	if err != nil {
+		return nil, err
+	}
+
+	// the selector of a job is generated by the job controller
+	jobConfig := batchapplyv1.Job(rbg.GetWorkloadName(role), rbg.Namespace).
+		WithSpec(batchapplyv1.JobSpec().
+			WithParallelism(*role.Replicas).
+			WithCompletions(*role.Replicas).
+			WithTemplate(podTemplateApplyConfiguration)).
+		WithAnnotations(rbg.GetCommonAnnotationsFromRole(role)).
+		WithAnnotations(revisionAnnotations(ctx)).
+		WithLabels(labels).
+		WithOwnerReferences(metaapplyv1.OwnerReference().
+			WithAPIVersion(rbg.APIVersion).
+			WithKind(rbg.Kind).
+			WithName(rbg.Name).
+			WithUID(rbg.GetUID()).
+			WithBlockOwnerDeletion(true).
+			WithController(true),
+		)
+	return jobConfig, nil
+}
+
+func (r *JobReconciler) ConstructRoleStatus(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (workloadsv1alpha1.RoleStatus, bool, error) {
+	updateStatus := false
+	job := &batchv1.Job{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, job); err != nil {
+		return workloadsv1alpha1.RoleStatus{}, updateStatus, err
+	}
+
+	// the replicas of a job are ready once they succeeded
+	completions := ptr.Deref(job.Spec.Completions, 1)
+	succeeded := min(job.Status.Succeeded, completions)
+	status, found := rbg.GetRoleStatus(role.Name)
+	newStatus := workloadsv1alpha1.RoleStatus{
+		Name:                 role.Name,
+		Replicas:             completions,
+		ReadyReplicas:        succeeded,
+		UpdatedReplicas:      completions,
+		UpdatedReadyReplicas: succeeded,
+		CurrentRevision:      status.CurrentRevision,
+		UpdateRevision:       status.UpdateRevision,
+		ObservedGeneration:   status.ObservedGeneration,
+		Conditions:           status.Conditions,
+	}
+	if !found || !apiequality.Semantic.DeepEqual(status, newStatus) {
+		status = newStatus
+		updateStatus = true
+	}
+	return status, updateStatus, nil
+}
+
+func (r *JobReconciler) CheckWorkloadReady(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (bool, error) {
+	job := &batchv1.Job{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, job); err != nil {
+		// the job is recreated when its spec changes
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return jobConditionTrue(job, batchv1.JobComplete), nil
+}
+
+func (r *JobReconciler) WorkloadFailure(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (string, error) {
+	job := &batchv1.Job{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, job); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return fmt.Sprintf("job %s failed: %s: %s", job.Name, condition.Reason, condition.Message), nil
+		}
+	}
+	return "", nil
+}
+
+func (r *JobReconciler) CleanupOrphanedWorkloads(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	logger := log.FromContext(ctx)
+	// list job managed by rbg
+	jobList := &batchv1.JobList{}
+	if err := r.client.List(ctx, jobList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels(map[string]string{
+			workloadsv1alpha1.SetNameLabelKey: rbg.Name,
+		}),
+	); err != nil {
+		return err
+	}
+
+	for _, job := range jobList.Items {
+		if !metav1.IsControlledBy(&job, rbg) {
+			continue
+		}
+		found := false
+		for _, role := range rbg.Spec.Roles {
+			if role.Workload.String() == workloadsv1alpha1.JobWorkloadType && rbg.GetWorkloadName(&role) == job.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			logger.Info("delete job", "job", job.Name)
+			if err := r.client.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+				return fmt.Errorf("delete job %s error: %s", job.Name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// RecreateWorkload deletes the job of role with its pods in the foreground, it is run again
+// with the next reconciliation of rbg.
+func (r *JobReconciler) RecreateWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
+	logger := log.FromContext(ctx)
+	if rbg == nil || role == nil {
+		return nil
+	}
+
+	jobName := rbg.GetWorkloadName(role)
+	var job batchv1.Job
+	err := r.client.Get(ctx, types.NamespacedName{Name: jobName, Namespace: rbg.Namespace}, &job)
+	// if job is not found, skip delete job
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("Recreate job workload, delete job %s", jobName))
+	if err := r.client.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// jobEqual determines whether the update of a Job needs no reconciliation.
+func jobEqual(oldObj, newObj client.Object) (bool, error) {
+	o1, o2 := oldObj.(*batchv1.Job), newObj.(*batchv1.Job)
+	// check spec
+	if equal, err := SemanticallyEqualJob(o1, o2); !equal {
+		return false, fmt.Errorf("job not equal, error: %s", err.Error())
+	}
+	// check status
+	if o1.Status.Succeeded != o2.Status.Succeeded {
+		return false, fmt.Errorf("Succeeded not equal, old: %d, new: %d", o1.Status.Succeeded, o2.Status.Succeeded)
+	}
+	if jobConditionTrue(o1, batchv1.JobComplete) != jobConditionTrue(o2, batchv1.JobComplete) ||
+		jobConditionTrue(o1, batchv1.JobFailed) != jobConditionTrue(o2, batchv1.JobFailed) {
+		return false, fmt.Errorf("job finished")
+	}
+	return true, nil
+}
+
+func SemanticallyEqualJob(oldJob, newJob *batchv1.Job) (bool, error) {
+	if oldJob == nil || oldJob.UID == "" {
+		return false, errors.New("old job not exist")
+	}
+	if newJob == nil {
+		return false, fmt.Errorf("new job is nil")
+	}
+
+	if equal, err := objectMetaEqual(oldJob.ObjectMeta, newJob.ObjectMeta); !equal {
+		return false, fmt.Errorf("objectMeta not equal: %s", err.Error())
+	}
+
+	if ptr.Deref(oldJob.Spec.Parallelism, 1) != ptr.Deref(newJob.Spec.Parallelism, 1) {
+		return false, fmt.Errorf("parallelism not equal, old: %d, new: %d",
+			ptr.Deref(oldJob.Spec.Parallelism, 1), ptr.Deref(newJob.Spec.Parallelism, 1))
+	}
+
+	if !jobImmutableFieldsEqual(oldJob.Spec, newJob.Spec) {
+		return false, fmt.Errorf("completions or podTemplateSpec not equal")
+	}
+	return true, nil
+}
+
+// jobImmutableFieldsEqual compares the fields of the jobs which can not be updated.
+func jobImmutableFieldsEqual(spec1, spec2 batchv1.JobSpec) bool {
+	if ptr.Deref(spec1.Completions, 1) != ptr.Deref(spec2.Completions, 1) {
+		return false
+	}
+
+	template1, template2 := *spec1.Template.DeepCopy(), *spec2.Template.DeepCopy()
+	for _, label := range jobControllerLabels {
+		delete(template1.Labels, label)
+		delete(template2.Labels, label)
+	}
+	if template1.Spec.RestartPolicy != template2.Spec.RestartPolicy {
+		return false
+	}
+	equal, _ := podTemplateSpecEqual(template1, template2)
+	return equal
+}
+
+func jobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func TestJobReconciler_ReadyAndFailure(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = batchv1.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+	}
+	role := &workloadsv1alpha1.RoleSpec{Name: "model-download", Replicas: ptr.To[int32](2)}
+	newJob := func(succeeded int32, conditions ...batchv1.JobCondition) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-rbg-model-download", Namespace: "default"},
+			Spec:       batchv1.JobSpec{Completions: ptr.To[int32](2)},
+			Status:     batchv1.JobStatus{Succeeded: succeeded, Conditions: conditions},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		job         *batchv1.Job
+		wantReady   bool
+		wantReplica int32
+		wantFailure bool
+	}{
+		{
+			name:        "running",
+			job:         newJob(1),
+			wantReplica: 1,
+		},
+		{
+			name:        "complete",
+			job:         newJob(2, batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}),
+			wantReady:   true,
+			wantReplica: 2,
+		},
+		{
+			name: "failed",
+			job: newJob(0, batchv1.JobCondition{
+				Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded",
+			}),
+			wantFailure: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewJobReconciler(scheme, fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.job).Build())
+			ctx := context.Background()
+
+			ready, err := r.CheckWorkloadReady(ctx, rbg, role)
+			if err != nil || ready != tt.wantReady {
+				t.Errorf("CheckWorkloadReady() = %v, %v, want %v", ready, err, tt.wantReady)
+			}
+			status, _, err := r.ConstructRoleStatus(ctx, rbg, role)
+			if err != nil || status.ReadyReplicas != tt.wantReplica {
+				t.Errorf("ConstructRoleStatus() readyReplicas = %d, %v, want %d", status.ReadyReplicas, err, tt.wantReplica)
+			}
+			failure, err := r.WorkloadFailure(ctx, rbg, role)
+			if err != nil || (failure != "") != tt.wantFailure {
+				t.Errorf("WorkloadFailure() = %q, %v, want failure %v", failure, err, tt.wantFailure)
+			}
+		})
+	}
+}
+
+func Test_jobImmutableFieldsEqual(t *testing.T) {
+	spec := batchv1.JobSpec{
+		Completions: ptr.To[int32](1),
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyOnFailure,
+				Containers:    []corev1.Container{{Name: "download", Image: "downloader:v1"}},
+			},
+		},
+	}
+	// the job controller labels the pod template of the created job
+	createdSpec := spec.DeepCopy()
+	createdSpec.Template.Labels = map[string]string{
+		batchv1.ControllerUidLabel: "uid",
+		batchv1.JobNameLabel:       "job",
+		"controller-uid":           "uid",
+		"job-name":                 "job",
+	}
+	updatedSpec := spec.DeepCopy()
+	updatedSpec.Template.Spec.Containers[0].Image = "downloader:v2"
+
+	if !jobImmutableFieldsEqual(*createdSpec, spec) {
+		t.Errorf("jobImmutableFieldsEqual() = false for the labels of the job controller")
+	}
+	if jobImmutableFieldsEqual(spec, *updatedSpec) {
+		t.Errorf("jobImmutableFieldsEqual() = true with a new image")
+	}
+}
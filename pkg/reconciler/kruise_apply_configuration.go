@@ -0,0 +1,99 @@
+package reconciler
+
+import (
+	kruiseappspub "github.com/openkruise/kruise-api/apps/pub"
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	coreapplyv1 "k8s.io/client-go/applyconfigurations/core/v1"
+	metaapplyv1 "k8s.io/client-go/applyconfigurations/meta/v1"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// kruise-api does not generate apply configurations, the types below only hold the fields
+// managed by the rbg controller.
+
+type kruiseStatefulSetApplyConfiguration struct {
+	metaapplyv1.TypeMetaApplyConfiguration    `json:",inline"`
+	*metaapplyv1.ObjectMetaApplyConfiguration `json:"metadata,omitempty"`
+	Spec                                      *kruiseStatefulSetSpecApplyConfiguration `json:"spec,omitempty"`
+}
+
+type kruiseStatefulSetSpecApplyConfiguration struct {
+	Replicas            *int32                                         `json:"replicas,omitempty"`
+	Selector            *metaapplyv1.LabelSelectorApplyConfiguration   `json:"selector,omitempty"`
+	Template            *coreapplyv1.PodTemplateSpecApplyConfiguration `json:"template,omitempty"`
+	ServiceName         *string                                        `json:"serviceName,omitempty"`
+	PodManagementPolicy *appsv1.PodManagementPolicyType                `json:"podManagementPolicy,omitempty"`
+	UpdateStrategy      *kruiseStatefulSetUpdateStrategyConfiguration  `json:"updateStrategy,omitempty"`
+}
+
+type kruiseStatefulSetUpdateStrategyConfiguration struct {
+	Type          *appsv1.StatefulSetUpdateStrategyType             `json:"type,omitempty"`
+	RollingUpdate *kruiseRollingUpdateStatefulSetApplyConfiguration `json:"rollingUpdate,omitempty"`
+}
+
+type kruiseRollingUpdateStatefulSetApplyConfiguration struct {
+	Partition             *int32                                   `json:"partition,omitempty"`
+	MaxUnavailable        *intstr.IntOrString                      `json:"maxUnavailable,omitempty"`
+	PodUpdatePolicy       *kruiseappsv1beta1.PodUpdateStrategyType `json:"podUpdatePolicy,omitempty"`
+	InPlaceUpdateStrategy *kruiseappspub.InPlaceUpdateStrategy     `json:"inPlaceUpdateStrategy,omitempty"`
+}
+
+type cloneSetApplyConfiguration struct {
+	metaapplyv1.TypeMetaApplyConfiguration    `json:",inline"`
+	*metaapplyv1.ObjectMetaApplyConfiguration `json:"metadata,omitempty"`
+	Spec                                      *cloneSetSpecApplyConfiguration `json:"spec,omitempty"`
+}
+
+type cloneSetSpecApplyConfiguration struct {
+	Replicas       *int32                                         `json:"replicas,omitempty"`
+	Selector       *metaapplyv1.LabelSelectorApplyConfiguration   `json:"selector,omitempty"`
+	Template       *coreapplyv1.PodTemplateSpecApplyConfiguration `json:"template,omitempty"`
+	UpdateStrategy *cloneSetUpdateStrategyApplyConfiguration      `json:"updateStrategy,omitempty"`
+}
+
+type cloneSetUpdateStrategyApplyConfiguration struct {
+	Type                  *kruiseappsv1alpha1.CloneSetUpdateStrategyType `json:"type,omitempty"`
+	Partition             *intstr.IntOrString                            `json:"partition,omitempty"`
+	MaxUnavailable        *intstr.IntOrString                            `json:"maxUnavailable,omitempty"`
+	MaxSurge              *intstr.IntOrString                            `json:"maxSurge,omitempty"`
+	InPlaceUpdateStrategy *kruiseappspub.InPlaceUpdateStrategy           `json:"inPlaceUpdateStrategy,omitempty"`
+}
+
+// kruiseObjectMeta returns the metadata of the OpenKruise workload of role.
+func kruiseObjectMeta(rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec,
+	labels, annotations map[string]string) *metaapplyv1.ObjectMetaApplyConfiguration {
+	meta := &metaapplyv1.ObjectMetaApplyConfiguration{}
+	meta.WithName(rbg.GetWorkloadName(role)).
+		WithNamespace(rbg.Namespace).
+		WithLabels(labels).
+		WithAnnotations(rbg.GetCommonAnnotationsFromRole(role)).
+		WithAnnotations(annotations).
+		WithOwnerReferences(metaapplyv1.OwnerReference().
+			WithAPIVersion(rbg.APIVersion).
+			WithKind(rbg.Kind).
+			WithName(rbg.Name).
+			WithUID(rbg.GetUID()).
+			WithBlockOwnerDeletion(true).
+			WithController(true),
+		)
+	return meta
+}
+
+// kruisePodUpdatePolicy returns the pod update policy of rollingUpdate, updating the pods in place when possible by default.
+func kruisePodUpdatePolicy(rollingUpdate *workloadsv1alpha1.RollingUpdate) workloadsv1alpha1.PodUpdatePolicyType {
+	if rollingUpdate == nil || rollingUpdate.PodUpdatePolicy == "" {
+		return workloadsv1alpha1.InPlaceIfPossiblePodUpdatePolicyType
+	}
+	return rollingUpdate.PodUpdatePolicy
+}
+
+// kruiseInPlaceUpdateStrategy returns the in-place update strategy of rollingUpdate, or nil if it is not set.
+func kruiseInPlaceUpdateStrategy(rollingUpdate *workloadsv1alpha1.RollingUpdate) *kruiseappspub.InPlaceUpdateStrategy {
+	if rollingUpdate == nil || rollingUpdate.InPlaceUpdateGracePeriodSeconds == 0 {
+		return nil
+	}
+	return &kruiseappspub.InPlaceUpdateStrategy{GracePeriodSeconds: rollingUpdate.InPlaceUpdateGracePeriodSeconds}
+}
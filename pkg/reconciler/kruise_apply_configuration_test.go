@@ -0,0 +1,99 @@
+package reconciler
+
+import (
+	"reflect"
+	"testing"
+
+	kruiseappspub "github.com/openkruise/kruise-api/apps/pub"
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func Test_kruiseUpdateStrategy(t *testing.T) {
+	tests := []struct {
+		name          string
+		rollingUpdate *workloadsv1alpha1.RollingUpdate
+		wantPolicy    workloadsv1alpha1.PodUpdatePolicyType
+		wantInPlace   *kruiseappspub.InPlaceUpdateStrategy
+	}{
+		{
+			name:       "default",
+			wantPolicy: workloadsv1alpha1.InPlaceIfPossiblePodUpdatePolicyType,
+		},
+		{
+			name:          "recreate",
+			rollingUpdate: &workloadsv1alpha1.RollingUpdate{PodUpdatePolicy: workloadsv1alpha1.RecreatePodUpdatePolicyType},
+			wantPolicy:    workloadsv1alpha1.RecreatePodUpdatePolicyType,
+		},
+		{
+			name: "in-place with grace period",
+			rollingUpdate: &workloadsv1alpha1.RollingUpdate{
+				PodUpdatePolicy:                 workloadsv1alpha1.InPlaceOnlyPodUpdatePolicyType,
+				InPlaceUpdateGracePeriodSeconds: 10,
+			},
+			wantPolicy:  workloadsv1alpha1.InPlaceOnlyPodUpdatePolicyType,
+			wantInPlace: &kruiseappspub.InPlaceUpdateStrategy{GracePeriodSeconds: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kruisePodUpdatePolicy(tt.rollingUpdate); got != tt.wantPolicy {
+				t.Errorf("kruisePodUpdatePolicy() = %v, want %v", got, tt.wantPolicy)
+			}
+			if got := kruiseInPlaceUpdateStrategy(tt.rollingUpdate); !reflect.DeepEqual(got, tt.wantInPlace) {
+				t.Errorf("kruiseInPlaceUpdateStrategy() = %v, want %v", got, tt.wantInPlace)
+			}
+		})
+	}
+}
+
+func Test_cloneSetSpecEqual(t *testing.T) {
+	spec := kruiseappsv1alpha1.CloneSetSpec{
+		Replicas: ptr.To(int32(2)),
+		UpdateStrategy: kruiseappsv1alpha1.CloneSetUpdateStrategy{
+			Type:      kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+			Partition: ptr.To(intstr.FromInt32(0)),
+		},
+	}
+	heldSpec := spec.DeepCopy()
+	heldSpec.UpdateStrategy.Partition = ptr.To(intstr.FromInt32(1))
+	scaledSpec := spec.DeepCopy()
+	scaledSpec.Replicas = ptr.To(int32(3))
+
+	tests := []struct {
+		name  string
+		spec1 kruiseappsv1alpha1.CloneSetSpec
+		spec2 kruiseappsv1alpha1.CloneSetSpec
+		want  bool
+	}{
+		{
+			name:  "equal",
+			spec1: spec,
+			spec2: *spec.DeepCopy(),
+			want:  true,
+		},
+		{
+			name:  "partition changed",
+			spec1: spec,
+			spec2: *heldSpec,
+			want:  false,
+		},
+		{
+			name:  "replicas changed",
+			spec1: spec,
+			spec2: *scaledSpec,
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := cloneSetSpecEqual(tt.spec1, tt.spec2); got != tt.want {
+				t.Errorf("cloneSetSpecEqual() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	metaapplyv1 "k8s.io/client-go/applyconfigurations/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+var kruiseStatefulSetGVK = kruiseappsv1beta1.SchemeGroupVersion.WithKind("StatefulSet")
+
+func init() {
+	RegisterWorkload(WorkloadPlugin{
+		GVK:       kruiseStatefulSetGVK,
+		CrdName:   utils.KruiseStatefulSetCrdName,
+		NewObject: func() client.Object { return &kruiseappsv1beta1.StatefulSet{} },
+		NewReconciler: func(scheme *runtime.Scheme, client client.Client) WorkloadReconciler {
+			return NewKruiseStatefulSetReconciler(scheme, client)
+		},
+		Equal: kruiseStatefulSetEqual,
+	})
+}
+
+// KruiseStatefulSetReconciler reconciles the roles running as OpenKruise Advanced StatefulSets,
+// whose pods are updated in place when possible.
+type KruiseStatefulSetReconciler struct {
+	scheme *runtime.Scheme
+	client client.Client
+}
+
+var _ WorkloadReconciler = &KruiseStatefulSetReconciler{}
+
+func NewKruiseStatefulSetReconciler(scheme *runtime.Scheme, client client.Client) *KruiseStatefulSetReconciler {
+	return &KruiseStatefulSetReconciler{scheme: scheme, client: client}
+}
+
+func (r *KruiseStatefulSetReconciler) Reconciler(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
+	logger := log.FromContext(ctx)
+	logger.V(1).Info("start to reconciling advanced statefulset workload")
+
+	oldSts := &kruiseappsv1beta1.StatefulSet{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, oldSts)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	stsApplyConfig, err := r.constructStatefulSetApplyConfiguration(ctx, rbg, role, oldSts)
+	if err != nil {
+		logger.Error(err, "Failed to construct advanced statefulset apply configuration")
+		return err
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(stsApplyConfig)
+	if err != nil {
+		logger.Error(err, "Converting obj apply configuration to json.")
+		return err
+	}
+	newSts := &kruiseappsv1beta1.StatefulSet{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj, newSts); err != nil {
+		return fmt.Errorf("convert stsApplyConfig to advanced sts error: %s", err.Error())
+	}
+
+	equal, err := semanticallyEqualKruiseStatefulSet(oldSts, newSts)
+	if equal && !revisionChanged(ctx, oldSts.Annotations) {
+		logger.Info("advanced sts equal, skip reconcile")
+	} else {
+		if err != nil {
+			logger.Info(fmt.Sprintf("advanced sts not equal, diff: %s", err.Error()))
+		}
+		if err := utils.PatchObjectApplyConfiguration(ctx, r.client, stsApplyConfig, utils.PatchSpec); err != nil {
+			logger.Error(err, "Failed to patch advanced statefulset apply configuration")
+			return err
+		}
+	}
+
+	sts := &kruiseappsv1beta1.StatefulSet{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, sts); err != nil {
+		return fmt.Errorf("get advanced sts error, skip reconcile svc. error:  %s", err.Error())
+	}
+	sts.SetGroupVersionKind(kruiseStatefulSetGVK)
+	return applyHeadlessService(ctx, r.client, rbg, role, sts)
+}
+
+func (r *KruiseStatefulSetReconciler) constructStatefulSetApplyConfiguration(
+	ctx context.Context,
+	rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec,
+	oldSts *kruiseappsv1beta1.StatefulSet,
+) (*kruiseStatefulSetApplyConfiguration, error) {
+	rollingStrategy, err := ValidateRolloutStrategy(role.RolloutStrategy, int(*role.Replicas))
+	if err != nil {
+		return nil, err
+	}
+
+	matchLabels := rbg.GetCommonLabelsFromRole(role)
+	if oldSts.UID != "" {
+		// do not update selector when workload exists
+		matchLabels = oldSts.Spec.Selector.MatchLabels
+	}
+
+	podReconciler := NewPodReconciler(r.scheme, r.client)
+	podTemplateApplyConfiguration, err := podReconciler.ConstructPodTemplateSpecApplyConfiguration(ctx, rbg, role, maps.Clone(matchLabels))
+	if err != nil {
+		return nil, err
+	}
+
+	// Kruise moves the partition itself, rbg only holds back the replicas pinned by the partition,
+	// the pause or the coordinated rollout of rbg.
+	return &kruiseStatefulSetApplyConfiguration{
+		TypeMetaApplyConfiguration: metaapplyv1.TypeMetaApplyConfiguration{
+			APIVersion: ptr.To(kruiseStatefulSetGVK.GroupVersion().String()),
+			Kind:       ptr.To(kruiseStatefulSetGVK.Kind),
+		},
+		ObjectMetaApplyConfiguration: kruiseObjectMeta(rbg, role, matchLabels, revisionAnnotations(ctx)),
+		Spec: &kruiseStatefulSetSpecApplyConfiguration{
+			Replicas:            role.Replicas,
+			Selector:            metaapplyv1.LabelSelector().WithMatchLabels(matchLabels),
+			Template:            podTemplateApplyConfiguration,
+			ServiceName:         ptr.To(rbg.GetWorkloadName(role)),
+			PodManagementPolicy: ptr.To(appsv1.ParallelPodManagement),
+			UpdateStrategy: &kruiseStatefulSetUpdateStrategyConfiguration{
+				Type: ptr.To(appsv1.StatefulSetUpdateStrategyType(rollingStrategy.Type)),
+				RollingUpdate: &kruiseRollingUpdateStatefulSetApplyConfiguration{
+					Partition:             ptr.To(heldReplicas(ctx, role)),
+					MaxUnavailable:        ptr.To(rollingStrategy.RollingUpdate.MaxUnavailable),
+					PodUpdatePolicy:       ptr.To(kruiseappsv1beta1.PodUpdateStrategyType(kruisePodUpdatePolicy(rollingStrategy.RollingUpdate))),
+					InPlaceUpdateStrategy: kruiseInPlaceUpdateStrategy(rollingStrategy.RollingUpdate),
+				},
+			},
+		},
+	}, nil
+}
+
+func (r *KruiseStatefulSetReconciler) ConstructRoleStatus(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (workloadsv1alpha1.RoleStatus, bool, error) {
+	updateStatus := false
+	sts := &kruiseappsv1beta1.StatefulSet{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, sts); err != nil {
+		return workloadsv1alpha1.RoleStatus{}, updateStatus, err
+	}
+
+	status, found := rbg.GetRoleStatus(role.Name)
+	newStatus := workloadsv1alpha1.RoleStatus{
+		Name:                 role.Name,
+		Replicas:             ptr.Deref(sts.Spec.Replicas, 1),
+		ReadyReplicas:        sts.Status.ReadyReplicas,
+		UpdatedReplicas:      sts.Status.UpdatedReplicas,
+		UpdatedReadyReplicas: sts.Status.UpdatedReadyReplicas,
+		CurrentRevision:      status.CurrentRevision,
+		UpdateRevision:       status.UpdateRevision,
+		ObservedGeneration:   status.ObservedGeneration,
+		Conditions:           status.Conditions,
+	}
+	if !found || !apiequality.Semantic.DeepEqual(status, newStatus) {
+		status = newStatus
+		updateStatus = true
+	}
+	return status, updateStatus, nil
+}
+
+func (r *KruiseStatefulSetReconciler) RolloutState(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (*RolloutState, error) {
+	sts := &kruiseappsv1beta1.StatefulSet{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &RolloutState{
+		Revision:        sts.Annotations[workloadsv1alpha1.RevisionAnnotationKey],
+		UpdatedReplicas: sts.Status.UpdatedReplicas,
+		ReadyReplicas:   sts.Status.ReadyReplicas,
+		Observed:        sts.Status.ObservedGeneration >= sts.Generation,
+	}, nil
+}
+
+func (r *KruiseStatefulSetReconciler) CheckWorkloadReady(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (bool, error) {
+	sts := &kruiseappsv1beta1.StatefulSet{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, sts); err != nil {
+		return false, err
+	}
+	return sts.Status.ReadyReplicas == ptr.Deref(sts.Spec.Replicas, 1), nil
+}
+
+func (r *KruiseStatefulSetReconciler) CleanupOrphanedWorkloads(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	logger := log.FromContext(ctx)
+	if err := utils.CheckCrdExists(r.client, utils.KruiseStatefulSetCrdName); err != nil {
+		logger.Info(fmt.Sprintf("KruiseStatefulSetReconciler CleanupOrphanedWorkloads check crd failed: %s", err.Error()))
+		return nil
+	}
+	// list advanced sts managed by rbg
+	stsList := &kruiseappsv1beta1.StatefulSetList{}
+	if err := r.client.List(ctx, stsList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels(map[string]string{
+			workloadsv1alpha1.SetNameLabelKey: rbg.Name,
+		}),
+	); err != nil {
+		return err
+	}
+
+	for _, sts := range stsList.Items {
+		if !metav1.IsControlledBy(&sts, rbg) {
+			continue
+		}
+		found := false
+		for _, role := range rbg.Spec.Roles {
+			if role.Workload.String() == workloadsv1alpha1.KruiseStatefulSetWorkloadType && rbg.GetWorkloadName(&role) == sts.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			if err := r.client.Delete(ctx, &sts); err != nil {
+				return fmt.Errorf("delete advanced sts %s error: %s", sts.Name, err.Error())
+			}
+			// The deletion of headless services depends on its own reference
+			logger.Info("delete advanced sts", "sts", sts.Name)
+		}
+	}
+	return nil
+}
+
+// RecreateWorkload deletes the advanced sts of role with its pods in the foreground, it is created again
+// by the next reconciliation of rbg.
+func (r *KruiseStatefulSetReconciler) RecreateWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
+	logger := log.FromContext(ctx)
+	if rbg == nil || role == nil {
+		return nil
+	}
+
+	stsName := rbg.GetWorkloadName(role)
+	var sts kruiseappsv1beta1.StatefulSet
+	err := r.client.Get(ctx, types.NamespacedName{Name: stsName, Namespace: rbg.Namespace}, &sts)
+	// if sts is not found, skip delete sts
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("Recreate advanced sts workload, delete sts %s", stsName))
+	if err := r.client.Delete(ctx, &sts, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// kruiseStatefulSetEqual determines whether the update of an Advanced StatefulSet needs no reconciliation.
+func kruiseStatefulSetEqual(oldObj, newObj client.Object) (bool, error) {
+	o1, o2 := oldObj.(*kruiseappsv1beta1.StatefulSet), newObj.(*kruiseappsv1beta1.StatefulSet)
+	// check spec
+	if equal, err := semanticallyEqualKruiseStatefulSet(o1, o2); !equal {
+		return false, fmt.Errorf("advanced sts not equal, error: %s", err.Error())
+	}
+	// check status
+	if o1.Status.ReadyReplicas != o2.Status.ReadyReplicas {
+		return false, fmt.Errorf("ReadyReplicas not equal, old: %d, new: %d", o1.Status.ReadyReplicas, o2.Status.ReadyReplicas)
+	}
+	return true, nil
+}
+
+func semanticallyEqualKruiseStatefulSet(oldSts, newSts *kruiseappsv1beta1.StatefulSet) (bool, error) {
+	if oldSts == nil || oldSts.UID == "" {
+		return false, errors.New("old advanced sts not exist")
+	}
+	if newSts == nil {
+		return false, fmt.Errorf("new advanced sts is nil")
+	}
+
+	if equal, err := objectMetaEqual(oldSts.ObjectMeta, newSts.ObjectMeta); !equal {
+		return false, fmt.Errorf("objectMeta not equal: %s", err.Error())
+	}
+
+	if equal, err := kruiseStatefulSetSpecEqual(oldSts.Spec, newSts.Spec); !equal {
+		return false, fmt.Errorf("spec not equal: %s", err.Error())
+	}
+	return true, nil
+}
+
+func kruiseStatefulSetSpecEqual(spec1, spec2 kruiseappsv1beta1.StatefulSetSpec) (bool, error) {
+	if ptr.Deref(spec1.Replicas, 1) != ptr.Deref(spec2.Replicas, 1) {
+		return false, fmt.Errorf("replicas not equal, old: %d, new: %d", ptr.Deref(spec1.Replicas, 1), ptr.Deref(spec2.Replicas, 1))
+	}
+
+	if !reflect.DeepEqual(spec1.Selector, spec2.Selector) {
+		return false, fmt.Errorf("selector not equal, old: %v, new: %v", spec1.Selector, spec2.Selector)
+	}
+
+	if spec1.ServiceName != spec2.ServiceName {
+		return false, fmt.Errorf("serviceName not equal, old: %s, new: %s", spec1.ServiceName, spec2.ServiceName)
+	}
+
+	if !kruiseRollingUpdateEqual(spec1.UpdateStrategy.RollingUpdate, spec2.UpdateStrategy.RollingUpdate) {
+		return false, fmt.Errorf("rollingUpdate not equal, old: %v, new: %v",
+			spec1.UpdateStrategy.RollingUpdate, spec2.UpdateStrategy.RollingUpdate)
+	}
+
+	if equal, err := podTemplateSpecEqual(spec1.Template, spec2.Template); !equal {
+		return false, fmt.Errorf("podTemplateSpec not equal, %s", err.Error())
+	}
+
+	return true, nil
+}
+
+// kruiseRollingUpdateEqual compares the rolling update fields managed by rbg.
+func kruiseRollingUpdateEqual(r1, r2 *kruiseappsv1beta1.RollingUpdateStatefulSetStrategy) bool {
+	if r1 == nil || r2 == nil {
+		return r1 == r2
+	}
+	return ptr.Deref(r1.Partition, 0) == ptr.Deref(r2.Partition, 0) &&
+		reflect.DeepEqual(r1.MaxUnavailable, r2.MaxUnavailable) &&
+		r1.PodUpdatePolicy == r2.PodUpdatePolicy &&
+		reflect.DeepEqual(r1.InPlaceUpdateStrategy, r2.InPlaceUpdateStrategy)
+}
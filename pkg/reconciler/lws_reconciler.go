@@ -2,21 +2,21 @@ package reconciler
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
-	"time"
+	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/strategicpatch"
-	"k8s.io/apimachinery/pkg/util/wait"
 	metaapplyv1 "k8s.io/client-go/applyconfigurations/meta/v1"
 	utilpointer "k8s.io/utils/pointer"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	lwsv1 "sigs.k8s.io/lws/api/leaderworkerset/v1"
@@ -25,6 +25,18 @@ import (
 	"sigs.k8s.io/rbgs/pkg/utils"
 )
 
+func init() {
+	RegisterWorkload(WorkloadPlugin{
+		GVK:       lwsv1.GroupVersion.WithKind("LeaderWorkerSet"),
+		CrdName:   utils.LwsCrdName,
+		NewObject: func() client.Object { return &lwsv1.LeaderWorkerSet{} },
+		NewReconciler: func(scheme *runtime.Scheme, client client.Client) WorkloadReconciler {
+			return NewLeaderWorkerSetReconciler(scheme, client)
+		},
+		Equal: leaderWorkerSetEqual,
+	})
+}
+
 type LeaderWorkerSetReconciler struct {
 	scheme *runtime.Scheme
 	client client.Client
@@ -62,7 +74,7 @@ func (r *LeaderWorkerSetReconciler) Reconciler(ctx context.Context, rbg *workloa
 	}
 
 	equal, err := semanticallyEqualLeaderWorkerSet(oldLWS, newLWS)
-	if equal {
+	if equal && !revisionChanged(ctx, oldLWS.Annotations) {
 		logger.Info("lws equal, skip reconcile")
 		return nil
 	}
@@ -84,21 +96,91 @@ func (r *LeaderWorkerSetReconciler) ConstructRoleStatus(ctx context.Context, rbg
 		return workloadsv1alpha1.RoleStatus{}, false, err
 	}
 
-	currentReplicas := lws.Status.Replicas
-	currentReady := lws.Status.ReadyReplicas
+	updatedReady, err := r.updatedReadyReplicas(ctx, lws)
+	if err != nil {
+		return workloadsv1alpha1.RoleStatus{}, false, err
+	}
+
 	status, found := rbg.GetRoleStatus(role.Name)
-	if !found || status.Replicas != currentReplicas || status.ReadyReplicas != currentReady {
-		status = workloadsv1alpha1.RoleStatus{
-			Name:          role.Name,
-			Replicas:      currentReplicas,
-			ReadyReplicas: currentReady,
-		}
+	newStatus := workloadsv1alpha1.RoleStatus{
+		Name:                 role.Name,
+		Replicas:             lws.Status.Replicas,
+		ReadyReplicas:        lws.Status.ReadyReplicas,
+		UpdatedReplicas:      lws.Status.UpdatedReplicas,
+		UpdatedReadyReplicas: updatedReady,
+		CurrentRevision:      status.CurrentRevision,
+		UpdateRevision:       status.UpdateRevision,
+		ObservedGeneration:   status.ObservedGeneration,
+		Conditions:           status.Conditions,
+	}
+	if !found || !apiequality.Semantic.DeepEqual(status, newStatus) {
+		status = newStatus
 		updateStatus = true
 	}
 
 	return status, updateStatus, nil
 }
 
+// updatedReadyReplicas counts the updated groups of lws whose leader pod is ready.
+// Groups are updated from the highest index down, so the group with the highest index
+// carries the update revision.
+func (r *LeaderWorkerSetReconciler) updatedReadyReplicas(ctx context.Context, lws *lwsv1.LeaderWorkerSet) (int32, error) {
+	if lws.Status.UpdatedReplicas == 0 {
+		return 0, nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.client.List(ctx, podList, client.InNamespace(lws.Namespace), client.MatchingLabels{
+		lwsv1.SetNameLabelKey:     lws.Name,
+		lwsv1.WorkerIndexLabelKey: "0",
+	}); err != nil {
+		return 0, err
+	}
+
+	replicas := int(ptr.Deref(lws.Spec.Replicas, 1))
+	leaders := make([]*corev1.Pod, replicas)
+	for i := range podList.Items {
+		groupIndex, err := strconv.Atoi(podList.Items[i].Labels[lwsv1.GroupIndexLabelKey])
+		if err != nil || groupIndex < 0 || groupIndex >= replicas {
+			continue
+		}
+		leaders[groupIndex] = &podList.Items[i]
+	}
+
+	var updateRevision string
+	for idx := replicas - 1; idx >= 0; idx-- {
+		if leaders[idx] != nil {
+			updateRevision = leaders[idx].Labels[lwsv1.RevisionKey]
+			break
+		}
+	}
+	var updatedReady int32
+	for _, leader := range leaders {
+		if leader != nil && leader.Labels[lwsv1.RevisionKey] == updateRevision && utils.PodRunningAndReady(*leader) {
+			updatedReady++
+		}
+	}
+	return min(updatedReady, lws.Status.UpdatedReplicas), nil
+}
+
+func (r *LeaderWorkerSetReconciler) RolloutState(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (*RolloutState, error) {
+	lws := &lwsv1.LeaderWorkerSet{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, lws); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// lws does not report the observed generation, rely on its UpdateInProgress condition instead
+	return &RolloutState{
+		Revision:        lws.Annotations[workloadsv1alpha1.RevisionAnnotationKey],
+		UpdatedReplicas: lws.Status.UpdatedReplicas,
+		ReadyReplicas:   lws.Status.ReadyReplicas,
+		Observed:        !apimeta.IsStatusConditionTrue(lws.Status.Conditions, string(lwsv1.LeaderWorkerSetUpdateInProgress)),
+	}, nil
+}
+
 func (r *LeaderWorkerSetReconciler) CheckWorkloadReady(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (bool, error) {
 	lws := &lwsv1.LeaderWorkerSet{}
 	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, lws); err != nil {
@@ -152,7 +234,7 @@ func (r *LeaderWorkerSetReconciler) constructLWSApplyConfiguration(ctx context.C
 	logger := log.FromContext(ctx)
 	// leaderTemplate
 	podReconciler := NewPodReconciler(r.scheme, r.client)
-	leaderTemp, err := patchPodTemplate(role.Template, role.LeaderWorkerSet.PatchLeaderTemplate)
+	leaderTemp, err := utils.PatchPodTemplate(role.Template, role.LeaderWorkerSet.PatchLeaderTemplate)
 	if err != nil {
 		logger.Error(err, "patch leader podTemplate failed", "rbg", keyOfRbg(rbg))
 		return nil, err
@@ -164,7 +246,7 @@ func (r *LeaderWorkerSetReconciler) constructLWSApplyConfiguration(ctx context.C
 	}
 
 	// workerTemplate
-	workerTemp, err := patchPodTemplate(role.Template, role.LeaderWorkerSet.PatchWorkerTemplate)
+	workerTemp, err := utils.PatchPodTemplate(role.Template, role.LeaderWorkerSet.PatchWorkerTemplate)
 	if err != nil {
 		logger.Error(err, "patch worker podTemplate failed", "rbg", keyOfRbg(rbg))
 		return nil, err
@@ -187,10 +269,10 @@ func (r *LeaderWorkerSetReconciler) constructLWSApplyConfiguration(ctx context.C
 	if role.RestartPolicy == "None" {
 		restartPolicy = lwsv1.NoneRestartPolicy
 	} else {
-		// if role has RecreateRBGOnPodRestart or RecreateRoleInstanceOnPodRestart policy, set RecreateGroupOnPodRestart for lws
-		// it's safe to do so since
+		// if role has RecreateRBGOnPodRestart, RecreateDependentsOnPodRestart or RecreateRoleInstanceOnPodRestart policy,
+		// set RecreateGroupOnPodRestart for lws, it's safe to do so since
 		// 1. RecreateGroupOnPodRestart is the default restart policy for lws
-		// 2. RecreateRBGOnPodRestart will delete lws if pod recreated or containers restarted
+		// 2. RecreateRBGOnPodRestart and RecreateDependentsOnPodRestart will delete lws if pod recreated or containers restarted
 		restartPolicy = lwsv1.RecreateGroupOnPodRestart
 	}
 
@@ -204,18 +286,25 @@ func (r *LeaderWorkerSetReconciler) constructLWSApplyConfiguration(ctx context.C
 		)
 
 	// RollingUpdate
+	rollingUpdateConfig := lwsapplyv1.RollingUpdateConfiguration()
 	if role.RolloutStrategy != nil && role.RolloutStrategy.RollingUpdate != nil {
-		lwsSpecConfig = lwsSpecConfig.WithRolloutStrategy(lwsapplyv1.RolloutStrategy().WithRollingUpdateConfiguration(
-			lwsapplyv1.RollingUpdateConfiguration().
-				WithMaxSurge(role.RolloutStrategy.RollingUpdate.MaxSurge).
-				WithMaxUnavailable(role.RolloutStrategy.RollingUpdate.MaxUnavailable),
-		))
+		rollingUpdateConfig = rollingUpdateConfig.
+			WithMaxSurge(role.RolloutStrategy.RollingUpdate.MaxSurge).
+			WithMaxUnavailable(role.RolloutStrategy.RollingUpdate.MaxUnavailable)
+	}
+	// Hold back the groups pinned by the partition, the pause or the coordinated rollout of rbg.
+	if held := heldReplicas(ctx, role); held > 0 {
+		rollingUpdateConfig = rollingUpdateConfig.WithPartition(held)
+	}
+	if rollingUpdateConfig.MaxSurge != nil || rollingUpdateConfig.Partition != nil {
+		lwsSpecConfig = lwsSpecConfig.WithRolloutStrategy(lwsapplyv1.RolloutStrategy().WithRollingUpdateConfiguration(rollingUpdateConfig))
 	}
 
 	// construct lws apply configuration
 	lwsConfig := lwsapplyv1.LeaderWorkerSet(rbg.GetWorkloadName(role), rbg.Namespace).
 		WithSpec(lwsSpecConfig).
 		WithAnnotations(rbg.GetCommonAnnotationsFromRole(role)).
+		WithAnnotations(revisionAnnotations(ctx)).
 		WithLabels(rbg.GetCommonLabelsFromRole(role)).
 		WithOwnerReferences(metaapplyv1.OwnerReference().
 			WithAPIVersion(rbg.APIVersion).
@@ -229,6 +318,8 @@ func (r *LeaderWorkerSetReconciler) constructLWSApplyConfiguration(ctx context.C
 
 }
 
+// RecreateWorkload deletes the lws of role with its pods in the foreground, it is created again
+// by the next reconciliation of rbg.
 func (r *LeaderWorkerSetReconciler) RecreateWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
 	logger := log.FromContext(ctx)
 	if rbg == nil || role == nil {
@@ -247,30 +338,25 @@ func (r *LeaderWorkerSetReconciler) RecreateWorkload(ctx context.Context, rbg *w
 	}
 
 	logger.Info(fmt.Sprintf("Recreate lws workload, delete lws %s", lws.Name))
-	if err := r.client.Delete(ctx, &lws); err != nil && !apierrors.IsNotFound(err) {
+	if err := r.client.Delete(ctx, &lws, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 
-	// wait new lws create
-	var retErr error
-	err = wait.PollUntilContextTimeout(ctx, 5*time.Second, 5*time.Minute, true, func(ctx context.Context) (bool, error) {
-		var newLws lwsv1.LeaderWorkerSet
-		retErr = r.client.Get(ctx, types.NamespacedName{Name: lwsName, Namespace: rbg.Namespace}, &newLws)
-		if retErr != nil {
-			if apierrors.IsNotFound(retErr) {
-				return false, nil
-			}
-			return false, retErr
-		}
-		return true, nil
-	})
+	return nil
+}
 
-	if err != nil {
-		logger.Error(retErr, "wait new lws creating error")
-		return retErr
+// leaderWorkerSetEqual determines whether the update of a LeaderWorkerSet needs no reconciliation.
+func leaderWorkerSetEqual(oldObj, newObj client.Object) (bool, error) {
+	o1, o2 := oldObj.(*lwsv1.LeaderWorkerSet), newObj.(*lwsv1.LeaderWorkerSet)
+	// check spec
+	if equal, err := semanticallyEqualLeaderWorkerSet(o1, o2); !equal {
+		return false, fmt.Errorf("lws not equal, error: %s", err.Error())
 	}
-
-	return nil
+	// check status
+	if o1.Status.ReadyReplicas != o2.Status.ReadyReplicas {
+		return false, fmt.Errorf("ReadyReplicas not equal, old: %d, new: %d", o1.Status.ReadyReplicas, o2.Status.ReadyReplicas)
+	}
+	return true, nil
 }
 
 func semanticallyEqualLeaderWorkerSet(oldLws, newLws *lwsv1.LeaderWorkerSet) (bool, error) {
@@ -318,9 +404,19 @@ func lwsSpecEqual(lws1, lws2 lwsv1.LeaderWorkerSetSpec) (bool, error) {
 	if *lws1.Replicas != *lws2.Replicas {
 		return false, fmt.Errorf("LeaderWorkerSetSpec replicas not equal")
 	}
+	if lwsPartition(lws1) != lwsPartition(lws2) {
+		return false, fmt.Errorf("LeaderWorkerSetSpec partition not equal, old: %d, new: %d", lwsPartition(lws1), lwsPartition(lws2))
+	}
 	return true, nil
 }
 
+func lwsPartition(spec lwsv1.LeaderWorkerSetSpec) int32 {
+	if spec.RolloutStrategy.RollingUpdateConfiguration == nil || spec.RolloutStrategy.RollingUpdateConfiguration.Partition == nil {
+		return 0
+	}
+	return *spec.RolloutStrategy.RollingUpdateConfiguration.Partition
+}
+
 func leaderWorkerTemplateEqual(oldLwt, newLwt lwsv1.LeaderWorkerTemplate) (bool, error) {
 	if equal, err := podTemplateSpecEqual(*oldLwt.LeaderTemplate, *newLwt.LeaderTemplate); !equal {
 		retErr := fmt.Errorf("leaderTemplate not equal")
@@ -349,22 +445,6 @@ func leaderWorkerTemplateEqual(oldLwt, newLwt lwsv1.LeaderWorkerTemplate) (bool,
 	return true, nil
 }
 
-func patchPodTemplate(template corev1.PodTemplateSpec, patch runtime.RawExtension) (corev1.PodTemplateSpec, error) {
-	if patch.Raw == nil {
-		return template, nil
-	}
-	tempBytes, _ := json.Marshal(template)
-	modified, err := strategicpatch.StrategicMergePatch(tempBytes, patch.Raw, &corev1.PodTemplateSpec{})
-	if err != nil {
-		return template, err
-	}
-	newTemp := &corev1.PodTemplateSpec{}
-	if err = json.Unmarshal(modified, newTemp); err != nil {
-		return template, err
-	}
-	return *newTemp, nil
-}
-
 func keyOfRbg(rbg *workloadsv1alpha1.RoleBasedGroup) string {
 	return fmt.Sprintf("%s/%s", rbg.Namespace, rbg.Name)
 }
@@ -336,9 +336,9 @@ func TestPatchPodTemplate(t *testing.T) {
 
 	for _, cs := range cases {
 		t.Run(cs.name, func(t *testing.T) {
-			obj, err := patchPodTemplate(cs.getTemplate(), cs.getPatch())
+			obj, err := utils.PatchPodTemplate(cs.getTemplate(), cs.getPatch())
 			if err != nil {
-				t.Fatalf("patchPodTemplate failed: %s", err.Error())
+				t.Fatalf("PatchPodTemplate failed: %s", err.Error())
 			}
 			if utils.DumpJSON(cs.expect()) != utils.DumpJSON(obj) {
 				t.Fatalf("expect(%s), but get(%s)", utils.DumpJSON(cs.expect()), utils.DumpJSON(obj))
@@ -0,0 +1,57 @@
+package reconciler
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Placement constrains the nodes the pods of a role run on, like the nodes of the resource flavors
+// Kueue admitted the role to.
+type Placement struct {
+	// NodeSelector is merged into the node selector of the pods.
+	NodeSelector map[string]string
+	// Tolerations are added to the tolerations of the pods.
+	Tolerations []corev1.Toleration
+}
+
+type placementKey struct{}
+
+// WithPlacement returns a copy of ctx carrying placement for the workload reconcilers.
+func WithPlacement(ctx context.Context, placement *Placement) context.Context {
+	return context.WithValue(ctx, placementKey{}, placement)
+}
+
+// PlacementFrom returns the placement carried by ctx, or nil when the pods of the role can run anywhere.
+func PlacementFrom(ctx context.Context) *Placement {
+	placement, _ := ctx.Value(placementKey{}).(*Placement)
+	return placement
+}
+
+// applyPlacement constrains the pods of spec to the nodes of the placement carried by ctx.
+func applyPlacement(ctx context.Context, spec *corev1.PodSpec) {
+	placement := PlacementFrom(ctx)
+	if placement == nil {
+		return
+	}
+	if len(placement.NodeSelector) > 0 && spec.NodeSelector == nil {
+		spec.NodeSelector = make(map[string]string, len(placement.NodeSelector))
+	}
+	for key, value := range placement.NodeSelector {
+		spec.NodeSelector[key] = value
+	}
+	for _, toleration := range placement.Tolerations {
+		if !containsToleration(spec.Tolerations, toleration) {
+			spec.Tolerations = append(spec.Tolerations, toleration)
+		}
+	}
+}
+
+func containsToleration(tolerations []corev1.Toleration, toleration corev1.Toleration) bool {
+	for i := range tolerations {
+		if tolerations[i].MatchToleration(&toleration) {
+			return true
+		}
+	}
+	return false
+}
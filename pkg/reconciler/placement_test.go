@@ -0,0 +1,60 @@
+package reconciler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestApplyPlacement(t *testing.T) {
+	gpuToleration := corev1.Toleration{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+	spotToleration := corev1.Toleration{Key: "spot", Operator: corev1.TolerationOpEqual, Value: "true", Effect: corev1.TaintEffectNoSchedule}
+
+	tests := []struct {
+		name      string
+		placement *Placement
+		spec      corev1.PodSpec
+		want      corev1.PodSpec
+	}{
+		{
+			name: "no placement",
+			spec: corev1.PodSpec{NodeSelector: map[string]string{"zone": "a"}},
+			want: corev1.PodSpec{NodeSelector: map[string]string{"zone": "a"}},
+		},
+		{
+			name: "merged into the pod spec",
+			placement: &Placement{
+				NodeSelector: map[string]string{"instance-type": "a100"},
+				Tolerations:  []corev1.Toleration{gpuToleration, spotToleration},
+			},
+			spec: corev1.PodSpec{
+				NodeSelector: map[string]string{"zone": "a"},
+				Tolerations:  []corev1.Toleration{gpuToleration},
+			},
+			want: corev1.PodSpec{
+				NodeSelector: map[string]string{"zone": "a", "instance-type": "a100"},
+				Tolerations:  []corev1.Toleration{gpuToleration, spotToleration},
+			},
+		},
+		{
+			name:      "empty pod spec",
+			placement: &Placement{NodeSelector: map[string]string{"instance-type": "a100"}},
+			want:      corev1.PodSpec{NodeSelector: map[string]string{"instance-type": "a100"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			if tt.placement != nil {
+				ctx = WithPlacement(ctx, tt.placement)
+			}
+			applyPlacement(ctx, &tt.spec)
+			if !reflect.DeepEqual(tt.spec, tt.want) {
+				t.Errorf("applyPlacement() = %+v, want %+v", tt.spec, tt.want)
+			}
+		})
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"sort"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	coreapplyv1 "k8s.io/client-go/applyconfigurations/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 	"sigs.k8s.io/rbgs/pkg/discovery"
+	"sigs.k8s.io/rbgs/pkg/scheduler"
 	"sigs.k8s.io/rbgs/pkg/utils"
 )
 
@@ -69,6 +71,17 @@ func (r *PodReconciler) ConstructPodTemplateSpecApplyConfiguration(
 		}
 	}
 
+	// place the pods of the roles relative to each other
+	scheduler.InjectTopologyAffinity(rbg, &podTemplateSpec.Spec)
+
+	// schedule and preempt with the priority of the role, or else of the group
+	if priorityClassName := rbg.GetPriorityClassName(role); priorityClassName != "" {
+		podTemplateSpec.Spec.PriorityClassName = priorityClassName
+	}
+
+	// run on the nodes the role is admitted to
+	applyPlacement(ctx, &podTemplateSpec.Spec)
+
 	// construct pod template spec configuration
 	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&podTemplateSpec)
 	if err != nil {
@@ -80,13 +93,14 @@ func (r *PodReconciler) ConstructPodTemplateSpecApplyConfiguration(
 		return nil, err
 	}
 
+	podTemplateApplyConfiguration.WithLabels(podLabels)
+	if setName := rbg.GetGroupSetName(); setName != "" {
+		// selected by the topology policy spreading the groups of the set
+		podTemplateApplyConfiguration.WithLabels(map[string]string{workloadsv1alpha1.GroupSetNameLabelKey: setName})
+	}
 	if rbg.EnableGangScheduling() {
-		if podLabels == nil {
-			podLabels = map[string]string{}
-		}
-		podLabels[workloadsv1alpha1.PodGroupLabelKey] = rbg.Name
+		scheduler.NewPodGroupScheduler(r.client).InjectPodGroupInfo(rbg, podTemplateApplyConfiguration)
 	}
-	podTemplateApplyConfiguration.WithLabels(podLabels)
 
 	return podTemplateApplyConfiguration, nil
 }
@@ -142,6 +156,14 @@ func podSpecEqual(spec1, spec2 corev1.PodSpec) (bool, error) {
 		return false, fmt.Errorf("podTemplate volumes not equal: %s", err.Error())
 	}
 
+	if !apiequality.Semantic.DeepEqual(spec1.Affinity, spec2.Affinity) {
+		return false, fmt.Errorf("pod template spec affinity not equal")
+	}
+
+	if spec1.PriorityClassName != spec2.PriorityClassName {
+		return false, fmt.Errorf("pod template spec priorityClassName not equal")
+	}
+
 	return true, nil
 }
 
@@ -0,0 +1,406 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreapplyv1 "k8s.io/client-go/applyconfigurations/core/v1"
+	metaapplyv1 "k8s.io/client-go/applyconfigurations/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/history"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+func init() {
+	RegisterWorkload(WorkloadPlugin{
+		GVK:       corev1.SchemeGroupVersion.WithKind("Pod"),
+		NewObject: func() client.Object { return &corev1.Pod{} },
+		NewReconciler: func(scheme *runtime.Scheme, client client.Client) WorkloadReconciler {
+			return NewPodWorkloadReconciler(scheme, client)
+		},
+		Equal: podEqual,
+	})
+}
+
+// PodWorkloadReconciler runs the replicas of a role as bare pods owned by the rbg, named <rbg>-<role>-<index>,
+// for singleton components which do not need a workload controller.
+type PodWorkloadReconciler struct {
+	scheme *runtime.Scheme
+	client client.Client
+}
+
+var _ WorkloadReconciler = &PodWorkloadReconciler{}
+var _ RolloutStateReader = &PodWorkloadReconciler{}
+var _ WorkloadDeleter = &PodWorkloadReconciler{}
+
+func NewPodWorkloadReconciler(scheme *runtime.Scheme, client client.Client) *PodWorkloadReconciler {
+	return &PodWorkloadReconciler{scheme: scheme, client: client}
+}
+
+func (r *PodWorkloadReconciler) Reconciler(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
+	logger := log.FromContext(ctx)
+	logger.V(1).Info("start to reconciling pod workload")
+
+	template, templateHash, err := r.constructPodTemplate(ctx, rbg, role)
+	if err != nil {
+		logger.Error(err, "Failed to construct pod template apply configuration")
+		return err
+	}
+	pods, err := r.listPods(ctx, rbg, role)
+	if err != nil {
+		return err
+	}
+
+	replicas := *role.Replicas
+	rollingStrategy, err := ValidateRolloutStrategy(role.RolloutStrategy, int(replicas))
+	if err != nil {
+		return err
+	}
+	maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(&rollingStrategy.RollingUpdate.MaxUnavailable, int(replicas), false)
+	if err != nil {
+		return err
+	}
+
+	remove, apply := planPods(ctx, role, pods, templateHash, max(maxUnavailable, 1))
+	for _, pod := range remove {
+		logger.Info("delete pod", "pod", pod.Name, "phase", pod.Status.Phase)
+		if err := r.client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	for _, index := range apply {
+		if err := r.applyPod(ctx, rbg, role, index, template, templateHash); err != nil {
+			return err
+		}
+	}
+
+	// the headless service gives the pods the same stable addresses as the pods of a statefulset
+	return applyHeadlessService(ctx, r.client, rbg, role, rbg)
+}
+
+// planPods returns the pods of role to delete and the indexes of the pods to apply. The pods out of the
+// replicas and the finished pods are deleted, the missing pods are created. The pod spec is immutable,
+// so the pods running an outdated template are deleted from the highest index to be created again,
+// keeping the pods held at the old revision and at most maxUnavailable pods unavailable. A pod can not
+// surge with a stable name, maxUnavailable has to be at least 1.
+func planPods(ctx context.Context, role *workloadsv1alpha1.RoleSpec, pods map[int32]*corev1.Pod,
+	templateHash string, maxUnavailable int) (remove []*corev1.Pod, apply []int32) {
+	replicas := *role.Replicas
+	unavailable := int(replicas)
+	for index, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if index < replicas && pod.Status.Phase != corev1.PodFailed && pod.Status.Phase != corev1.PodSucceeded {
+			if utils.PodRunningAndReady(*pod) {
+				unavailable--
+			}
+			continue
+		}
+		remove = append(remove, pod)
+	}
+
+	held := heldReplicas(ctx, role)
+	for index := replicas - 1; index >= 0; index-- {
+		pod, found := pods[index]
+		if !found {
+			apply = append(apply, index)
+			continue
+		}
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+			continue
+		}
+		if pod.Annotations[workloadsv1alpha1.PodTemplateHashAnnotationKey] == templateHash {
+			if revisionChanged(ctx, pod.Annotations) {
+				apply = append(apply, index)
+			}
+			continue
+		}
+
+		if index < held {
+			continue
+		}
+		ready := utils.PodRunningAndReady(*pod)
+		if ready && unavailable >= maxUnavailable {
+			continue
+		}
+		remove = append(remove, pod)
+		if ready {
+			unavailable++
+		}
+	}
+	return remove, apply
+}
+
+// constructPodTemplate returns the pod template of role and its hash.
+func (r *PodWorkloadReconciler) constructPodTemplate(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec) (*coreapplyv1.PodTemplateSpecApplyConfiguration, string, error) {
+	podReconciler := NewPodReconciler(r.scheme, r.client)
+	template, err := podReconciler.ConstructPodTemplateSpecApplyConfiguration(ctx, rbg, role, rbg.GetCommonLabelsFromRole(role))
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := json.Marshal(template)
+	if err != nil {
+		return nil, "", err
+	}
+	return template, history.HashRevisionData(data), nil
+}
+
+func (r *PodWorkloadReconciler) applyPod(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec,
+	index int32, template *coreapplyv1.PodTemplateSpecApplyConfiguration, templateHash string) error {
+	name := fmt.Sprintf("%s-%d", rbg.GetWorkloadName(role), index)
+	podConfig := coreapplyv1.Pod(name, rbg.Namespace).
+		WithLabels(template.Labels).
+		WithLabels(map[string]string{workloadsv1alpha1.PodIndexLabelKey: strconv.Itoa(int(index))}).
+		WithAnnotations(template.Annotations).
+		WithAnnotations(rbg.GetCommonAnnotationsFromRole(role)).
+		WithAnnotations(map[string]string{workloadsv1alpha1.PodTemplateHashAnnotationKey: templateHash}).
+		WithAnnotations(revisionAnnotations(ctx)).
+		WithOwnerReferences(metaapplyv1.OwnerReference().
+			WithAPIVersion(rbg.APIVersion).
+			WithKind(rbg.Kind).
+			WithName(rbg.Name).
+			WithUID(rbg.GetUID()).
+			WithBlockOwnerDeletion(true).
+			WithController(true),
+		).
+		WithSpec(template.Spec)
+	podConfig.Spec.WithHostname(name)
+	if podConfig.Spec.Subdomain == nil {
+		podConfig.Spec.WithSubdomain(rbg.GetWorkloadName(role))
+	}
+	if err := utils.PatchObjectApplyConfiguration(ctx, r.client, podConfig, utils.PatchSpec); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to patch pod apply configuration", "pod", name)
+		return err
+	}
+	return nil
+}
+
+// listPods returns the pods of role owned by rbg by their index.
+func (r *PodWorkloadReconciler) listPods(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec) (map[int32]*corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := r.client.List(ctx, podList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels(rbg.GetCommonLabelsFromRole(role))); err != nil {
+		return nil, err
+	}
+
+	pods := make(map[int32]*corev1.Pod, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !metav1.IsControlledBy(pod, rbg) {
+			continue
+		}
+		index, err := strconv.ParseInt(pod.Labels[workloadsv1alpha1.PodIndexLabelKey], 10, 32)
+		if err != nil {
+			continue
+		}
+		pods[int32(index)] = pod
+	}
+	return pods, nil
+}
+
+// podCounts returns the ready, updated and updated ready pods of role.
+func (r *PodWorkloadReconciler) podCounts(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec) (ready, updated, updatedReady int32, revision string, err error) {
+	_, templateHash, err := r.constructPodTemplate(ctx, rbg, role)
+	if err != nil {
+		return 0, 0, 0, "", err
+	}
+	pods, err := r.listPods(ctx, rbg, role)
+	if err != nil {
+		return 0, 0, 0, "", err
+	}
+	ready, updated, updatedReady, revision = countPods(pods, *role.Replicas, templateHash)
+	return ready, updated, updatedReady, revision, nil
+}
+
+// countPods returns the ready, updated and updated ready pods among the replicas, and the revision
+// recorded on the updated pods, or an empty string until they all record the same revision.
+func countPods(pods map[int32]*corev1.Pod, replicas int32, templateHash string) (ready, updated, updatedReady int32, revision string) {
+	revisions := sets.New[string]()
+	for index, pod := range pods {
+		if index >= replicas || pod.DeletionTimestamp != nil {
+			continue
+		}
+		podReady := utils.PodRunningAndReady(*pod)
+		if podReady {
+			ready++
+		}
+		if pod.Annotations[workloadsv1alpha1.PodTemplateHashAnnotationKey] == templateHash {
+			updated++
+			revisions.Insert(pod.Annotations[workloadsv1alpha1.RevisionAnnotationKey])
+			if podReady {
+				updatedReady++
+			}
+		}
+	}
+	if revisions.Len() == 1 {
+		revision = revisions.UnsortedList()[0]
+	}
+	return ready, updated, updatedReady, revision
+}
+
+func (r *PodWorkloadReconciler) ConstructRoleStatus(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (workloadsv1alpha1.RoleStatus, bool, error) {
+	updateStatus := false
+	ready, updated, updatedReady, _, err := r.podCounts(ctx, rbg, role)
+	if err != nil {
+		return workloadsv1alpha1.RoleStatus{}, updateStatus, err
+	}
+
+	status, found := rbg.GetRoleStatus(role.Name)
+	newStatus := workloadsv1alpha1.RoleStatus{
+		Name:                 role.Name,
+		Replicas:             *role.Replicas,
+		ReadyReplicas:        ready,
+		UpdatedReplicas:      updated,
+		UpdatedReadyReplicas: updatedReady,
+		CurrentRevision:      status.CurrentRevision,
+		UpdateRevision:       status.UpdateRevision,
+		ObservedGeneration:   status.ObservedGeneration,
+		Conditions:           status.Conditions,
+	}
+	if !found || !apiequality.Semantic.DeepEqual(status, newStatus) {
+		status = newStatus
+		updateStatus = true
+	}
+	return status, updateStatus, nil
+}
+
+func (r *PodWorkloadReconciler) RolloutState(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (*RolloutState, error) {
+	ready, updated, _, revision, err := r.podCounts(ctx, rbg, role)
+	if err != nil {
+		return nil, err
+	}
+	return &RolloutState{
+		Revision:        revision,
+		UpdatedReplicas: updated,
+		ReadyReplicas:   ready,
+		// the pods are reconciled by the rbg controller itself
+		Observed: true,
+	}, nil
+}
+
+func (r *PodWorkloadReconciler) CheckWorkloadReady(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (bool, error) {
+	pods, err := r.listPods(ctx, rbg, role)
+	if err != nil {
+		return false, err
+	}
+	ready, _, _, _ := countPods(pods, *role.Replicas, "")
+	return ready == *role.Replicas, nil
+}
+
+func (r *PodWorkloadReconciler) CleanupOrphanedWorkloads(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	logger := log.FromContext(ctx)
+	// list pods managed by rbg, the pods of the other workloads are owned by their workloads
+	podList := &corev1.PodList{}
+	if err := r.client.List(ctx, podList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels(map[string]string{
+			workloadsv1alpha1.SetNameLabelKey: rbg.Name,
+		}),
+	); err != nil {
+		return err
+	}
+
+	for _, pod := range podList.Items {
+		if !metav1.IsControlledBy(&pod, rbg) {
+			continue
+		}
+		role, err := rbg.GetRole(pod.Labels[workloadsv1alpha1.SetRoleLabelKey])
+		if err == nil && role.Workload.String() == workloadsv1alpha1.PodWorkloadType {
+			continue
+		}
+		logger.Info("delete pod", "pod", pod.Name)
+		if err := r.client.Delete(ctx, &pod); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete pod %s error: %s", pod.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func (r *PodWorkloadReconciler) DeleteWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleName string) error {
+	logger := log.FromContext(ctx)
+	podList := &corev1.PodList{}
+	if err := r.client.List(ctx, podList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels(map[string]string{
+			workloadsv1alpha1.SetNameLabelKey: rbg.Name,
+			workloadsv1alpha1.SetRoleLabelKey: roleName,
+		}),
+	); err != nil {
+		return err
+	}
+
+	for _, pod := range podList.Items {
+		if !metav1.IsControlledBy(&pod, rbg) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		logger.Info("delete pod", "pod", pod.Name)
+		if err := r.client.Delete(ctx, &pod); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete pod %s error: %s", pod.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// RecreateWorkload deletes the pods of role, they are created again by the next reconciliation of rbg.
+func (r *PodWorkloadReconciler) RecreateWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
+	logger := log.FromContext(ctx)
+	if rbg == nil || role == nil {
+		return nil
+	}
+
+	pods, err := r.listPods(ctx, rbg, role)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		logger.Info(fmt.Sprintf("Recreate pod workload, delete pod %s", pod.Name))
+		if err := r.client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// WorkloadExists reports whether any pod of the role roleName exists, terminating or not.
+func (r *PodWorkloadReconciler) WorkloadExists(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleName string) (bool, error) {
+	pods, err := r.listPods(ctx, rbg, &workloadsv1alpha1.RoleSpec{Name: roleName})
+	if err != nil {
+		return false, err
+	}
+	return len(pods) > 0, nil
+}
+
+// podEqual determines whether the update of a pod of a role needs no reconciliation.
+func podEqual(oldObj, newObj client.Object) (bool, error) {
+	o1, o2 := oldObj.(*corev1.Pod), newObj.(*corev1.Pod)
+	if o1.Status.Phase != o2.Status.Phase {
+		return false, fmt.Errorf("phase not equal, old: %s, new: %s", o1.Status.Phase, o2.Status.Phase)
+	}
+	if utils.PodRunningAndReady(*o1) != utils.PodRunningAndReady(*o2) {
+		return false, fmt.Errorf("ready not equal, old: %t, new: %t", utils.PodRunningAndReady(*o1), utils.PodRunningAndReady(*o2))
+	}
+	if (o1.DeletionTimestamp == nil) != (o2.DeletionTimestamp == nil) {
+		return false, fmt.Errorf("pod %s is being deleted", o2.Name)
+	}
+	if !maps.Equal(o1.Annotations, o2.Annotations) {
+		return false, fmt.Errorf("annotation not equal, old [%s], new [%s]", o1.Annotations, o2.Annotations)
+	}
+	return true, nil
+}
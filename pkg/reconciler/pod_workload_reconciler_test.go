@@ -0,0 +1,137 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func buildRolePod(index int, templateHash string, phase corev1.PodPhase, ready bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("test-rbg-router-%d", index),
+			Annotations: map[string]string{workloadsv1alpha1.PodTemplateHashAnnotationKey: templateHash},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+	if ready {
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	}
+	return pod
+}
+
+func Test_planPods(t *testing.T) {
+	tests := []struct {
+		name           string
+		replicas       int32
+		partition      *int32
+		pods           map[int32]*corev1.Pod
+		maxUnavailable int
+		wantRemove     []string
+		wantApply      []int32
+	}{
+		{
+			name:       "create missing pods",
+			replicas:   3,
+			pods:       map[int32]*corev1.Pod{1: buildRolePod(1, "v2", corev1.PodRunning, true)},
+			wantApply:  []int32{2, 0},
+			wantRemove: nil,
+		},
+		{
+			name:     "delete pods out of replicas and finished pods",
+			replicas: 2,
+			pods: map[int32]*corev1.Pod{
+				0: buildRolePod(0, "v2", corev1.PodFailed, false),
+				1: buildRolePod(1, "v2", corev1.PodRunning, true),
+				2: buildRolePod(2, "v2", corev1.PodRunning, true),
+			},
+			wantRemove: []string{"test-rbg-router-0", "test-rbg-router-2"},
+		},
+		{
+			name:     "recreate outdated pods from the highest index",
+			replicas: 3,
+			pods: map[int32]*corev1.Pod{
+				0: buildRolePod(0, "v1", corev1.PodRunning, true),
+				1: buildRolePod(1, "v1", corev1.PodRunning, true),
+				2: buildRolePod(2, "v1", corev1.PodRunning, true),
+			},
+			maxUnavailable: 1,
+			wantRemove:     []string{"test-rbg-router-2"},
+		},
+		{
+			name:     "unavailable outdated pods do not count against maxUnavailable",
+			replicas: 3,
+			pods: map[int32]*corev1.Pod{
+				0: buildRolePod(0, "v1", corev1.PodRunning, true),
+				1: buildRolePod(1, "v1", corev1.PodPending, false),
+				2: buildRolePod(2, "v2", corev1.PodRunning, true),
+			},
+			maxUnavailable: 1,
+			wantRemove:     []string{"test-rbg-router-1"},
+		},
+		{
+			name:      "keep pods held by the partition",
+			replicas:  2,
+			partition: ptr.To[int32](1),
+			pods: map[int32]*corev1.Pod{
+				0: buildRolePod(0, "v1", corev1.PodRunning, true),
+				1: buildRolePod(1, "v2", corev1.PodRunning, true),
+			},
+			maxUnavailable: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := &workloadsv1alpha1.RoleSpec{Name: "router", Replicas: ptr.To(tt.replicas)}
+			if tt.partition != nil {
+				role.RolloutStrategy = &workloadsv1alpha1.RolloutStrategy{
+					RollingUpdate: &workloadsv1alpha1.RollingUpdate{Partition: tt.partition},
+				}
+			}
+			remove, apply := planPods(context.Background(), role, tt.pods, "v2", tt.maxUnavailable)
+			var removed []string
+			for _, pod := range remove {
+				removed = append(removed, pod.Name)
+			}
+			// the pods are not deleted in a stable order
+			if len(removed) == 2 && removed[0] > removed[1] {
+				removed[0], removed[1] = removed[1], removed[0]
+			}
+			if !reflect.DeepEqual(removed, tt.wantRemove) {
+				t.Errorf("planPods() remove = %v, want %v", removed, tt.wantRemove)
+			}
+			if !reflect.DeepEqual(apply, tt.wantApply) {
+				t.Errorf("planPods() apply = %v, want %v", apply, tt.wantApply)
+			}
+		})
+	}
+}
+
+func Test_countPods(t *testing.T) {
+	pods := map[int32]*corev1.Pod{
+		0: buildRolePod(0, "v2", corev1.PodRunning, true),
+		1: buildRolePod(1, "v2", corev1.PodPending, false),
+		2: buildRolePod(2, "v1", corev1.PodRunning, true),
+		3: buildRolePod(3, "v2", corev1.PodRunning, true),
+	}
+	pods[0].Annotations[workloadsv1alpha1.RevisionAnnotationKey] = "rev-2"
+	pods[1].Annotations[workloadsv1alpha1.RevisionAnnotationKey] = "rev-2"
+
+	ready, updated, updatedReady, revision := countPods(pods, 3, "v2")
+	if ready != 2 || updated != 2 || updatedReady != 1 || revision != "rev-2" {
+		t.Errorf("countPods() = %d, %d, %d, %q, want 2, 2, 1, rev-2", ready, updated, updatedReady, revision)
+	}
+
+	// the revision is not rolled out until every updated pod records it
+	pods[1].Annotations[workloadsv1alpha1.RevisionAnnotationKey] = "rev-1"
+	if _, _, _, revision = countPods(pods, 3, "v2"); revision != "" {
+		t.Errorf("countPods() revision = %q with pods at different revisions", revision)
+	}
+}
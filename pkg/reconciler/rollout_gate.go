@@ -0,0 +1,84 @@
+package reconciler
+
+import (
+	"context"
+
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// RolloutGate holds back the update of a role while the roles of rbg are rolled out together.
+type RolloutGate struct {
+	// Revision is the hash of the role revision the workload is rolled out to.
+	// It is recorded in the RevisionAnnotationKey annotation of the workload.
+	Revision string
+	// Partition is the number of replicas kept at the old revision.
+	Partition int32
+}
+
+type rolloutGateKey struct{}
+
+// WithRolloutGate returns a copy of ctx carrying gate for the workload reconcilers.
+func WithRolloutGate(ctx context.Context, gate *RolloutGate) context.Context {
+	return context.WithValue(ctx, rolloutGateKey{}, gate)
+}
+
+// RolloutGateFrom returns the gate carried by ctx, or nil when the role rolls out on its own.
+func RolloutGateFrom(ctx context.Context) *RolloutGate {
+	gate, _ := ctx.Value(rolloutGateKey{}).(*RolloutGate)
+	return gate
+}
+
+// RolloutState is the rollout progress of the workload of a role.
+type RolloutState struct {
+	// Revision is the role revision recorded on the workload.
+	Revision string
+	// UpdatedReplicas is the number of replicas running the latest workload template.
+	UpdatedReplicas int32
+	// ReadyReplicas is the number of ready replicas.
+	ReadyReplicas int32
+	// Observed is false until the workload controller has seen the latest workload spec.
+	Observed bool
+}
+
+// RolloutStateReader is implemented by the workload reconcilers supporting coordinated rollout.
+type RolloutStateReader interface {
+	// RolloutState returns the rollout state of the workload of role, or nil if the workload does not exist.
+	RolloutState(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (*RolloutState, error)
+}
+
+// revisionAnnotations returns the annotations recording the revision of gate on the workload.
+func revisionAnnotations(ctx context.Context) map[string]string {
+	gate := RolloutGateFrom(ctx)
+	if gate == nil || gate.Revision == "" {
+		return nil
+	}
+	return map[string]string{workloadsv1alpha1.RevisionAnnotationKey: gate.Revision}
+}
+
+// revisionChanged reports whether the revision annotation of the workload has to be updated.
+func revisionChanged(ctx context.Context, annotations map[string]string) bool {
+	gate := RolloutGateFrom(ctx)
+	if gate == nil || gate.Revision == "" {
+		return false
+	}
+	return annotations[workloadsv1alpha1.RevisionAnnotationKey] != gate.Revision
+}
+
+// heldReplicas returns the number of replicas of role, from ordinal 0, kept at the old revision
+// by the partition and paused settings of role and by the coordinated rollout of rbg.
+func heldReplicas(ctx context.Context, role *workloadsv1alpha1.RoleSpec) int32 {
+	replicas := *role.Replicas
+	var partition int32
+	if strategy := role.RolloutStrategy; strategy != nil {
+		if strategy.Paused {
+			return replicas
+		}
+		if strategy.RollingUpdate != nil && strategy.RollingUpdate.Partition != nil {
+			partition = *strategy.RollingUpdate.Partition
+		}
+	}
+	if gate := RolloutGateFrom(ctx); gate != nil {
+		partition = max(partition, gate.Partition)
+	}
+	return min(partition, replicas)
+}
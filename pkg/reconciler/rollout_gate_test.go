@@ -0,0 +1,63 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+func TestHeldReplicas(t *testing.T) {
+	tests := []struct {
+		name string
+		role workloadsv1alpha1.RoleSpec
+		gate *RolloutGate
+		want int32
+	}{
+		{
+			name: "no partition",
+			role: wrappers.BuildBasicRole("worker").WithReplicas(4).Obj(),
+			want: 0,
+		},
+		{
+			name: "partition",
+			role: wrappers.BuildBasicRole("worker").WithReplicas(4).WithPartition(3).Obj(),
+			want: 3,
+		},
+		{
+			name: "partition above replicas",
+			role: wrappers.BuildBasicRole("worker").WithReplicas(4).WithPartition(6).Obj(),
+			want: 4,
+		},
+		{
+			name: "paused",
+			role: wrappers.BuildBasicRole("worker").WithReplicas(4).WithPartition(1).WithPaused(true).Obj(),
+			want: 4,
+		},
+		{
+			name: "coordinated rollout holds more replicas",
+			role: wrappers.BuildBasicRole("worker").WithReplicas(4).WithPartition(1).Obj(),
+			gate: &RolloutGate{Partition: 2},
+			want: 2,
+		},
+		{
+			name: "partition holds more replicas",
+			role: wrappers.BuildBasicRole("worker").WithReplicas(4).WithPartition(3).Obj(),
+			gate: &RolloutGate{Partition: 2},
+			want: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			if tt.gate != nil {
+				ctx = WithRolloutGate(ctx, tt.gate)
+			}
+			if got := heldReplicas(ctx, &tt.role); got != tt.want {
+				t.Errorf("heldReplicas() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
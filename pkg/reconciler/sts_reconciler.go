@@ -6,12 +6,12 @@ import (
 	"fmt"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/apimachinery/pkg/util/wait"
 	appsapplyv1 "k8s.io/client-go/applyconfigurations/apps/v1"
 	coreapplyv1 "k8s.io/client-go/applyconfigurations/core/v1"
 	metaapplyv1 "k8s.io/client-go/applyconfigurations/meta/v1"
@@ -22,9 +22,19 @@ import (
 	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 	"sigs.k8s.io/rbgs/pkg/utils"
 	"strconv"
-	"time"
 )
 
+func init() {
+	RegisterWorkload(WorkloadPlugin{
+		GVK:       appsv1.SchemeGroupVersion.WithKind("StatefulSet"),
+		NewObject: func() client.Object { return &appsv1.StatefulSet{} },
+		NewReconciler: func(scheme *runtime.Scheme, client client.Client) WorkloadReconciler {
+			return NewStatefulSetReconciler(scheme, client)
+		},
+		Equal: statefulSetEqual,
+	})
+}
+
 type StatefulSetReconciler struct {
 	scheme *runtime.Scheme
 	client client.Client
@@ -51,7 +61,7 @@ func (r *StatefulSetReconciler) reconcileStatefulSet(ctx context.Context, rbg *w
 	logger := log.FromContext(ctx)
 	logger.V(1).Info("start to reconciling sts workload")
 
-	rollingStrategy, err := validateRolloutStrategy(role.RolloutStrategy, int(*role.Replicas))
+	rollingStrategy, err := ValidateRolloutStrategy(role.RolloutStrategy, int(*role.Replicas))
 	if err != nil {
 		logger.Error(err, "Invalid rollout strategy")
 		return err
@@ -91,7 +101,11 @@ func (r *StatefulSetReconciler) reconcileStatefulSet(ctx context.Context, rbg *w
 		return err
 	}
 
-	if equal && partition == *oldSts.Spec.UpdateStrategy.RollingUpdate.Partition && *oldSts.Spec.Replicas == *role.Replicas {
+	// Hold back the replicas pinned by the partition, the pause or the coordinated rollout of rbg.
+	partition = max(partition, heldReplicas(ctx, role))
+
+	if equal && partition == *oldSts.Spec.UpdateStrategy.RollingUpdate.Partition && *oldSts.Spec.Replicas == *role.Replicas &&
+		!revisionChanged(ctx, oldSts.Annotations) {
 		logger.Info("sts equal, skip reconcile")
 		return nil
 	}
@@ -330,7 +344,15 @@ func (r *StatefulSetReconciler) reconcileHeadlessService(ctx context.Context, rb
 		return fmt.Errorf("get sts error, skip reconcile svc. error:  %s", err.Error())
 	}
 
-	svcApplyConfig, err := r.constructServiceApplyConfiguration(ctx, rbg, role, sts)
+	return applyHeadlessService(ctx, r.client, rbg, role, sts)
+}
+
+// applyHeadlessService creates or updates the headless service giving stable network ids to the pods
+// of a StatefulSet-like workload, owner is the workload.
+func applyHeadlessService(ctx context.Context, k8sClient client.Client, rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec, owner client.Object) error {
+	logger := log.FromContext(ctx)
+	svcApplyConfig, err := constructServiceApplyConfiguration(rbg, role, owner)
 	if err != nil {
 		return err
 	}
@@ -346,7 +368,7 @@ func (r *StatefulSetReconciler) reconcileHeadlessService(ctx context.Context, rb
 	}
 
 	oldSvc := &corev1.Service{}
-	err = r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, oldSvc)
+	err = k8sClient.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, oldSvc)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
@@ -359,7 +381,7 @@ func (r *StatefulSetReconciler) reconcileHeadlessService(ctx context.Context, rb
 
 	logger.V(1).Info(fmt.Sprintf("svc not equal, diff: %s", err.Error()))
 
-	if err := utils.PatchObjectApplyConfiguration(ctx, r.client, svcApplyConfig, utils.PatchSpec); err != nil {
+	if err := utils.PatchObjectApplyConfiguration(ctx, k8sClient, svcApplyConfig, utils.PatchSpec); err != nil {
 		logger.Error(err, "Failed to patch svc apply configuration")
 		return err
 	}
@@ -395,6 +417,7 @@ func (r *StatefulSetReconciler) constructStatefulSetApplyConfiguration(
 			WithSelector(metaapplyv1.LabelSelector().
 				WithMatchLabels(matchLabels))).
 		WithAnnotations(rbg.GetCommonAnnotationsFromRole(role)).
+		WithAnnotations(revisionAnnotations(ctx)).
 		WithLabels(matchLabels).
 		WithOwnerReferences(metaapplyv1.OwnerReference().
 			WithAPIVersion(rbg.APIVersion).
@@ -407,12 +430,12 @@ func (r *StatefulSetReconciler) constructStatefulSetApplyConfiguration(
 	return statefulSetConfig, nil
 }
 
-func (r *StatefulSetReconciler) constructServiceApplyConfiguration(
-	ctx context.Context,
+func constructServiceApplyConfiguration(
 	rbg *workloadsv1alpha1.RoleBasedGroup,
 	role *workloadsv1alpha1.RoleSpec,
-	sts *appsv1.StatefulSet,
+	owner client.Object,
 ) (*coreapplyv1.ServiceApplyConfiguration, error) {
+	ownerAPIVersion, ownerKind := owner.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
 	selectMap := map[string]string{
 		workloadsv1alpha1.SetNameLabelKey: rbg.Name,
 		workloadsv1alpha1.SetRoleLabelKey: role.Name,
@@ -425,10 +448,10 @@ func (r *StatefulSetReconciler) constructServiceApplyConfiguration(
 		WithLabels(rbg.GetCommonLabelsFromRole(role)).
 		WithAnnotations(rbg.GetCommonAnnotationsFromRole(role)).
 		WithOwnerReferences(metaapplyv1.OwnerReference().
-			WithAPIVersion(sts.APIVersion).
-			WithKind(sts.Kind).
-			WithName(sts.Name).
-			WithUID(sts.GetUID()).
+			WithAPIVersion(ownerAPIVersion).
+			WithKind(ownerKind).
+			WithName(owner.GetName()).
+			WithUID(owner.GetUID()).
 			WithBlockOwnerDeletion(true),
 		)
 	return serviceConfig, nil
@@ -445,20 +468,69 @@ func (r *StatefulSetReconciler) ConstructRoleStatus(
 		return workloadsv1alpha1.RoleStatus{}, updateStatus, err
 	}
 
-	currentReplicas := *sts.Spec.Replicas
-	currentReady := sts.Status.ReadyReplicas
+	updatedReady, err := r.updatedReadyReplicas(ctx, sts)
+	if err != nil {
+		return workloadsv1alpha1.RoleStatus{}, updateStatus, err
+	}
+
 	status, found := rbg.GetRoleStatus(role.Name)
-	if !found || status.Replicas != currentReplicas || status.ReadyReplicas != currentReady {
-		status = workloadsv1alpha1.RoleStatus{
-			Name:          role.Name,
-			Replicas:      currentReplicas,
-			ReadyReplicas: currentReady,
-		}
+	newStatus := workloadsv1alpha1.RoleStatus{
+		Name:                 role.Name,
+		Replicas:             *sts.Spec.Replicas,
+		ReadyReplicas:        sts.Status.ReadyReplicas,
+		UpdatedReplicas:      sts.Status.UpdatedReplicas,
+		UpdatedReadyReplicas: updatedReady,
+		CurrentRevision:      status.CurrentRevision,
+		UpdateRevision:       status.UpdateRevision,
+		ObservedGeneration:   status.ObservedGeneration,
+		Conditions:           status.Conditions,
+	}
+	if !found || !apiequality.Semantic.DeepEqual(status, newStatus) {
+		status = newStatus
 		updateStatus = true
 	}
 	return status, updateStatus, nil
 }
 
+// updatedReadyReplicas counts the ready pods of sts running its update revision.
+func (r *StatefulSetReconciler) updatedReadyReplicas(ctx context.Context, sts *appsv1.StatefulSet) (int32, error) {
+	if sts.Status.UpdatedReplicas == 0 || sts.Status.UpdateRevision == "" || sts.Spec.Selector == nil {
+		return 0, nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.client.List(ctx, podList, client.InNamespace(sts.Namespace),
+		client.MatchingLabels(sts.Spec.Selector.MatchLabels)); err != nil {
+		return 0, err
+	}
+	var updatedReady int32
+	for _, pod := range podList.Items {
+		if pod.Labels[appsv1.ControllerRevisionHashLabelKey] == sts.Status.UpdateRevision && utils.PodRunningAndReady(pod) {
+			updatedReady++
+		}
+	}
+	return updatedReady, nil
+}
+
+func (r *StatefulSetReconciler) RolloutState(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (*RolloutState, error) {
+	sts := &appsv1.StatefulSet{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// surge replicas are created at the update revision, do not count them as updated replicas of the role
+	surge := utils.NonZeroValue(*sts.Spec.Replicas - *role.Replicas)
+	return &RolloutState{
+		Revision:        sts.Annotations[workloadsv1alpha1.RevisionAnnotationKey],
+		UpdatedReplicas: utils.NonZeroValue(sts.Status.UpdatedReplicas - surge),
+		ReadyReplicas:   sts.Status.ReadyReplicas,
+		Observed:        sts.Status.ObservedGeneration >= sts.Generation,
+	}, nil
+}
+
 func (r *StatefulSetReconciler) CheckWorkloadReady(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (bool, error) {
 	sts := &appsv1.StatefulSet{}
 	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, sts); err != nil {
@@ -485,7 +557,7 @@ func (r *StatefulSetReconciler) CleanupOrphanedWorkloads(ctx context.Context, rb
 		}
 		found := false
 		for _, role := range rbg.Spec.Roles {
-			if role.Workload.Kind == "StatefulSet" && rbg.GetWorkloadName(&role) == sts.Name {
+			if role.Workload.String() == workloadsv1alpha1.StatefulSetWorkloadType && rbg.GetWorkloadName(&role) == sts.Name {
 				found = true
 				break
 			}
@@ -515,6 +587,8 @@ func (r *StatefulSetReconciler) getHighestRevision(ctx context.Context, sts *app
 	return utils.GetHighestRevision(revisions), nil
 }
 
+// RecreateWorkload deletes the sts of role with its pods in the foreground, it is created again
+// by the next reconciliation of rbg.
 func (r *StatefulSetReconciler) RecreateWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error {
 	logger := log.FromContext(ctx)
 	if rbg == nil || role == nil {
@@ -533,30 +607,25 @@ func (r *StatefulSetReconciler) RecreateWorkload(ctx context.Context, rbg *workl
 	}
 
 	logger.Info(fmt.Sprintf("Recreate sts workload, delete sts %s", stsName))
-	if err := r.client.Delete(ctx, &sts); err != nil && !apierrors.IsNotFound(err) {
+	if err := r.client.Delete(ctx, &sts, client.PropagationPolicy(v1.DeletePropagationForeground)); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 
-	// wait new sts create
-	var retErr error
-	err = wait.PollUntilContextTimeout(ctx, 5*time.Second, 5*time.Minute, true, func(ctx context.Context) (bool, error) {
-		var newSts appsv1.StatefulSet
-		retErr = r.client.Get(ctx, types.NamespacedName{Name: stsName, Namespace: rbg.Namespace}, &newSts)
-		if retErr != nil {
-			if apierrors.IsNotFound(retErr) {
-				return false, nil
-			}
-			return false, retErr
-		}
-		return true, nil
-	})
+	return nil
+}
 
-	if err != nil {
-		logger.Error(retErr, "wait new sts creating error")
-		return retErr
+// statefulSetEqual determines whether the update of a StatefulSet needs no reconciliation.
+func statefulSetEqual(oldObj, newObj client.Object) (bool, error) {
+	o1, o2 := oldObj.(*appsv1.StatefulSet), newObj.(*appsv1.StatefulSet)
+	// check spec
+	if equal, err := SemanticallyEqualStatefulSet(o1, o2); !equal {
+		return false, fmt.Errorf("sts not equal, error: %s", err.Error())
 	}
-
-	return nil
+	// check status
+	if o1.Status.ReadyReplicas != o2.Status.ReadyReplicas {
+		return false, fmt.Errorf("ReadyReplicas not equal, old: %d, new: %d", o1.Status.ReadyReplicas, o2.Status.ReadyReplicas)
+	}
+	return true, nil
 }
 
 func SemanticallyEqualStatefulSet(oldSts, newSts *appsv1.StatefulSet) (bool, error) {
@@ -613,7 +682,9 @@ func SemanticallyEqualService(svc1, svc2 *corev1.Service) (bool, error) {
 	return true, nil
 }
 
-func validateRolloutStrategy(rollingStrategy *workloadsv1alpha1.RolloutStrategy, replicas int) (*workloadsv1alpha1.RolloutStrategy, error) {
+// ValidateRolloutStrategy returns the rollout strategy to apply for the given replicas, falling back to
+// maxUnavailable=1 and maxSurge=0 when no rolling update is configured.
+func ValidateRolloutStrategy(rollingStrategy *workloadsv1alpha1.RolloutStrategy, replicas int) (*workloadsv1alpha1.RolloutStrategy, error) {
 	if rollingStrategy == nil || rollingStrategy.RollingUpdate == nil {
 		return &workloadsv1alpha1.RolloutStrategy{
 			Type: workloadsv1alpha1.RollingUpdateStrategyType,
@@ -621,6 +692,7 @@ func validateRolloutStrategy(rollingStrategy *workloadsv1alpha1.RolloutStrategy,
 				MaxUnavailable: intstr.FromInt32(1),
 				MaxSurge:       intstr.FromInt32(0),
 			},
+			Paused: rollingStrategy != nil && rollingStrategy.Paused,
 		}, nil
 	}
 
@@ -110,6 +110,7 @@ func getFakeClient() client.Client {
 func TestStatefulSetReconciler_ConstructRoleStatus(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = appsv1.AddToScheme(scheme) // 添加 StatefulSet 类型支持
+	_ = corev1.AddToScheme(scheme)
 	_ = workloadsv1alpha1.AddToScheme(scheme)
 	type fields struct {
 		client client.Client
@@ -135,6 +136,27 @@ func TestStatefulSetReconciler_ConstructRoleStatus(t *testing.T) {
 		},
 	}
 
+	podLabels := map[string]string{workloadsv1alpha1.SetNameLabelKey: "test-rbg", workloadsv1alpha1.SetRoleLabelKey: "test-role"}
+	updatingSTS := testSTS.DeepCopy()
+	updatingSTS.Spec.Selector = &metav1.LabelSelector{MatchLabels: podLabels}
+	updatingSTS.Status.UpdatedReplicas = 2
+	updatingSTS.Status.UpdateRevision = "rev-2"
+	buildStsPod := func(name, revision string, ready bool) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{
+				appsv1.ControllerRevisionHashLabelKey: revision,
+			}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		for k, v := range podLabels {
+			pod.Labels[k] = v
+		}
+		if ready {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		return pod
+	}
+
 	tests := []struct {
 		name             string
 		fields           fields
@@ -247,7 +269,49 @@ func TestStatefulSetReconciler_ConstructRoleStatus(t *testing.T) {
 			wantUpdateStatus: true,
 			wantErr:          false,
 		},
-		// 用例4: StatefulSet 不存在
+		// 用例4: 滚动升级中, 统计已升级且就绪的副本
+		{
+			name: "rolling-update-in-progress",
+			fields: fields{
+				scheme: scheme,
+				client: fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithObjects(
+						updatingSTS.DeepCopy(),
+						buildStsPod("test-rbg-test-role-0", "rev-1", true),
+						buildStsPod("test-rbg-test-role-1", "rev-2", false),
+						buildStsPod("test-rbg-test-role-2", "rev-2", true),
+					).
+					Build(),
+			},
+			args: args{
+				ctx: context.Background(),
+				rbg: &workloadsv1alpha1.RoleBasedGroup{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-rbg",
+						Namespace: "default",
+					},
+					Status: workloadsv1alpha1.RoleBasedGroupStatus{
+						RoleStatuses: []workloadsv1alpha1.RoleStatus{
+							{Name: "test-role", Replicas: 3, ReadyReplicas: 3, CurrentRevision: "r1", UpdateRevision: "r2"},
+						},
+					},
+				},
+				role: &workloadsv1alpha1.RoleSpec{Name: "test-role"},
+			},
+			wantStatus: workloadsv1alpha1.RoleStatus{
+				Name:                 "test-role",
+				Replicas:             3,
+				ReadyReplicas:        2,
+				UpdatedReplicas:      2,
+				UpdatedReadyReplicas: 1,
+				CurrentRevision:      "r1",
+				UpdateRevision:       "r2",
+			},
+			wantUpdateStatus: true,
+			wantErr:          false,
+		},
+		// 用例5: StatefulSet 不存在
 		{
 			name: "statefulset-not-found",
 			fields: fields{
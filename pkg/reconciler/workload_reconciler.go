@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	lwsv1 "sigs.k8s.io/lws/api/leaderworkerset/v1"
 
-	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 )
 
@@ -17,66 +19,81 @@ type WorkloadReconciler interface {
 	ConstructRoleStatus(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (workloadsv1alpha1.RoleStatus, bool, error)
 	CheckWorkloadReady(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (bool, error)
 	CleanupOrphanedWorkloads(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error
+	// RecreateWorkload deletes the workload of role without waiting, it is created again by the next
+	// reconciliation of rbg.
 	RecreateWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) error
 }
 
-func NewWorkloadReconciler(workload workloadsv1alpha1.WorkloadSpec, scheme *runtime.Scheme, client client.Client) (WorkloadReconciler, error) {
-	switch {
-	case workload.String() == workloadsv1alpha1.DeploymentWorkloadType:
-		return NewDeploymentReconciler(scheme, client), nil
-	case workload.String() == workloadsv1alpha1.StatefulSetWorkloadType:
-		return NewStatefulSetReconciler(scheme, client), nil
-	case workload.String() == workloadsv1alpha1.LeaderWorkerSetWorkloadType:
-		return NewLeaderWorkerSetReconciler(scheme, client), nil
-	default:
-		return nil, fmt.Errorf("unsupported workload type: %s", workload.String())
-	}
+// WorkloadFailureReader is implemented by the reconcilers of the workloads which can fail for good, like Jobs,
+// and never become ready without being recreated.
+type WorkloadFailureReader interface {
+	// WorkloadFailure returns why the workload of role failed, or an empty string if it did not fail.
+	WorkloadFailure(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) (string, error)
 }
 
-// WorkloadEqual determines whether the workload needs reconciliation
-func WorkloadEqual(obj1, obj2 interface{}) (bool, error) {
-	switch o1 := obj1.(type) {
-	case *appsv1.Deployment:
-		if o2, ok := obj2.(*appsv1.Deployment); ok {
-			// check spec
-			if equal, err := SemanticallyEqualDeployment(o1, o2); !equal {
-				return false, fmt.Errorf("deploy not equal, error: %s", err.Error())
-			}
-			// check status
-			if o1.Status.ReadyReplicas != o2.Status.ReadyReplicas {
-				return false, fmt.Errorf("ReadyReplicas not equal, old: %d, new: %d", o1.Status.ReadyReplicas, o2.Status.ReadyReplicas)
-			}
+// WorkloadDeleter is implemented by the reconcilers of the workloads which are not a single object
+// named after their role, like bare pods.
+type WorkloadDeleter interface {
+	// DeleteWorkload deletes the workload of the role roleName, which may have been removed from the spec of rbg.
+	DeleteWorkload(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleName string) error
+	// WorkloadExists reports whether the workload of the role roleName exists, terminating or not.
+	WorkloadExists(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleName string) (bool, error)
+}
 
-			return true, nil
-		}
-	case *appsv1.StatefulSet:
-		if o2, ok := obj2.(*appsv1.StatefulSet); ok {
-			// check spec
-			if equal, err := SemanticallyEqualStatefulSet(o1, o2); !equal {
-				return false, fmt.Errorf("sts not equal, error: %s", err.Error())
-			}
-			// check status
-			if o1.Status.ReadyReplicas != o2.Status.ReadyReplicas {
-				return false, fmt.Errorf("ReadyReplicas not equal, old: %d, new: %d", o1.Status.ReadyReplicas, o2.Status.ReadyReplicas)
-			}
+// DeleteRoleWorkload deletes the workload of plugin of the role roleName controlled by rbg, if it exists.
+// The pods of the workload are deleted in the background.
+func DeleteRoleWorkload(ctx context.Context, scheme *runtime.Scheme, c client.Client, plugin WorkloadPlugin,
+	rbg *workloadsv1alpha1.RoleBasedGroup, roleName string) error {
+	if deleter, ok := plugin.NewReconciler(scheme, c).(WorkloadDeleter); ok {
+		return deleter.DeleteWorkload(ctx, rbg, roleName)
+	}
+
+	obj := plugin.NewObject()
+	if err := c.Get(ctx, types.NamespacedName{Namespace: rbg.Namespace, Name: rbg.GetWorkloadName(&workloadsv1alpha1.RoleSpec{Name: roleName})}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(obj, rbg) || obj.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	log.FromContext(ctx).Info("delete workload", "kind", plugin.GVK.Kind, "workload", obj.GetName())
+	return client.IgnoreNotFound(c.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+}
 
-			return true, nil
-		}
+// RoleWorkloadExists reports whether the workload of plugin of the role roleName exists, terminating or not.
+func RoleWorkloadExists(ctx context.Context, scheme *runtime.Scheme, c client.Client, plugin WorkloadPlugin,
+	rbg *workloadsv1alpha1.RoleBasedGroup, roleName string) (bool, error) {
+	if deleter, ok := plugin.NewReconciler(scheme, c).(WorkloadDeleter); ok {
+		return deleter.WorkloadExists(ctx, rbg, roleName)
+	}
 
-	case *lwsv1.LeaderWorkerSet:
-		if o2, ok := obj2.(*lwsv1.LeaderWorkerSet); ok {
-			// check spec
-			if equal, err := semanticallyEqualLeaderWorkerSet(o1, o2); !equal {
-				return false, fmt.Errorf("lws not equal, error: %s", err.Error())
-			}
-			// check status
-			if o1.Status.ReadyReplicas != o2.Status.ReadyReplicas {
-				return false, fmt.Errorf("ReadyReplicas not equal, old: %d, new: %d", o1.Status.ReadyReplicas, o2.Status.ReadyReplicas)
-			}
+	obj := plugin.NewObject()
+	err := c.Get(ctx, types.NamespacedName{Namespace: rbg.Namespace, Name: rbg.GetWorkloadName(&workloadsv1alpha1.RoleSpec{Name: roleName})}, obj)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
 
-			return true, nil
-		}
+// NewWorkloadReconciler builds the reconciler of the workload plugin registered for workload.
+func NewWorkloadReconciler(workload workloadsv1alpha1.WorkloadSpec, scheme *runtime.Scheme, client client.Client) (WorkloadReconciler, error) {
+	plugin, ok := LookupWorkload(workload)
+	if !ok {
+		return nil, fmt.Errorf("unsupported workload type: %s", workload.String())
 	}
+	return plugin.NewReconciler(scheme, client), nil
+}
 
-	return false, fmt.Errorf("not support workload: %v", reflect.TypeOf(obj1))
+// IsSupportedWorkload reports whether a workload plugin is registered for the workload.
+func IsSupportedWorkload(workload workloadsv1alpha1.WorkloadSpec) bool {
+	_, ok := LookupWorkload(workload)
+	return ok
+}
+
+// WorkloadEqual determines whether the workload needs reconciliation
+func WorkloadEqual(obj1, obj2 interface{}) (bool, error) {
+	plugin, ok := workloadPluginFor(obj1)
+	if !ok || reflect.TypeOf(obj1) != reflect.TypeOf(obj2) {
+		return false, fmt.Errorf("not support workload: %v", reflect.TypeOf(obj1))
+	}
+	return plugin.Equal(obj1.(client.Object), obj2.(client.Object))
 }
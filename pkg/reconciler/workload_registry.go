@@ -0,0 +1,99 @@
+package reconciler
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// WorkloadPlugin describes a workload kind the roles of a rbg can run as.
+// The Go type of the workload has to be registered in the scheme of the manager.
+type WorkloadPlugin struct {
+	// GVK is the group, version and kind of the workload, matching the workload of the role spec.
+	GVK schema.GroupVersionKind
+	// CrdName is the name of the CRD defining the workload, empty for the workloads built in Kubernetes.
+	// The workload is only watched once the CRD is installed.
+	CrdName string
+	// NewObject returns an empty workload object.
+	NewObject func() client.Object
+	// NewReconciler builds the reconciler of the workload.
+	NewReconciler func(scheme *runtime.Scheme, client client.Client) WorkloadReconciler
+	// Equal determines whether an update of the workload needs no reconciliation of its rbg.
+	// It returns the difference found as error.
+	Equal func(oldObj, newObj client.Object) (bool, error)
+}
+
+var (
+	workloadPluginsLock sync.RWMutex
+	workloadPlugins     = map[schema.GroupVersionKind]WorkloadPlugin{}
+)
+
+// RegisterWorkload registers plugin, it is meant to be called from init functions.
+// It panics if a plugin is already registered for the same GVK.
+func RegisterWorkload(plugin WorkloadPlugin) {
+	workloadPluginsLock.Lock()
+	defer workloadPluginsLock.Unlock()
+
+	if plugin.NewObject == nil || plugin.NewReconciler == nil || plugin.Equal == nil {
+		panic(fmt.Sprintf("incomplete workload plugin %s", plugin.GVK))
+	}
+	if _, ok := workloadPlugins[plugin.GVK]; ok {
+		panic(fmt.Sprintf("workload plugin %s registered twice", plugin.GVK))
+	}
+	workloadPlugins[plugin.GVK] = plugin
+}
+
+// LookupWorkload returns the plugin registered for the workload of a role.
+func LookupWorkload(workload workloadsv1alpha1.WorkloadSpec) (WorkloadPlugin, bool) {
+	workloadPluginsLock.RLock()
+	defer workloadPluginsLock.RUnlock()
+
+	plugin, ok := workloadPlugins[schema.FromAPIVersionAndKind(workload.APIVersion, workload.Kind)]
+	return plugin, ok
+}
+
+// WorkloadPlugins returns the registered plugins, sorted by GVK.
+func WorkloadPlugins() []WorkloadPlugin {
+	workloadPluginsLock.RLock()
+	defer workloadPluginsLock.RUnlock()
+
+	plugins := make([]WorkloadPlugin, 0, len(workloadPlugins))
+	for _, plugin := range workloadPlugins {
+		plugins = append(plugins, plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return workloadTypeName(plugins[i].GVK) < workloadTypeName(plugins[j].GVK)
+	})
+	return plugins
+}
+
+// SupportedWorkloads returns the workload types of the registered plugins, in the format of WorkloadSpec.String.
+func SupportedWorkloads() []string {
+	plugins := WorkloadPlugins()
+	types := make([]string, 0, len(plugins))
+	for _, plugin := range plugins {
+		types = append(types, workloadTypeName(plugin.GVK))
+	}
+	return types
+}
+
+// workloadPluginFor returns the plugin registered for the Go type of obj.
+func workloadPluginFor(obj interface{}) (WorkloadPlugin, bool) {
+	objType := reflect.TypeOf(obj)
+	for _, plugin := range WorkloadPlugins() {
+		if reflect.TypeOf(plugin.NewObject()) == objType {
+			return plugin, true
+		}
+	}
+	return WorkloadPlugin{}, false
+}
+
+func workloadTypeName(gvk schema.GroupVersionKind) string {
+	return fmt.Sprintf("%s/%s", gvk.GroupVersion().String(), gvk.Kind)
+}
@@ -0,0 +1,163 @@
+package reconciler
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	lwsv1 "sigs.k8s.io/lws/api/leaderworkerset/v1"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+func TestNewWorkloadReconciler(t *testing.T) {
+	tests := []struct {
+		name     string
+		workload workloadsv1alpha1.WorkloadSpec
+		want     reflect.Type
+		wantErr  bool
+	}{
+		{
+			name:     "statefulset",
+			workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"},
+			want:     reflect.TypeOf(&StatefulSetReconciler{}),
+		},
+		{
+			name:     "deployment",
+			workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "Deployment"},
+			want:     reflect.TypeOf(&DeploymentReconciler{}),
+		},
+		{
+			name:     "leaderworkerset",
+			workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "leaderworkerset.x-k8s.io/v1", Kind: "LeaderWorkerSet"},
+			want:     reflect.TypeOf(&LeaderWorkerSetReconciler{}),
+		},
+		{
+			name:     "kruise statefulset",
+			workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "apps.kruise.io/v1beta1", Kind: "StatefulSet"},
+			want:     reflect.TypeOf(&KruiseStatefulSetReconciler{}),
+		},
+		{
+			name:     "cloneset",
+			workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet"},
+			want:     reflect.TypeOf(&CloneSetReconciler{}),
+		},
+		{
+			name:     "job",
+			workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "batch/v1", Kind: "Job"},
+			want:     reflect.TypeOf(&JobReconciler{}),
+		},
+		{
+			name:     "pod",
+			workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "v1", Kind: "Pod"},
+			want:     reflect.TypeOf(&PodWorkloadReconciler{}),
+		},
+		{
+			name:     "unsupported version",
+			workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1beta1", Kind: "StatefulSet"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewWorkloadReconciler(tt.workload, nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewWorkloadReconciler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && reflect.TypeOf(got) != tt.want {
+				t.Errorf("NewWorkloadReconciler() = %T, want %v", got, tt.want)
+			}
+			if IsSupportedWorkload(tt.workload) == tt.wantErr {
+				t.Errorf("IsSupportedWorkload() = %v, want %v", !tt.wantErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSupportedWorkloads(t *testing.T) {
+	want := []string{
+		workloadsv1alpha1.CloneSetWorkloadType,
+		workloadsv1alpha1.KruiseStatefulSetWorkloadType,
+		workloadsv1alpha1.DeploymentWorkloadType,
+		workloadsv1alpha1.StatefulSetWorkloadType,
+		workloadsv1alpha1.JobWorkloadType,
+		workloadsv1alpha1.LeaderWorkerSetWorkloadType,
+		workloadsv1alpha1.PodWorkloadType,
+	}
+	if got := SupportedWorkloads(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SupportedWorkloads() = %v, want %v", got, want)
+	}
+
+	plugin, ok := LookupWorkload(workloadsv1alpha1.WorkloadSpec{APIVersion: "leaderworkerset.x-k8s.io/v1", Kind: "LeaderWorkerSet"})
+	if !ok || plugin.CrdName != utils.LwsCrdName {
+		t.Errorf("LookupWorkload() = %v, %v", plugin.GVK, ok)
+	}
+}
+
+func TestRegisterWorkload(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterWorkload() did not panic registering a GVK twice")
+		}
+	}()
+	RegisterWorkload(WorkloadPlugin{
+		GVK:       appsv1.SchemeGroupVersion.WithKind("StatefulSet"),
+		NewObject: func() client.Object { return &appsv1.StatefulSet{} },
+		NewReconciler: func(scheme *runtime.Scheme, client client.Client) WorkloadReconciler {
+			return NewStatefulSetReconciler(scheme, client)
+		},
+		Equal: statefulSetEqual,
+	})
+}
+
+func TestWorkloadEqual(t *testing.T) {
+	deploy := &appsv1.Deployment{}
+	deploy.UID = "deploy-uid"
+	deploy.Status.ReadyReplicas = 1
+	updatedDeploy := deploy.DeepCopy()
+	updatedDeploy.Status.ReadyReplicas = 2
+
+	tests := []struct {
+		name string
+		obj1 interface{}
+		obj2 interface{}
+		want bool
+	}{
+		{
+			name: "same deployment",
+			obj1: deploy,
+			obj2: deploy.DeepCopy(),
+			want: true,
+		},
+		{
+			name: "ready replicas changed",
+			obj1: deploy,
+			obj2: updatedDeploy,
+			want: false,
+		},
+		{
+			name: "different kinds",
+			obj1: &appsv1.StatefulSet{},
+			obj2: &lwsv1.LeaderWorkerSet{},
+			want: false,
+		},
+		{
+			name: "not a workload",
+			obj1: &schema.GroupVersionKind{},
+			obj2: &schema.GroupVersionKind{},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := WorkloadEqual(tt.obj1, tt.obj2); got != tt.want {
+				t.Errorf("WorkloadEqual() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"context"
+
+	coreapplyv1 "k8s.io/client-go/applyconfigurations/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// PodGroupBackend is a gang-scheduler that schedules the pods of a rbg through a PodGroup CRD.
+type PodGroupBackend interface {
+	// Name returns the name of the gang-scheduler.
+	Name() string
+
+	// CrdName returns the name of the PodGroup CRD the gang-scheduler relies on.
+	CrdName() string
+
+	// PodGroupObject returns an empty PodGroup object, used to watch the PodGroups owned by rbg.
+	PodGroupObject() client.Object
+
+	// Enabled reports whether the rbg selects this gang-scheduler in its podGroupPolicy.
+	Enabled(rbg *workloadsv1alpha.RoleBasedGroup) bool
+
+	// CreateOrUpdatePodGroup makes the PodGroup of rbg match its spec.
+	CreateOrUpdatePodGroup(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error
+
+	// DeletePodGroup removes the PodGroup of rbg if it exists.
+	DeletePodGroup(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error
+
+	// InjectPodGroupInfo adds the labels, annotations and scheduler name which
+	// bind the pods created from podTemplate to the PodGroup of rbg.
+	InjectPodGroupInfo(rbg *workloadsv1alpha.RoleBasedGroup, podTemplate *coreapplyv1.PodTemplateSpecApplyConfiguration)
+}
@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	coreapplyv1 "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+// KubeSchedulingBackend gang-schedules rbg pods with the coscheduling plugin of the Kubernetes scheduler-plugins.
+type KubeSchedulingBackend struct {
+	client client.Client
+}
+
+var _ PodGroupBackend = &KubeSchedulingBackend{}
+
+func NewKubeSchedulingBackend(client client.Client) *KubeSchedulingBackend {
+	return &KubeSchedulingBackend{client: client}
+}
+
+func (r *KubeSchedulingBackend) Name() string {
+	return "kube-scheduling"
+}
+
+func (r *KubeSchedulingBackend) CrdName() string {
+	return utils.PodGroupCrdName
+}
+
+func (r *KubeSchedulingBackend) PodGroupObject() client.Object {
+	return &schedv1alpha1.PodGroup{}
+}
+
+func (r *KubeSchedulingBackend) Enabled(rbg *workloadsv1alpha.RoleBasedGroup) bool {
+	return rbg.Spec.PodGroupPolicy != nil && rbg.Spec.PodGroupPolicy.KubeScheduling != nil
+}
+
+func (r *KubeSchedulingBackend) CreateOrUpdatePodGroup(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error {
+	logger := log.FromContext(ctx)
+	minMember := int32(rbg.GetGroupMinMember())
+	minResources, err := computeMinResources(rbg)
+	if err != nil {
+		return err
+	}
+	podGroup := &schedv1alpha1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rbg.Name,
+			Namespace: rbg.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(rbg, rbg.GroupVersionKind()),
+			},
+		},
+		Spec: schedv1alpha1.PodGroupSpec{
+			MinMember:              minMember,
+			MinResources:           minResources,
+			ScheduleTimeoutSeconds: rbg.Spec.PodGroupPolicy.KubeScheduling.ScheduleTimeoutSeconds,
+		},
+	}
+
+	err = r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup)
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "get pod group error")
+		return err
+	}
+
+	if apierrors.IsNotFound(err) {
+		err = r.client.Create(ctx, podGroup)
+		if err != nil {
+			logger.Error(err, "create pod group error")
+		}
+		return err
+	}
+
+	if podGroup.Spec.MinMember != minMember || !resourceListEqual(podGroup.Spec.MinResources, minResources) {
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup); err != nil {
+				return err
+			}
+			podGroup.Spec.MinMember = minMember
+			podGroup.Spec.MinResources = minResources
+			updateErr := r.client.Update(ctx, podGroup)
+			return updateErr
+		})
+		if err != nil {
+			logger.Error(err, "update pod group error")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *KubeSchedulingBackend) DeletePodGroup(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error {
+	podGroup := &schedv1alpha1.PodGroup{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	return r.client.Delete(ctx, podGroup)
+}
+
+func (r *KubeSchedulingBackend) InjectPodGroupInfo(rbg *workloadsv1alpha.RoleBasedGroup, podTemplate *coreapplyv1.PodTemplateSpecApplyConfiguration) {
+	podTemplate.WithLabels(map[string]string{
+		workloadsv1alpha.PodGroupLabelKey: rbg.Name,
+	})
+}
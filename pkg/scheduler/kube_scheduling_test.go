@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func buildElasticRbg(decodeReplicas int32, decodeMinAvailable *intstr.IntOrString) *workloadsv1alpha.RoleBasedGroup {
+	template := wrappers.BuildBasicPodTemplateSpec().Obj()
+	template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("1"),
+	}
+	decode := wrappers.BuildBasicRole("decode").WithReplicas(decodeReplicas).WithTemplate(template).Obj()
+	decode.MinAvailable = decodeMinAvailable
+	return wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").
+		WithRoles([]workloadsv1alpha.RoleSpec{
+			wrappers.BuildBasicRole("prefill").WithReplicas(1).WithTemplate(template).Obj(),
+			decode,
+		}).
+		WithGangScheduling(true).Obj()
+}
+
+func TestKubeSchedulingBackend_CreateOrUpdatePodGroup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = workloadsv1alpha.AddToScheme(scheme)
+	_ = schedv1alpha1.AddToScheme(scheme)
+
+	tests := []struct {
+		name          string
+		rbg           *workloadsv1alpha.RoleBasedGroup
+		existing      *schedv1alpha1.PodGroup
+		wantMinMember int32
+		wantCPU       string
+	}{
+		{
+			name:          "all replicas by default",
+			rbg:           buildElasticRbg(4, nil),
+			wantMinMember: 5,
+			wantCPU:       "5",
+		},
+		{
+			name:          "absolute min available",
+			rbg:           buildElasticRbg(4, ptr.To(intstr.FromInt32(2))),
+			wantMinMember: 3,
+			wantCPU:       "3",
+		},
+		{
+			name:          "percent min available is rounded up",
+			rbg:           buildElasticRbg(3, ptr.To(intstr.FromString("50%"))),
+			wantMinMember: 3,
+			wantCPU:       "3",
+		},
+		{
+			name: "scale-up of elastic role keeps the gang",
+			rbg:  buildElasticRbg(8, ptr.To(intstr.FromInt32(2))),
+			existing: &schedv1alpha1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+				Spec: schedv1alpha1.PodGroupSpec{
+					MinMember:    3,
+					MinResources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")},
+				},
+			},
+			wantMinMember: 3,
+			wantCPU:       "3",
+		},
+		{
+			name: "scale-down below min available caps the gang",
+			rbg:  buildElasticRbg(1, ptr.To(intstr.FromInt32(2))),
+			existing: &schedv1alpha1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+				Spec:       schedv1alpha1.PodGroupSpec{MinMember: 3},
+			},
+			wantMinMember: 2,
+			wantCPU:       "2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if tt.existing != nil {
+				builder.WithObjects(tt.existing)
+			}
+			fakeClient := builder.Build()
+
+			if err := NewKubeSchedulingBackend(fakeClient).CreateOrUpdatePodGroup(context.TODO(), tt.rbg); err != nil {
+				t.Fatalf("CreateOrUpdatePodGroup() error = %v", err)
+			}
+
+			podGroup := &schedv1alpha1.PodGroup{}
+			if err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: tt.rbg.Name, Namespace: tt.rbg.Namespace}, podGroup); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if podGroup.Spec.MinMember != tt.wantMinMember {
+				t.Errorf("minMember = %d, want %d", podGroup.Spec.MinMember, tt.wantMinMember)
+			}
+			cpu := podGroup.Spec.MinResources[corev1.ResourceCPU]
+			if cpu.Cmp(resource.MustParse(tt.wantCPU)) != 0 {
+				t.Errorf("minResources cpu = %s, want %s", cpu.String(), tt.wantCPU)
+			}
+		})
+	}
+}
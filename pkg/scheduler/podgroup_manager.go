@@ -2,89 +2,105 @@ package scheduler
 
 import (
 	"context"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/util/retry"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	coreapplyv1 "k8s.io/client-go/applyconfigurations/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
 	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
-	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
 )
 
+// PodGroupScheduler dispatches the PodGroup of a rbg to the gang-scheduler selected in its podGroupPolicy.
 type PodGroupScheduler struct {
-	client client.Client
+	backends []PodGroupBackend
 }
 
 func NewPodGroupScheduler(client client.Client) *PodGroupScheduler {
-	return &PodGroupScheduler{client: client}
-}
-
-func (r *PodGroupScheduler) Reconcile(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error {
-	if rbg.EnableGangScheduling() {
-		return r.createOrUpdatePodGroup(ctx, rbg)
-	} else {
-		return r.deletePodGroup(ctx, rbg)
+	return &PodGroupScheduler{
+		backends: []PodGroupBackend{
+			NewKubeSchedulingBackend(client),
+			NewVolcanoBackend(client),
+		},
 	}
+}
 
+// Backends returns all the supported gang-schedulers.
+func (r *PodGroupScheduler) Backends() []PodGroupBackend {
+	return r.backends
 }
 
-func (r *PodGroupScheduler) createOrUpdatePodGroup(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error {
-	logger := log.FromContext(ctx)
-	podGroup := &schedv1alpha1.PodGroup{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      rbg.Name,
-			Namespace: rbg.Namespace,
-			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(rbg, rbg.GroupVersionKind()),
-			},
-		},
-		Spec: schedv1alpha1.PodGroupSpec{
-			MinMember:              int32(rbg.GetGroupSize()),
-			ScheduleTimeoutSeconds: rbg.Spec.PodGroupPolicy.KubeScheduling.ScheduleTimeoutSeconds,
-		},
+// Reconcile creates or updates the PodGroup of backend if rbg selects it, otherwise deletes it.
+func (r *PodGroupScheduler) Reconcile(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup, backend PodGroupBackend) error {
+	if backend.Enabled(rbg) {
+		return backend.CreateOrUpdatePodGroup(ctx, rbg)
 	}
+	return backend.DeletePodGroup(ctx, rbg)
+}
 
-	err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup)
-	if err != nil && !apierrors.IsNotFound(err) {
-		logger.Error(err, "get pod group error")
-		return err
+// InjectPodGroupInfo binds the pods created from podTemplate to the PodGroup of the selected gang-scheduler.
+func (r *PodGroupScheduler) InjectPodGroupInfo(rbg *workloadsv1alpha.RoleBasedGroup, podTemplate *coreapplyv1.PodTemplateSpecApplyConfiguration) {
+	for _, backend := range r.backends {
+		if backend.Enabled(rbg) {
+			backend.InjectPodGroupInfo(rbg, podTemplate)
+		}
 	}
+}
 
-	if apierrors.IsNotFound(err) {
-		err = r.client.Create(ctx, podGroup)
-		if err != nil {
-			logger.Error(err, "create pod group error")
+// computeMinResources sums the requests of the pods each role requires for gang-scheduling. The pods of a
+// LeaderWorkerSet role request the resources of their leader or worker template.
+func computeMinResources(rbg *workloadsv1alpha.RoleBasedGroup) (corev1.ResourceList, error) {
+	minResources := corev1.ResourceList{}
+	for i := range rbg.Spec.Roles {
+		role := &rbg.Spec.Roles[i]
+		instances := int64(role.GetMinAvailable())
+		if instances == 0 {
+			continue
 		}
-		return err
-	}
 
-	if podGroup.Spec.MinMember != int32(rbg.GetGroupSize()) {
-		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup); err != nil {
-				return err
-			}
-			podGroup.Spec.MinMember = int32(rbg.GetGroupSize())
-			updateErr := r.client.Update(ctx, podGroup)
-			return updateErr
-		})
+		if role.Workload.String() != workloadsv1alpha.LeaderWorkerSetWorkloadType || role.LeaderWorkerSet.Size == nil {
+			addPodRequests(minResources, role.Template, instances*int64(role.GetPodsPerInstance()))
+			continue
+		}
+		leaderTemplate, err := utils.PatchPodTemplate(role.Template, role.LeaderWorkerSet.PatchLeaderTemplate)
 		if err != nil {
-			logger.Error(err, "update pod group error")
+			return nil, fmt.Errorf("failed to patch the leader template of role %s: %w", role.Name, err)
 		}
-		return err
+		workerTemplate, err := utils.PatchPodTemplate(role.Template, role.LeaderWorkerSet.PatchWorkerTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to patch the worker template of role %s: %w", role.Name, err)
+		}
+		addPodRequests(minResources, leaderTemplate, instances)
+		addPodRequests(minResources, workerTemplate, instances*int64(role.GetPodsPerInstance()-1))
 	}
-
-	return nil
+	return minResources, nil
 }
 
-func (r *PodGroupScheduler) deletePodGroup(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error {
-	podGroup := &schedv1alpha1.PodGroup{}
-	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup); err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil
+// addPodRequests adds the requests of podNum pods of template to resources.
+func addPodRequests(resources corev1.ResourceList, template corev1.PodTemplateSpec, podNum int64) {
+	if podNum <= 0 {
+		return
+	}
+	for name, quantity := range utils.PodRequests(template.Spec) {
+		total := quantity.DeepCopy()
+		total.Mul(podNum)
+		if current, ok := resources[name]; ok {
+			total.Add(current)
 		}
-		return err
+		resources[name] = total
 	}
+}
 
-	return r.client.Delete(ctx, podGroup)
+// resourceListEqual compares quantities semantically, so that 1000m equals 1.
+func resourceListEqual(a, b corev1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, qa := range a {
+		qb, ok := b[name]
+		if !ok || qa.Cmp(qb) != 0 {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+func TestComputeMinResources(t *testing.T) {
+	cpu := func(quantity string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(quantity)}}
+	}
+	withInitContainers := func(role workloadsv1alpha.RoleSpec, initContainers ...corev1.Container) workloadsv1alpha.RoleSpec {
+		role.Template.Spec.Containers[0].Resources = cpu("1")
+		role.Template.Spec.InitContainers = initContainers
+		return role
+	}
+
+	tests := []struct {
+		name string
+		role workloadsv1alpha.RoleSpec
+		want string
+	}{
+		{
+			name: "containers",
+			role: withInitContainers(wrappers.BuildBasicRole("worker").WithReplicas(2).Obj()),
+			want: "2",
+		},
+		{
+			name: "init container larger than the containers",
+			role: withInitContainers(wrappers.BuildBasicRole("worker").WithReplicas(2).Obj(),
+				corev1.Container{Name: "init", Resources: cpu("3")}),
+			want: "6",
+		},
+		{
+			name: "sidecar running next to the containers",
+			role: withInitContainers(wrappers.BuildBasicRole("worker").WithReplicas(2).Obj(),
+				corev1.Container{Name: "sidecar", Resources: cpu("500m"), RestartPolicy: ptr.To(corev1.ContainerRestartPolicyAlways)},
+				corev1.Container{Name: "init", Resources: cpu("1")}),
+			want: "3",
+		},
+		{
+			name: "pod overhead",
+			role: func() workloadsv1alpha.RoleSpec {
+				role := withInitContainers(wrappers.BuildBasicRole("worker").WithReplicas(2).Obj())
+				role.Template.Spec.Overhead = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")}
+				return role
+			}(),
+			want: "2500m",
+		},
+		{
+			name: "leader and worker templates",
+			role: func() workloadsv1alpha.RoleSpec {
+				role := withInitContainers(wrappers.BuildLwsRole("worker").WithReplicas(2).
+					WithLeaderWorkerTemplate(runtime.RawExtension{}, runtime.RawExtension{
+						Raw: []byte(`{"spec":{"containers":[{"name":"nginx","resources":{"requests":{"cpu":"4"}}}]}}`),
+					}).Obj())
+				role.LeaderWorkerSet.Size = ptr.To[int32](3)
+				return role
+			}(),
+			// 2 leaders of 1 cpu and 4 workers of 4 cpus
+			want: "18",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").
+				WithRoles([]workloadsv1alpha.RoleSpec{tt.role}).Obj()
+			got, err := computeMinResources(rbg)
+			if err != nil {
+				t.Fatalf("computeMinResources() error = %v", err)
+			}
+			if cpu := got[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse(tt.want)) != 0 {
+				t.Errorf("computeMinResources() cpu = %s, want %s", cpu.String(), tt.want)
+			}
+		})
+	}
+}
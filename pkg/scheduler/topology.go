@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// preferredTopologyWeight is the weight of the preferred terms of a topologyPolicy.
+const preferredTopologyWeight = 100
+
+// InjectTopologyAffinity adds to podSpec the pod affinity or anti-affinity term placing the pods of rbg
+// as its topologyPolicy asks for. The terms of the pod template are kept.
+func InjectTopologyAffinity(rbg *workloadsv1alpha.RoleBasedGroup, podSpec *corev1.PodSpec) {
+	policy := rbg.Spec.TopologyPolicy
+	if policy == nil {
+		return
+	}
+
+	term := corev1.PodAffinityTerm{TopologyKey: policy.TopologyKey}
+	switch policy.Mode {
+	case workloadsv1alpha.ColocateTopologyPolicyMode:
+		term.LabelSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{workloadsv1alpha.SetNameLabelKey: rbg.Name},
+		}
+	case workloadsv1alpha.SpreadTopologyPolicyMode:
+		setName := rbg.GetGroupSetName()
+		if setName == "" {
+			// only the replicas of a RoleBasedGroupSet are spread
+			return
+		}
+		term.LabelSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{workloadsv1alpha.GroupSetNameLabelKey: setName},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: workloadsv1alpha.SetNameLabelKey, Operator: metav1.LabelSelectorOpNotIn, Values: []string{rbg.Name}},
+			},
+		}
+	default:
+		return
+	}
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	var required *[]corev1.PodAffinityTerm
+	var preferred *[]corev1.WeightedPodAffinityTerm
+	if policy.Mode == workloadsv1alpha.ColocateTopologyPolicyMode {
+		if podSpec.Affinity.PodAffinity == nil {
+			podSpec.Affinity.PodAffinity = &corev1.PodAffinity{}
+		}
+		required = &podSpec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		preferred = &podSpec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	} else {
+		if podSpec.Affinity.PodAntiAffinity == nil {
+			podSpec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+		}
+		required = &podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		preferred = &podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	}
+
+	if policy.Preferred {
+		weighted := corev1.WeightedPodAffinityTerm{Weight: preferredTopologyWeight, PodAffinityTerm: term}
+		for _, t := range *preferred {
+			if apiequality.Semantic.DeepEqual(t, weighted) {
+				return
+			}
+		}
+		*preferred = append(*preferred, weighted)
+		return
+	}
+	for _, t := range *required {
+		if apiequality.Semantic.DeepEqual(t, term) {
+			return
+		}
+	}
+	*required = append(*required, term)
+}
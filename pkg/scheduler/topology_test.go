@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
+)
+
+func TestInjectTopologyAffinity(t *testing.T) {
+	const zoneKey = "topology.kubernetes.io/zone"
+	colocateTerm := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{workloadsv1alpha.SetNameLabelKey: "test-rbg"}},
+		TopologyKey:   zoneKey,
+	}
+	spreadTerm := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{workloadsv1alpha.GroupSetNameLabelKey: "test-rbgset"},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: workloadsv1alpha.SetNameLabelKey, Operator: metav1.LabelSelectorOpNotIn, Values: []string{"test-rbg"}},
+			},
+		},
+		TopologyKey: zoneKey,
+	}
+	userTerm := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "cache"}},
+		TopologyKey:   "kubernetes.io/hostname",
+	}
+
+	spread := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").
+		WithTopologyPolicy(workloadsv1alpha.SpreadTopologyPolicyMode, zoneKey).Obj()
+	spread.Labels = map[string]string{workloadsv1alpha.GroupSetLabelKey: "test-rbgset"}
+
+	preferredColocate := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").
+		WithTopologyPolicy(workloadsv1alpha.ColocateTopologyPolicyMode, zoneKey).Obj()
+	preferredColocate.Spec.TopologyPolicy.Preferred = true
+
+	tests := []struct {
+		name     string
+		rbg      *workloadsv1alpha.RoleBasedGroup
+		affinity *corev1.Affinity
+		want     *corev1.Affinity
+	}{
+		{
+			name: "no topology policy",
+			rbg:  wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").Obj(),
+		},
+		{
+			name: "colocate",
+			rbg: wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").
+				WithTopologyPolicy(workloadsv1alpha.ColocateTopologyPolicyMode, zoneKey).Obj(),
+			want: &corev1.Affinity{PodAffinity: &corev1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{colocateTerm},
+			}},
+		},
+		{
+			name: "preferred colocate",
+			rbg:  preferredColocate,
+			want: &corev1.Affinity{PodAffinity: &corev1.PodAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+					{Weight: preferredTopologyWeight, PodAffinityTerm: colocateTerm},
+				},
+			}},
+		},
+		{
+			name: "spread outside of a set",
+			rbg: wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").
+				WithTopologyPolicy(workloadsv1alpha.SpreadTopologyPolicyMode, zoneKey).Obj(),
+		},
+		{
+			name: "spread keeps the terms of the template",
+			rbg:  spread,
+			affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{userTerm},
+			}},
+			want: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{userTerm, spreadTerm},
+			}},
+		},
+		{
+			name: "term already in the template",
+			rbg: wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").
+				WithTopologyPolicy(workloadsv1alpha.ColocateTopologyPolicyMode, zoneKey).Obj(),
+			affinity: &corev1.Affinity{PodAffinity: &corev1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{colocateTerm},
+			}},
+			want: &corev1.Affinity{PodAffinity: &corev1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{colocateTerm},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podSpec := &corev1.PodSpec{Affinity: tt.affinity}
+			InjectTopologyAffinity(tt.rbg, podSpec)
+			if !reflect.DeepEqual(podSpec.Affinity, tt.want) {
+				t.Errorf("InjectTopologyAffinity() affinity = %+v, want %+v", podSpec.Affinity, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package scheduler
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	coreapplyv1 "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
+	volcanov1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+)
+
+// VolcanoSchedulerName is the scheduler name of the volcano scheduler.
+const VolcanoSchedulerName = "volcano"
+
+// VolcanoBackend gang-schedules rbg pods with the Volcano scheduler.
+type VolcanoBackend struct {
+	client client.Client
+}
+
+var _ PodGroupBackend = &VolcanoBackend{}
+
+func NewVolcanoBackend(client client.Client) *VolcanoBackend {
+	return &VolcanoBackend{client: client}
+}
+
+func (r *VolcanoBackend) Name() string {
+	return "volcano"
+}
+
+func (r *VolcanoBackend) CrdName() string {
+	return utils.VolcanoPodGroupCrdName
+}
+
+func (r *VolcanoBackend) PodGroupObject() client.Object {
+	return &volcanov1beta1.PodGroup{}
+}
+
+func (r *VolcanoBackend) Enabled(rbg *workloadsv1alpha.RoleBasedGroup) bool {
+	return rbg.Spec.PodGroupPolicy != nil && rbg.Spec.PodGroupPolicy.Volcano != nil
+}
+
+func (r *VolcanoBackend) CreateOrUpdatePodGroup(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error {
+	logger := log.FromContext(ctx)
+	desired, err := r.podGroupSpec(rbg)
+	if err != nil {
+		return err
+	}
+
+	podGroup := &volcanov1beta1.PodGroup{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup)
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "get volcano pod group error")
+		return err
+	}
+
+	if apierrors.IsNotFound(err) {
+		podGroup = &volcanov1beta1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      rbg.Name,
+				Namespace: rbg.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(rbg, rbg.GroupVersionKind()),
+				},
+			},
+			Spec: desired,
+		}
+		err = r.client.Create(ctx, podGroup)
+		if err != nil {
+			logger.Error(err, "create volcano pod group error")
+		}
+		return err
+	}
+
+	if volcanoPodGroupSpecEqual(podGroup.Spec, desired) {
+		return nil
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup); err != nil {
+			return err
+		}
+		podGroup.Spec.MinMember = desired.MinMember
+		podGroup.Spec.MinResources = desired.MinResources
+		// volcano fills in the default queue, only override the queue set by users.
+		if desired.Queue != "" {
+			podGroup.Spec.Queue = desired.Queue
+		}
+		podGroup.Spec.PriorityClassName = desired.PriorityClassName
+		return r.client.Update(ctx, podGroup)
+	})
+	if err != nil {
+		logger.Error(err, "update volcano pod group error")
+	}
+	return err
+}
+
+func (r *VolcanoBackend) DeletePodGroup(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error {
+	podGroup := &volcanov1beta1.PodGroup{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	return r.client.Delete(ctx, podGroup)
+}
+
+func (r *VolcanoBackend) InjectPodGroupInfo(rbg *workloadsv1alpha.RoleBasedGroup, podTemplate *coreapplyv1.PodTemplateSpecApplyConfiguration) {
+	podTemplate.WithAnnotations(map[string]string{
+		volcanov1beta1.KubeGroupNameAnnotationKey: rbg.Name,
+	})
+	if podTemplate.Spec == nil {
+		podTemplate.WithSpec(coreapplyv1.PodSpec())
+	}
+	podTemplate.Spec.WithSchedulerName(VolcanoSchedulerName)
+}
+
+func (r *VolcanoBackend) podGroupSpec(rbg *workloadsv1alpha.RoleBasedGroup) (volcanov1beta1.PodGroupSpec, error) {
+	policy := rbg.Spec.PodGroupPolicy.Volcano
+	minResources, err := computeMinResources(rbg)
+	if err != nil {
+		return volcanov1beta1.PodGroupSpec{}, err
+	}
+	return volcanov1beta1.PodGroupSpec{
+		MinMember:         int32(rbg.GetGroupMinMember()),
+		MinResources:      &minResources,
+		Queue:             policy.Queue,
+		PriorityClassName: policy.PriorityClassName,
+	}, nil
+}
+
+func volcanoPodGroupSpecEqual(current, desired volcanov1beta1.PodGroupSpec) bool {
+	if current.MinMember != desired.MinMember || current.PriorityClassName != desired.PriorityClassName {
+		return false
+	}
+	if desired.Queue != "" && current.Queue != desired.Queue {
+		return false
+	}
+	if current.MinResources == nil {
+		return len(*desired.MinResources) == 0
+	}
+	return resourceListEqual(*current.MinResources, *desired.MinResources)
+}
@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	coreapplyv1 "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
+	volcanov1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
+)
+
+func buildVolcanoRbg(replicas int32) *workloadsv1alpha.RoleBasedGroup {
+	template := wrappers.BuildBasicPodTemplateSpec().Obj()
+	template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("500m"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	return wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").
+		WithRoles([]workloadsv1alpha.RoleSpec{
+			wrappers.BuildBasicRole("prefill").WithReplicas(replicas).WithTemplate(template).Obj(),
+			wrappers.BuildBasicRole("decode").WithReplicas(1).WithTemplate(template).Obj(),
+		}).
+		WithVolcanoGangScheduling("llm").Obj()
+}
+
+func TestVolcanoBackend_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = workloadsv1alpha.AddToScheme(scheme)
+	_ = volcanov1beta1.AddToScheme(scheme)
+
+	tests := []struct {
+		name          string
+		rbg           *workloadsv1alpha.RoleBasedGroup
+		existing      *volcanov1beta1.PodGroup
+		wantExist     bool
+		wantMinMember int32
+		wantCPU       string
+		wantQueue     string
+	}{
+		{
+			name:          "create pod group",
+			rbg:           buildVolcanoRbg(2),
+			wantExist:     true,
+			wantMinMember: 3,
+			wantCPU:       "1500m",
+			wantQueue:     "llm",
+		},
+		{
+			name: "update pod group after scaling",
+			rbg:  buildVolcanoRbg(3),
+			existing: &volcanov1beta1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+				Spec:       volcanov1beta1.PodGroupSpec{MinMember: 3, Queue: "llm"},
+			},
+			wantExist:     true,
+			wantMinMember: 4,
+			wantCPU:       "2",
+			wantQueue:     "llm",
+		},
+		{
+			name: "delete pod group when gang-scheduling is disabled",
+			rbg: func() *workloadsv1alpha.RoleBasedGroup {
+				rbg := buildVolcanoRbg(1)
+				rbg.Spec.PodGroupPolicy = nil
+				return rbg
+			}(),
+			existing: &volcanov1beta1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+			},
+			wantExist: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if tt.existing != nil {
+				builder.WithObjects(tt.existing)
+			}
+			fakeClient := builder.Build()
+
+			podGroupScheduler := NewPodGroupScheduler(fakeClient)
+			if err := podGroupScheduler.Reconcile(context.TODO(), tt.rbg, NewVolcanoBackend(fakeClient)); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			podGroup := &volcanov1beta1.PodGroup{}
+			err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: tt.rbg.Name, Namespace: tt.rbg.Namespace}, podGroup)
+			if (err == nil) != tt.wantExist {
+				t.Fatalf("Get() error = %v, want exist %v", err, tt.wantExist)
+			}
+			if !tt.wantExist {
+				return
+			}
+			if podGroup.Spec.MinMember != tt.wantMinMember {
+				t.Errorf("minMember = %d, want %d", podGroup.Spec.MinMember, tt.wantMinMember)
+			}
+			if podGroup.Spec.Queue != tt.wantQueue {
+				t.Errorf("queue = %s, want %s", podGroup.Spec.Queue, tt.wantQueue)
+			}
+			if podGroup.Spec.MinResources == nil {
+				t.Fatalf("minResources not set")
+			}
+			cpu := (*podGroup.Spec.MinResources)[corev1.ResourceCPU]
+			if cpu.Cmp(resource.MustParse(tt.wantCPU)) != 0 {
+				t.Errorf("minResources cpu = %s, want %s", cpu.String(), tt.wantCPU)
+			}
+		})
+	}
+}
+
+func TestPodGroupScheduler_InjectPodGroupInfo(t *testing.T) {
+	tests := []struct {
+		name              string
+		rbg               *workloadsv1alpha.RoleBasedGroup
+		wantLabels        map[string]string
+		wantAnnotations   map[string]string
+		wantSchedulerName *string
+	}{
+		{
+			name:       "kube scheduling",
+			rbg:        wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithGangScheduling(true).Obj(),
+			wantLabels: map[string]string{workloadsv1alpha.PodGroupLabelKey: "test-rbg"},
+		},
+		{
+			name:              "volcano",
+			rbg:               buildVolcanoRbg(1),
+			wantAnnotations:   map[string]string{volcanov1beta1.KubeGroupNameAnnotationKey: "test-rbg"},
+			wantSchedulerName: ptr.To(VolcanoSchedulerName),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podTemplate := coreapplyv1.PodTemplateSpec()
+			NewPodGroupScheduler(nil).InjectPodGroupInfo(tt.rbg, podTemplate)
+
+			for k, v := range tt.wantLabels {
+				if podTemplate.Labels[k] != v {
+					t.Errorf("label %s = %s, want %s", k, podTemplate.Labels[k], v)
+				}
+			}
+			for k, v := range tt.wantAnnotations {
+				if podTemplate.Annotations[k] != v {
+					t.Errorf("annotation %s = %s, want %s", k, podTemplate.Annotations[k], v)
+				}
+			}
+			if tt.wantSchedulerName != nil {
+				if podTemplate.Spec == nil || podTemplate.Spec.SchedulerName == nil || *podTemplate.Spec.SchedulerName != *tt.wantSchedulerName {
+					t.Errorf("schedulerName not set to %s", *tt.wantSchedulerName)
+				}
+			}
+		})
+	}
+}
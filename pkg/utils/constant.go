@@ -4,9 +4,18 @@ const (
 	// PodGroupCrdName is PodGroup CRD Name
 	PodGroupCrdName = "podgroups.scheduling.x-k8s.io"
 
+	// VolcanoPodGroupCrdName is Volcano PodGroup CRD name
+	VolcanoPodGroupCrdName = "podgroups.scheduling.volcano.sh"
+
 	// LwsCrdName is LWS CRD name
 	LwsCrdName = "leaderworkersets.leaderworkerset.x-k8s.io"
 
+	// KruiseStatefulSetCrdName is OpenKruise Advanced StatefulSet CRD name
+	KruiseStatefulSetCrdName = "statefulsets.apps.kruise.io"
+
+	// CloneSetCrdName is OpenKruise CloneSet CRD name
+	CloneSetCrdName = "clonesets.apps.kruise.io"
+
 	// RbgCRDName is rbg crd name
 	RbgCRDName = "rolebasedgroups.workloads.x-k8s.io"
 
@@ -1,6 +1,12 @@
 package utils
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
 
 // PodRunningAndReady checks if the pod condition is running and marked as ready.
 func PodRunningAndReady(pod corev1.Pod) bool {
@@ -66,3 +72,83 @@ func ContainerRestarted(pod *corev1.Pod) bool {
 func PodDeleted(pod *corev1.Pod) bool {
 	return pod.DeletionTimestamp != nil
 }
+
+// PodPreempted checks if the pod is terminated by the scheduler to make room for a higher priority pod.
+func PodPreempted(pod *corev1.Pod) bool {
+	_, condition := getPodCondition(&pod.Status, corev1.DisruptionTarget)
+	return condition != nil && condition.Status == corev1.ConditionTrue &&
+		condition.Reason == corev1.PodReasonPreemptionByScheduler
+}
+
+// PodConditionTrue checks if the pod is running and the condition of conditionType is true.
+func PodConditionTrue(pod corev1.Pod, conditionType corev1.PodConditionType) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	_, condition := getPodCondition(&pod.Status, conditionType)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// PatchPodTemplate applies the strategic merge patch to a copy of template, like the leader and worker
+// template patches of a LeaderWorkerSet role.
+func PatchPodTemplate(template corev1.PodTemplateSpec, patch runtime.RawExtension) (corev1.PodTemplateSpec, error) {
+	if patch.Raw == nil {
+		return template, nil
+	}
+	tempBytes, _ := json.Marshal(template)
+	modified, err := strategicpatch.StrategicMergePatch(tempBytes, patch.Raw, &corev1.PodTemplateSpec{})
+	if err != nil {
+		return template, err
+	}
+	newTemp := &corev1.PodTemplateSpec{}
+	if err = json.Unmarshal(modified, newTemp); err != nil {
+		return template, err
+	}
+	return *newTemp, nil
+}
+
+// PodRequests returns the resources the scheduler reserves for a pod of spec: the requests of the
+// containers and of the sidecar init containers, or of the largest init container with the sidecars
+// started before it if larger, plus the pod overhead.
+func PodRequests(spec corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range spec.Containers {
+		addResourceList(requests, container.Resources.Requests)
+	}
+
+	initRequests := corev1.ResourceList{}
+	sidecarRequests := corev1.ResourceList{}
+	for _, container := range spec.InitContainers {
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			// a sidecar keeps running next to the containers and the init containers after it
+			addResourceList(requests, container.Resources.Requests)
+			addResourceList(sidecarRequests, container.Resources.Requests)
+			maxResourceList(initRequests, sidecarRequests)
+			continue
+		}
+		running := sidecarRequests.DeepCopy()
+		addResourceList(running, container.Resources.Requests)
+		maxResourceList(initRequests, running)
+	}
+	maxResourceList(requests, initRequests)
+	addResourceList(requests, spec.Overhead)
+	return requests
+}
+
+// addResourceList adds the quantities of toAdd to list.
+func addResourceList(list, toAdd corev1.ResourceList) {
+	for name, quantity := range toAdd {
+		total := list[name]
+		total.Add(quantity)
+		list[name] = total
+	}
+}
+
+// maxResourceList raises the quantities of list to those of other when larger.
+func maxResourceList(list, other corev1.ResourceList) {
+	for name, quantity := range other {
+		if current, ok := list[name]; !ok || quantity.Cmp(current) > 0 {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
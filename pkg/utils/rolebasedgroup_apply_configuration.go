@@ -56,14 +56,22 @@ func (b *RbgApplyConfiguration) ensureObjectMetaApplyConfigurationExists() {
 }
 
 type RbgStatusApplyConfiguration struct {
-	Conditions   []v1.Condition        `json:"conditions,omitempty"`
-	RoleStatuses []v1alpha1.RoleStatus `json:"roleStatuses,omitempty"`
+	ObservedGeneration int64                        `json:"observedGeneration,omitempty"`
+	Conditions         []v1.Condition               `json:"conditions,omitempty"`
+	RoleStatuses       []v1alpha1.RoleStatus        `json:"roleStatuses,omitempty"`
+	Rollout            *v1alpha1.GroupRolloutStatus `json:"rollout,omitempty"`
+	Restarts           *v1alpha1.RestartStatus      `json:"restarts,omitempty"`
 }
 
 func RbgStatus() *RbgStatusApplyConfiguration {
 	return &RbgStatusApplyConfiguration{}
 }
 
+func (b *RbgStatusApplyConfiguration) WithObservedGeneration(value int64) *RbgStatusApplyConfiguration {
+	b.ObservedGeneration = value
+	return b
+}
+
 func (b *RbgStatusApplyConfiguration) WithConditions(conditions []v1.Condition) *RbgStatusApplyConfiguration {
 	b.Conditions = conditions
 	return b
@@ -73,3 +81,13 @@ func (b *RbgStatusApplyConfiguration) WithRoleStatuses(roleStatuses []v1alpha1.R
 	b.RoleStatuses = roleStatuses
 	return b
 }
+
+func (b *RbgStatusApplyConfiguration) WithRollout(rollout *v1alpha1.GroupRolloutStatus) *RbgStatusApplyConfiguration {
+	b.Rollout = rollout
+	return b
+}
+
+func (b *RbgStatusApplyConfiguration) WithRestarts(restarts *v1alpha1.RestartStatus) *RbgStatusApplyConfiguration {
+	b.Restarts = restarts
+	return b
+}
@@ -42,6 +42,35 @@ func (rbgWrapper *RoleBasedGroupWrapper) WithGangScheduling(gangScheduling bool)
 	return rbgWrapper
 }
 
+func (rbgWrapper *RoleBasedGroupWrapper) WithVolcanoGangScheduling(queue string) *RoleBasedGroupWrapper {
+	rbgWrapper.Spec.PodGroupPolicy = &workloadsv1alpha.PodGroupPolicy{
+		PodGroupPolicySource: workloadsv1alpha.PodGroupPolicySource{
+			Volcano: &workloadsv1alpha.VolcanoPodGroupPolicySource{
+				Queue: queue,
+			},
+		},
+	}
+	return rbgWrapper
+}
+
+func (rbgWrapper *RoleBasedGroupWrapper) WithTopologyPolicy(mode workloadsv1alpha.TopologyPolicyMode, topologyKey string) *RoleBasedGroupWrapper {
+	rbgWrapper.Spec.TopologyPolicy = &workloadsv1alpha.TopologyPolicy{
+		TopologyKey: topologyKey,
+		Mode:        mode,
+	}
+	return rbgWrapper
+}
+
+func (rbgWrapper *RoleBasedGroupWrapper) WithPriorityClassName(priorityClassName string) *RoleBasedGroupWrapper {
+	rbgWrapper.Spec.PriorityClassName = priorityClassName
+	return rbgWrapper
+}
+
+func (rbgWrapper *RoleBasedGroupWrapper) WithPreemptionPolicy(policy workloadsv1alpha.PreemptionPolicyType) *RoleBasedGroupWrapper {
+	rbgWrapper.Spec.PreemptionPolicy = policy
+	return rbgWrapper
+}
+
 func BuildBasicRoleBasedGroup(name, ns string) *RoleBasedGroupWrapper {
 	return &RoleBasedGroupWrapper{
 		workloadsv1alpha.RoleBasedGroup{
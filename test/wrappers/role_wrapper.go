@@ -44,6 +44,19 @@ func (roleWrapper *RoleWrapper) WithMaxSurge(value int32) *RoleWrapper {
 	return roleWrapper
 }
 
+func (roleWrapper *RoleWrapper) WithPartition(value int32) *RoleWrapper {
+	if roleWrapper.RolloutStrategy.RollingUpdate == nil {
+		roleWrapper.RolloutStrategy.RollingUpdate = &workloadsv1alpha.RollingUpdate{}
+	}
+	roleWrapper.RolloutStrategy.RollingUpdate.Partition = ptr.To(value)
+	return roleWrapper
+}
+
+func (roleWrapper *RoleWrapper) WithPaused(paused bool) *RoleWrapper {
+	roleWrapper.RolloutStrategy.Paused = paused
+	return roleWrapper
+}
+
 func (roleWrapper *RoleWrapper) WithTemplate(template corev1.PodTemplateSpec) *RoleWrapper {
 	roleWrapper.Template = template
 	return roleWrapper
@@ -54,6 +67,11 @@ func (roleWrapper *RoleWrapper) WithDependencies(dependencies []string) *RoleWra
 	return roleWrapper
 }
 
+func (roleWrapper *RoleWrapper) WithDependencyReadiness(readiness ...workloadsv1alpha.DependencyReadiness) *RoleWrapper {
+	roleWrapper.DependencyReadiness = readiness
+	return roleWrapper
+}
+
 func (roleWrapper *RoleWrapper) WithRollingUpdate(rollingUpdate workloadsv1alpha.RollingUpdate) *RoleWrapper {
 	roleWrapper.RolloutStrategy = &workloadsv1alpha.RolloutStrategy{
 		Type:          workloadsv1alpha.RollingUpdateStrategyType,
@@ -67,6 +85,11 @@ func (roleWrapper *RoleWrapper) WithRestartPolicy(restartPolicy workloadsv1alpha
 	return roleWrapper
 }
 
+func (roleWrapper *RoleWrapper) WithPriorityClassName(priorityClassName string) *RoleWrapper {
+	roleWrapper.PriorityClassName = priorityClassName
+	return roleWrapper
+}
+
 func (roleWrapper *RoleWrapper) WithWorkload(workloadType string) *RoleWrapper {
 	switch workloadType {
 	case workloadsv1alpha.DeploymentWorkloadType:
@@ -84,6 +107,16 @@ func (roleWrapper *RoleWrapper) WithWorkload(workloadType string) *RoleWrapper {
 			APIVersion: "leaderworkerset.x-k8s.io/v1",
 			Kind:       "LeaderWorkerSet",
 		}
+	case workloadsv1alpha.JobWorkloadType:
+		roleWrapper.Workload = workloadsv1alpha.WorkloadSpec{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		}
+	case workloadsv1alpha.PodWorkloadType:
+		roleWrapper.Workload = workloadsv1alpha.WorkloadSpec{
+			APIVersion: "v1",
+			Kind:       "Pod",
+		}
 	default:
 		panic(fmt.Sprintf("workload type not supported: %s", workloadType))
 	}